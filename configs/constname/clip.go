@@ -0,0 +1,25 @@
+package constname
+
+const (
+	// UseClipCmd Name clip command
+	UseClipCmd = `clip <file>`
+
+	// ShortClipCmd Short description clip command
+	ShortClipCmd = `Clip osm pbf or osm xml to bounding box or polygon`
+
+	// LongClipCmd Long description clip command
+	LongClipCmd = `
+This command write elements inside of the region to new pbf file. Region is
+a bounding box or polygon from geojson or osmosis .poly file.
+
+Ways with any node inside of the region are written with all nodes, relations
+with any written member are written with all members. Header bbox of the
+output is the bounding box of the region
+`
+
+	// ExampleClipCmd Example use clip command
+	ExampleClipCmd = `
+mbt clip andorra.osm.pbf --bbox 1.45,42.45,1.6,42.6 -o center.osm.pbf
+mbt clip andorra.osm.pbf --polygon escaldes.geojson -o escaldes.osm.pbf --compression zstd
+`
+)
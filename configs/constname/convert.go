@@ -2,13 +2,74 @@ package constname
 
 const (
 	// UseConvertCmd Name example command
-	UseConvertCmd = `convert`
+	UseConvertCmd = `convert <file>`
 
 	// ShortConvertCmd Short description example command
-	ShortConvertCmd = `Convert osm pbf to mbtiles`
+	ShortConvertCmd = `Convert osm pbf or osm xml to mbtiles`
 
 	// LongConvertCmd Long description example command
 	LongConvertCmd = `
-This command convert osm pbf files to mbtiles
+This command convert osm pbf files and osm xml files (.osm, .osm.gz, .osm.bz2)
+to mbtiles. Output file is created beside the input file with .mbtiles extension.
+Name of tileset is the name of input file without extension if --name is not set.
+Description, attribution, type and version of metadata are set by flags, they
+can be changed later with the meta command.
+
+Bounds of metadata are the bounds of features written to tiles limited to web
+mercator, center is the tile with the most features on a zoom showing all data.
+--bounds and --center replace computed values.
+
+Values of numeric and boolean keys like ele, population, lanes and oneway are
+written as mvt numbers and booleans, --attribute-type changes the type of a key.
+Values which can not be converted stay strings.
+
+Closed ways of areas (buildings, landuse, leisure, natural, area=yes and
+others) are written to the polygons layer, other ways to the lines layer.
+Areas get label points in the labels layer on zooms where the area is larger
+than --label-min-area square pixels. Label is the pole of inaccessibility found
+with --label-precision meters or the centroid of area.
+
+With --merge lines connected lines with equal attributes, like parts of one
+road, are written as one feature of each tile. Oneway lines are joined only
+in their direction. With --merge polygons adjacent polygons with equal
+attributes are joined on zooms up to --merge-max-zoom. Merged features keep
+the id of their first element.
+
+With --ocean coastlines (natural=coastline ways, land on the left side) are
+assembled to water polygons of the ocean layer. Coastlines ending inside of
+the data are closed along the border of data, tiles fully inside of the ocean
+get a polygon covering the tile. --water-polygons uses precomputed water
+polygons of a shapefile or geojson file in lon/lat instead, like water
+polygons of osmdata.openstreetmap.de. Water is clipped to bounds of data.
+
+Feature ids of tiles are osm id * 10 + type code (1 node, 2 way, 3 relation),
+so they are unique and can be used for feature-state and promoteId. Osm id and
+type are written to properties only with --id-properties.
+
+Files with unsupported required features of the pbf header, like history
+files (HistoricalInformation), are rejected.
+
+With --languages names are normalized: name is the local name and name_<code>
+is the name of each language from name:<code>, name tags of fallback languages,
+int_name for latin languages and the local name. --transliterate converts the
+local name between cyrillic and latin for languages without own name. Other
+name:* tags are not written.
+
+With --bbox or --polygon only elements inside of the region are converted,
+ways crossing the border are kept complete like in the clip command
+`
+
+	// ExampleConvertCmd Example use convert command
+	ExampleConvertCmd = `
+mbt convert andorra.osm.pbf
+mbt convert export.osm --output export.mbtiles --name "Export"
+mbt convert andorra.osm.pbf --type baselayer --attribution "© OpenStreetMap contributors"
+mbt convert andorra.osm.pbf --bbox 1.45,42.45,1.6,42.6
+mbt convert andorra.osm.pbf --attribute-type maxspeed=number --attribute-type oneway=string
+mbt convert andorra.osm.pbf --polygon escaldes.poly -o escaldes.mbtiles
+mbt convert belarus.osm.pbf --languages ru,en,be:ru --transliterate
+mbt convert andorra.osm.pbf --merge lines,polygons --merge-max-zoom 10
+mbt convert malta.osm.pbf --ocean
+mbt convert malta.osm.pbf --water-polygons water-polygons-split-4326/water_polygons.shp
 `
 )
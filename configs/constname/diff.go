@@ -0,0 +1,26 @@
+package constname
+
+const (
+	// UseDiffCmd Name diff command
+	UseDiffCmd = `diff <old.mbtiles> <new.mbtiles>`
+
+	// ShortDiffCmd Short description diff command
+	ShortDiffCmd = `Compare two mbtiles tilesets`
+
+	// LongDiffCmd Long description diff command
+	LongDiffCmd = `
+This command report metadata differences, added, removed and changed tiles
+per zoom and for changed tiles the added, removed and changed features of every layer.
+Features are matched by feature id or by osm id and type of properties.
+
+With --patch added and changed tiles are written into a new mbtiles file
+with metadata of the new tileset, removed tiles are written with empty data
+`
+
+	// ExampleDiffCmd Example use diff command
+	ExampleDiffCmd = `
+mbt diff old.mbtiles new.mbtiles
+mbt diff old.mbtiles new.mbtiles --patch patch.mbtiles --features=false
+mbt diff old.mbtiles new.mbtiles --json
+`
+)
@@ -0,0 +1,30 @@
+package constname
+
+const (
+	// UseFilterCmd Name filter command
+	UseFilterCmd = `filter <file.osm.pbf> <expression>...`
+
+	// ShortFilterCmd Short description filter command
+	ShortFilterCmd = `Extract osm elements matching tag expressions to new pbf file`
+
+	// LongFilterCmd Long description filter command
+	LongFilterCmd = `
+This command read the pbf file and write elements matching any of the tag
+expressions to new pbf file. Nodes of written ways and members of written
+relations are written too, so geometry of the extract is complete.
+
+Expression has the form [nwr/]key[=value,...] or [nwr/]key!=value,...
+where the prefix limits element types: n nodes, w ways, r relations.
+Value * matches any value of the key.
+
+Blob index of the input is built in the first scan, later passes read only
+blocks with ways and relations in parallel. With --cache-index the index is
+saved beside the input file (.idx) and used while the input is not changed
+`
+
+	// ExampleFilterCmd Example use filter command
+	ExampleFilterCmd = `
+mbt filter andorra.osm.pbf w/highway n/amenity -o roads.osm.pbf
+mbt filter andorra.osm.pbf "w/highway=primary,secondary" "r/boundary=administrative" -o extract.osm.pbf --compression zstd
+`
+)
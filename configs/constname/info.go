@@ -0,0 +1,21 @@
+package constname
+
+const (
+	// UseInfoCmd Name info command
+	UseInfoCmd = `info <file.mbtiles>`
+
+	// ShortInfoCmd Short description info command
+	ShortInfoCmd = `Show content of mbtiles file`
+
+	// LongInfoCmd Long description info command
+	LongInfoCmd = `
+This command print metadata, vector layers, compression
+and tile sizes per zoom of mbtiles file
+`
+
+	// ExampleInfoCmd Example use info command
+	ExampleInfoCmd = `
+mbt info andorra.mbtiles
+mbt info andorra.mbtiles --json | jq '.zooms'
+`
+)
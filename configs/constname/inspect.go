@@ -0,0 +1,26 @@
+package constname
+
+const (
+	// UseInspectTileCmd Name inspect tile command
+	UseInspectTileCmd = `inspect-tile <file.mbtiles> <z/x/y>`
+
+	// ShortInspectTileCmd Short description inspect tile command
+	ShortInspectTileCmd = `Decode one tile of mbtiles to geojson`
+
+	// LongInspectTileCmd Long description inspect tile command
+	LongInspectTileCmd = `
+This command read tile by xyz coordinates from mbtiles file,
+decompress and decode mvt and print it as geojson.
+Every layer is a nested feature collection with the layer name, feature
+counts and attribute summaries in properties.
+
+Use --summary for a table of feature counts and attributes of every layer
+`
+
+	// ExampleInspectTileCmd Example use inspect tile command
+	ExampleInspectTileCmd = `
+mbt inspect-tile andorra.mbtiles 14/8290/6030
+mbt inspect-tile andorra.mbtiles 14/8290/6030 --coordinates tile
+mbt inspect-tile andorra.mbtiles 14/8290/6030 --summary
+`
+)
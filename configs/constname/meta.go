@@ -0,0 +1,43 @@
+package constname
+
+const (
+	// UseMetaCmd Name meta command
+	UseMetaCmd = `meta`
+
+	// ShortMetaCmd Short description meta command
+	ShortMetaCmd = `Read, set and delete metadata of mbtiles`
+
+	// LongMetaCmd Long description meta command
+	LongMetaCmd = `
+This command change metadata table of existing mbtiles file without convert.
+Values of keys defined by the mbtiles spec are checked before writing: type is
+overlay or baselayer, minzoom and maxzoom are zooms, bounds and center are
+comma separated numbers, json is valid json
+`
+
+	// ExampleMetaCmd Example use meta command
+	ExampleMetaCmd = `
+mbt meta get andorra.mbtiles
+mbt meta get andorra.mbtiles name
+mbt meta set andorra.mbtiles attribution "© OpenStreetMap contributors"
+mbt meta delete andorra.mbtiles description attribution
+`
+
+	// UseMetaGetCmd Name meta get command
+	UseMetaGetCmd = `get <file.mbtiles> [key]`
+
+	// ShortMetaGetCmd Short description meta get command
+	ShortMetaGetCmd = `Print all metadata or value of one key`
+
+	// UseMetaSetCmd Name meta set command
+	UseMetaSetCmd = `set <file.mbtiles> <key> <value>`
+
+	// ShortMetaSetCmd Short description meta set command
+	ShortMetaSetCmd = `Set value of metadata key`
+
+	// UseMetaDeleteCmd Name meta delete command
+	UseMetaDeleteCmd = `delete <file.mbtiles> <key>...`
+
+	// ShortMetaDeleteCmd Short description meta delete command
+	ShortMetaDeleteCmd = `Delete metadata keys`
+)
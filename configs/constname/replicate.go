@@ -0,0 +1,32 @@
+package constname
+
+const (
+	// UseReplicateCmd Name replicate command
+	UseReplicateCmd = `replicate <file.mbtiles>`
+
+	// ShortReplicateCmd Short description replicate command
+	ShortReplicateCmd = `Follow osm replication diffs to keep mbtiles file up to date`
+
+	// LongReplicateCmd Long description replicate command
+	LongReplicateCmd = `
+This command read state.txt of the replication server or local mirror with the
+same layout (000/004/577.osc.gz) and apply all diffs after the sequence saved in
+the metadata of tileset. Replication url is taken from the source pbf header.
+
+Sequence is saved after every diff, an interrupted run continues from the last
+saved sequence. Diffs between the source pbf and the tileset are applied again
+in memory without rendering of tiles, so every run reads the source and the
+diffs since it.
+
+With --follow the command keeps running, checks state.txt every --interval and
+applies new diffs with the state kept in memory, diffs since the source are
+read only once. Interrupt stops after the diff being applied
+`
+
+	// ExampleReplicateCmd Example use replicate command
+	ExampleReplicateCmd = `
+mbt replicate andorra.mbtiles --source andorra.osm.pbf
+mbt replicate andorra.mbtiles --source andorra.osm.pbf --url ./mirror --max-diffs 10
+mbt replicate andorra.mbtiles --source andorra.osm.pbf --follow --interval 5m
+`
+)
@@ -0,0 +1,29 @@
+package constname
+
+const (
+	// UseServeCmd Name serve command
+	UseServeCmd = `serve <file.mbtiles|directory>`
+
+	// ShortServeCmd Short description serve command
+	ShortServeCmd = `Serve mbtiles as xyz vector tiles`
+
+	// LongServeCmd Long description serve command
+	LongServeCmd = `
+This command start local http server of vector tiles from a mbtiles file
+or from all mbtiles files in a directory.
+
+Routes:
+  /{tileset}/                 map preview of all layers of tileset
+  /{tileset}/{z}/{x}/{y}.pbf  vector tile
+  /{tileset}/tiles.json       tilejson of tileset
+  /index.json                 tilejson of all tilesets
+
+A single served tileset is also available from /, /{z}/{x}/{y}.pbf and /tiles.json
+`
+
+	// ExampleServeCmd Example use serve command
+	ExampleServeCmd = `
+mbt serve andorra.mbtiles
+mbt serve ./tiles --addr :3000 --max-age 600
+`
+)
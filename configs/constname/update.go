@@ -0,0 +1,27 @@
+package constname
+
+const (
+	// UseUpdateCmd Name update command
+	UseUpdateCmd = `update <file.mbtiles> <change.osc>...`
+
+	// ShortUpdateCmd Short description update command
+	ShortUpdateCmd = `Apply osm change files to existing mbtiles file`
+
+	// LongUpdateCmd Long description update command
+	LongUpdateCmd = `
+This command load the source pbf of the tileset, apply osm change files
+(.osc, .osc.gz, .osc.bz2) in the given order and render again only tiles
+touched by changed nodes and ways. Tiles with the same content are not written.
+
+Pass all change files since the source pbf, because the source is loaded again
+on every run. Replication sequence and timestamp are written to the metadata,
+update is refused when the tileset was updated after the source and the change
+files do not start at the source
+`
+
+	// ExampleUpdateCmd Example use update command
+	ExampleUpdateCmd = `
+mbt update andorra.mbtiles --source andorra.osm.pbf 6123.osc.gz
+mbt update andorra.mbtiles --source andorra.osm.pbf --sequence 6124 6123.osc.gz 6124.osc.gz
+`
+)
@@ -0,0 +1,26 @@
+package constname
+
+const (
+	// UseValidateCmd Name validate command
+	UseValidateCmd = `validate <file.mbtiles>`
+
+	// ShortValidateCmd Short description validate command
+	ShortValidateCmd = `Check mbtiles file against the mbtiles and mvt specs`
+
+	// LongValidateCmd Long description validate command
+	LongValidateCmd = `
+This command check required metadata, bounds and center format,
+zoom levels of metadata and tiles, tms rows, decoding of every tile,
+layers of vector_layers and validity of polygon geometry.
+
+The table shows up to 20 findings of each check, --json prints all findings
+and nothing else to stdout. Exit code is non-zero when errors are found,
+with --strict also on warnings
+`
+
+	// ExampleValidateCmd Example use validate command
+	ExampleValidateCmd = `
+mbt validate andorra.mbtiles
+mbt validate andorra.mbtiles --strict --json
+`
+)
@@ -6,7 +6,9 @@ require (
 	github.com/charmbracelet/fang v0.4.3
 	github.com/charmbracelet/huh v0.7.0
 	github.com/charmbracelet/lipgloss/v2 v2.0.0-beta.3.0.20250917201909-41ff0bf215ea
+	github.com/klauspost/compress v1.20.1
 	github.com/mattn/go-sqlite3 v1.14.32
+	github.com/paulmach/orb v0.12.0
 	github.com/spf13/cobra v1.10.1
 	google.golang.org/protobuf v1.36.10
 )
@@ -43,7 +45,6 @@ require (
 	github.com/muesli/mango-pflag v0.1.0 // indirect
 	github.com/muesli/roff v0.1.0 // indirect
 	github.com/muesli/termenv v0.16.0 // indirect
-	github.com/paulmach/orb v0.12.0 // indirect
 	github.com/paulmach/protoscan v0.2.1 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
 	github.com/spf13/pflag v1.0.9 // indirect
@@ -0,0 +1,136 @@
+package clip
+
+import (
+	"io"
+
+	"github.com/your-map/mbtiles-tool/internal/osm"
+	osmp "github.com/your-map/mbtiles-tool/internal/osm/proto"
+)
+
+// Source Open the input file again for every pass of clipping
+type Source func() (osm.Reader, io.Closer, error)
+
+// Clipper Write elements inside of region with complete ways and relations:
+// ways with any node inside are written with all nodes, relations with any
+// written member are written with all members
+type Clipper struct {
+	Source      Source
+	Region      *Region
+	Compression osm.Compression
+
+	extract *osm.Extract
+}
+
+func NewClipper(source Source, region *Region, compression osm.Compression) *Clipper {
+	c := &Clipper{
+		Source:      source,
+		Region:      region,
+		Compression: compression,
+	}
+	c.extract = osm.NewExtract(c.each, compression)
+
+	return c
+}
+
+// Run clip the source in four passes: elements inside of region, nodes of ways
+// added by relations, bbox of kept nodes and writing of kept elements
+func (c *Clipper) Run(w io.Writer) (*osm.ExtractResult, error) {
+	if err := c.collect(); err != nil {
+		return nil, err
+	}
+
+	if err := c.extract.CollectWayNodes(); err != nil {
+		return nil, err
+	}
+
+	// Bbox of source is replaced, complete ways and relations may reach out of region
+	bbox, err := c.extract.BBox()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.extract.Write(w, nil, func(header *osmp.HeaderBlock) {
+		header.Bbox = bbox
+	})
+}
+
+func (c *Clipper) collect() error {
+	members := make(map[int64][]osm.Member)
+	inside := make(map[int64]bool)
+
+	err := c.each(nil, func(elements *osm.Elements) {
+		for _, node := range elements.Nodes {
+			if c.Region.Contains(node.Lat, node.Lon) {
+				inside[node.ID] = true
+				c.extract.KeepNode(node.ID)
+			}
+		}
+
+		for _, way := range elements.Ways {
+			for _, ref := range way.Refs {
+				if inside[ref] {
+					c.extract.KeepWay(way)
+					break
+				}
+			}
+		}
+
+		for _, relation := range elements.Relations {
+			members[relation.ID] = relation.Members
+		}
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	// Relations with member inside of region, parents are resolved until nothing is added
+	for changed := true; changed; {
+		changed = false
+		for id, relationMembers := range members {
+			if c.extract.Kept(osm.Member{Type: osm.RelationMember, Ref: id}) {
+				continue
+			}
+			for _, member := range relationMembers {
+				if c.extract.Kept(member) {
+					c.extract.KeepRelation(id)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	// Complete relations: all members of kept relations are written too
+	c.extract.CompleteRelations(members)
+
+	return nil
+}
+
+// each read the source again and decode every primitive block, the source
+// has no index, so blocks of all types are read
+func (c *Clipper) each(_ []osm.MemberType, onBlock func(elements *osm.Elements), onHeader func(header *osmp.HeaderBlock)) error {
+	reader, closer, err := c.Source()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = closer.Close()
+	}()
+
+	dataChan, err := reader.Read()
+	if err != nil {
+		return err
+	}
+
+	for data := range dataChan {
+		if data.Header != nil && onHeader != nil {
+			onHeader(data.Header)
+		}
+
+		if data.Block != nil {
+			onBlock(osm.Decode(data.Block))
+		}
+	}
+
+	return reader.Err()
+}
@@ -0,0 +1,225 @@
+package clip
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+	"github.com/paulmach/orb/planar"
+)
+
+var ErrInvalidRegion = errors.New("invalid clip region")
+
+// Region Area of clipping, bounding box is stored as polygon too
+type Region struct {
+	Polygon orb.MultiPolygon
+	Bound   orb.Bound
+}
+
+func NewRegion(polygon orb.MultiPolygon) *Region {
+	return &Region{Polygon: polygon, Bound: polygon.Bound()}
+}
+
+// ParseBBox parse bounding box "minlon,minlat,maxlon,maxlat"
+func ParseBBox(value string) (*Region, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("%w: bbox %q must be minlon,minlat,maxlon,maxlat", ErrInvalidRegion, value)
+	}
+
+	coords := make([]float64, len(parts))
+	for i, part := range parts {
+		coord, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: bbox %q: %w", ErrInvalidRegion, value, err)
+		}
+		coords[i] = coord
+	}
+
+	bound := orb.Bound{Min: orb.Point{coords[0], coords[1]}, Max: orb.Point{coords[2], coords[3]}}
+	if bound.Min[0] >= bound.Max[0] || bound.Min[1] >= bound.Max[1] ||
+		bound.Min[0] < -180 || bound.Max[0] > 180 || bound.Min[1] < -90 || bound.Max[1] > 90 {
+		return nil, fmt.Errorf("%w: bbox %q is empty or out of range", ErrInvalidRegion, value)
+	}
+
+	return NewRegion(orb.MultiPolygon{bound.ToPolygon()}), nil
+}
+
+// ReadPolygon read region from geojson file or osmosis .poly file
+func ReadPolygon(file string) (*Region, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var polygon orb.MultiPolygon
+	if strings.EqualFold(filepath.Ext(file), ".poly") {
+		polygon, err = ParsePoly(f)
+	} else {
+		polygon, err = ParseGeoJSON(f)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRegion(polygon), nil
+}
+
+// ParseGeoJSON read polygons of geometry, feature or feature collection
+func ParseGeoJSON(r io.Reader) (orb.MultiPolygon, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var object struct {
+		Type string `json:"type"`
+	}
+	if err = json.Unmarshal(data, &object); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRegion, err)
+	}
+
+	var geometries []orb.Geometry
+	switch object.Type {
+	case "FeatureCollection":
+		collection, err := geojson.UnmarshalFeatureCollection(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRegion, err)
+		}
+		for _, feature := range collection.Features {
+			geometries = append(geometries, feature.Geometry)
+		}
+	case "Feature":
+		feature, err := geojson.UnmarshalFeature(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRegion, err)
+		}
+		geometries = append(geometries, feature.Geometry)
+	default:
+		geometry, err := geojson.UnmarshalGeometry(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRegion, err)
+		}
+		geometries = append(geometries, geometry.Geometry())
+	}
+
+	var polygon orb.MultiPolygon
+	for _, geometry := range geometries {
+		switch g := geometry.(type) {
+		case orb.Polygon:
+			polygon = append(polygon, g)
+		case orb.MultiPolygon:
+			polygon = append(polygon, g...)
+		}
+	}
+
+	if len(polygon) == 0 {
+		return nil, fmt.Errorf("%w: geojson has no polygons", ErrInvalidRegion)
+	}
+
+	return polygon, nil
+}
+
+// ParsePoly read osmosis polygon filter file, rings with name starting with "!" are holes
+func ParsePoly(r io.Reader) (orb.MultiPolygon, error) {
+	scanner := bufio.NewScanner(r)
+
+	// First line is name of polygon
+	if !scanner.Scan() {
+		return nil, fmt.Errorf("%w: empty poly file", ErrInvalidRegion)
+	}
+
+	var outers, holes []orb.Ring
+	var ring orb.Ring
+	inRing, hole := false, false
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if line == "END" {
+			if !inRing {
+				break
+			}
+			if len(ring) < 3 {
+				return nil, fmt.Errorf("%w: poly ring has %d points", ErrInvalidRegion, len(ring))
+			}
+			if !ring.Closed() {
+				ring = append(ring, ring[0])
+			}
+			if hole {
+				holes = append(holes, ring)
+			} else {
+				outers = append(outers, ring)
+			}
+			ring, inRing = nil, false
+			continue
+		}
+
+		if !inRing {
+			inRing, hole = true, strings.HasPrefix(line, "!")
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%w: poly line %q", ErrInvalidRegion, line)
+		}
+		lon, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: poly line %q", ErrInvalidRegion, line)
+		}
+		lat, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: poly line %q", ErrInvalidRegion, line)
+		}
+		ring = append(ring, orb.Point{lon, lat})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if inRing || len(outers) == 0 {
+		return nil, fmt.Errorf("%w: poly file is not complete", ErrInvalidRegion)
+	}
+
+	polygon := make(orb.MultiPolygon, len(outers))
+	for i, outer := range outers {
+		polygon[i] = orb.Polygon{outer}
+	}
+
+	// Hole is added to the outer ring containing its first point
+	for _, h := range holes {
+		for i := range polygon {
+			if planar.RingContains(polygon[i][0], h[0]) {
+				polygon[i] = append(polygon[i], h)
+				break
+			}
+		}
+	}
+
+	return polygon, nil
+}
+
+// Contains check that point is inside of region
+func (r *Region) Contains(lat, lon float64) bool {
+	point := orb.Point{lon, lat}
+	if !r.Bound.Contains(point) {
+		return false
+	}
+
+	return planar.MultiPolygonContains(r.Polygon, point)
+}
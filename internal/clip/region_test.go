@@ -0,0 +1,86 @@
+package clip
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseBBox(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "valid", value: "1.45,42.45,1.6,42.6"},
+		{name: "spaces", value: "1.45, 42.45, 1.6, 42.6"},
+		{name: "three values", value: "1.45,42.45,1.6", wantErr: true},
+		{name: "not number", value: "a,42.45,1.6,42.6", wantErr: true},
+		{name: "empty", value: "1.6,42.45,1.45,42.6", wantErr: true},
+		{name: "out of range", value: "1.45,42.45,1.6,95", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseBBox(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseBBox() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegionContains(t *testing.T) {
+	poly := `test
+outer
+   1.50 42.49
+   1.56 42.49
+   1.56 42.53
+   1.50 42.53
+END
+!hole
+   1.52 42.50
+   1.53 42.50
+   1.53 42.51
+   1.52 42.51
+END
+END
+`
+	polygon, err := ParsePoly(strings.NewReader(poly))
+	if err != nil {
+		t.Fatalf("ParsePoly() error = %v", err)
+	}
+
+	geoJSON := `{"type":"Feature","properties":{},"geometry":{"type":"Polygon","coordinates":
+		[[[1.50,42.49],[1.56,42.49],[1.56,42.53],[1.50,42.53],[1.50,42.49]],
+		[[1.52,42.50],[1.53,42.50],[1.53,42.51],[1.52,42.51],[1.52,42.50]]]}}`
+	geoJSONPolygon, err := ParseGeoJSON(strings.NewReader(geoJSON))
+	if err != nil {
+		t.Fatalf("ParseGeoJSON() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		lat, lon float64
+		want     bool
+	}{
+		{name: "inside", lat: 42.52, lon: 1.51, want: true},
+		{name: "in hole", lat: 42.505, lon: 1.525, want: false},
+		{name: "outside", lat: 42.6, lon: 1.51, want: false},
+	}
+	for _, region := range []*Region{NewRegion(polygon), NewRegion(geoJSONPolygon)} {
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if got := region.Contains(tt.lat, tt.lon); got != tt.want {
+					t.Errorf("Contains() = %v, want %v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestParsePolyInvalid(t *testing.T) {
+	for _, poly := range []string{"", "test\n1\n 1 2\n", "test\n1\n 1 2\n 3 4\nEND\nEND\n", "test\n1\n 1 a\nEND\nEND\n"} {
+		if _, err := ParsePoly(strings.NewReader(poly)); err == nil {
+			t.Errorf("ParsePoly(%q) expected error", poly)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/your-map/mbtiles-tool/configs/constname"
+	"github.com/your-map/mbtiles-tool/internal/component/output"
+	"github.com/your-map/mbtiles-tool/pkg/tiles"
+)
+
+var clipOptions tiles.ClipOptions
+
+var clipOutput string
+
+// clipCmd Command for clip osm file to region
+var clipCmd = &cobra.Command{
+	Use:     constname.UseClipCmd,
+	Short:   constname.ShortClipCmd,
+	Long:    constname.LongClipCmd,
+	Example: constname.ExampleClipCmd,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := tiles.NewMap(args[0]).Clip(clipOutput, clipOptions)
+		if err != nil {
+			return err
+		}
+
+		output.Green(fmt.Sprintf(
+			"Success clip file: %s, %d nodes, %d ways, %d relations",
+			clipOutput, result.Nodes, result.Ways, result.Relations,
+		))
+
+		return nil
+	},
+}
+
+func init() {
+	clipCmd.Flags().StringVarP(&clipOutput, "output", "o", "", "osm pbf file of clipped region")
+	clipCmd.Flags().StringVar(&clipOptions.BBox, "bbox", "", "region minlon,minlat,maxlon,maxlat")
+	clipCmd.Flags().StringVar(&clipOptions.Polygon, "polygon", "", "region of geojson or .poly file")
+	clipCmd.Flags().StringVar(&clipOptions.Compression, "compression", "zlib", "compression of blobs: zlib, zstd or none")
+	_ = clipCmd.MarkFlagRequired("output")
+	clipCmd.MarkFlagsMutuallyExclusive("bbox", "polygon")
+	clipCmd.MarkFlagsOneRequired("bbox", "polygon")
+}
@@ -7,11 +7,38 @@ import (
 	"github.com/your-map/mbtiles-tool/pkg/tiles"
 )
 
+var (
+	convertOutput         string
+	convertName           string
+	convertDesc           string
+	convertAttrib         string
+	convertType           string
+	convertVersion        string
+	convertBounds         string
+	convertCenter         string
+	convertBBox           string
+	convertPolygon        string
+	convertTypes          map[string]string
+	convertID             string
+	convertIDProps        bool
+	convertLangs          []string
+	convertTranslit       bool
+	convertLabels         string
+	convertLabelPrecision float64
+	convertLabelMinArea   float64
+	convertMerge          []string
+	convertMergeMaxZoom   int
+	convertOcean          bool
+	convertWater          string
+)
+
 // convertCmd Command for build pipeline
 var convertCmd = &cobra.Command{
-	Use:   constname.UseConvertCmd,
-	Short: constname.ShortConvertCmd,
-	Long:  constname.LongConvertCmd,
+	Use:     constname.UseConvertCmd,
+	Short:   constname.ShortConvertCmd,
+	Long:    constname.LongConvertCmd,
+	Example: constname.ExampleConvertCmd,
+	Args:    cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		//todo delete after completed
 		//fields, err := convertForm.Run()
@@ -19,15 +46,69 @@ var convertCmd = &cobra.Command{
 		//	return err
 		//}
 
-		pbfMap := tiles.NewMap("test/maps/andorra.osm.pbf")
+		osmMap := tiles.NewMap(args[0])
+
+		file := convertOutput
+		if file == "" {
+			file = osmMap.MBTilesFile()
+		}
 
-		mbtMap, err := pbfMap.Convert()
+		mbtMap, err := osmMap.Convert(file, tiles.ConvertOptions{
+			Name:           convertName,
+			Description:    convertDesc,
+			Attribution:    convertAttrib,
+			Type:           convertType,
+			Version:        convertVersion,
+			Bounds:         convertBounds,
+			Center:         convertCenter,
+			BBox:           convertBBox,
+			Polygon:        convertPolygon,
+			AttributeTypes: convertTypes,
+			FeatureID:      convertID,
+			IDProperties:   convertIDProps,
+			Languages:      convertLangs,
+			Transliterate:  convertTranslit,
+			LabelPlacement: convertLabels,
+			LabelPrecision: convertLabelPrecision,
+			LabelMinArea:   convertLabelMinArea,
+			Merge:          convertMerge,
+			MergeMaxZoom:   convertMergeMaxZoom,
+			Ocean:          convertOcean,
+			WaterPolygons:  convertWater,
+		})
 		if err != nil {
 			return err
 		}
 
-		output.Green("Success convert file: ", mbtMap.File)
+		output.Green("Success convert file: " + mbtMap.File)
 
 		return nil
 	},
 }
+
+func init() {
+	convertCmd.Flags().StringVarP(&convertOutput, "output", "o", "", "mbtiles file, name of input file with .mbtiles by default")
+	convertCmd.Flags().StringVar(&convertName, "name", "", "name of tileset, name of input file by default")
+	convertCmd.Flags().StringVar(&convertDesc, "description", "", "description of tileset")
+	convertCmd.Flags().StringVar(&convertAttrib, "attribution", "", "attribution of tileset, html is allowed")
+	convertCmd.Flags().StringVar(&convertType, "type", "overlay", "type of tileset: overlay or baselayer")
+	convertCmd.Flags().StringVar(&convertVersion, "version", "", "version of tileset, 1.3 by default")
+	convertCmd.Flags().StringVar(&convertBounds, "bounds", "", "bounds of metadata left,bottom,right,top, bounds of written features by default")
+	convertCmd.Flags().StringVar(&convertCenter, "center", "", "center of metadata lon,lat,zoom, the densest tile of written features by default")
+	convertCmd.Flags().StringVar(&convertBBox, "bbox", "", "convert only region minlon,minlat,maxlon,maxlat")
+	convertCmd.Flags().StringVar(&convertPolygon, "polygon", "", "convert only region of geojson or .poly file")
+	convertCmd.Flags().StringToStringVar(&convertTypes, "attribute-type", nil, "type of attribute by osm key: string, number or boolean, e.g. ele=number")
+	convertCmd.Flags().StringVar(&convertID, "feature-id", "type-code", "encoding of feature ids: type-code (osm id * 10 + 1 node, 2 way, 3 relation), osm or none")
+	convertCmd.Flags().BoolVar(&convertIDProps, "id-properties", false, "write osm id and type to properties of features")
+	convertCmd.Flags().StringSliceVar(&convertLangs, "languages", nil, "languages of name_<code> attributes, fallbacks after colon, e.g. ru,en,be:ru")
+	convertCmd.Flags().BoolVar(&convertTranslit, "transliterate", false, "transliterate local names to cyrillic or latin of languages without own name")
+	convertCmd.Flags().StringVar(&convertLabels, "label-placement", "polylabel", "label points of areas: polylabel, centroid or none")
+	convertCmd.Flags().Float64Var(&convertLabelPrecision, "label-precision", 10, "precision of pole of inaccessibility in meters")
+	convertCmd.Flags().Float64Var(&convertLabelMinArea, "label-min-area", 64, "the smallest area with label point in square pixels of 256px tile")
+	convertCmd.Flags().StringSliceVar(&convertMerge, "merge", nil, "layers with merged features of equal attributes: lines (connected lines), polygons (adjacent polygons)")
+	convertCmd.Flags().IntVar(&convertMergeMaxZoom, "merge-max-zoom", 12, "the highest zoom of merged polygons")
+	convertCmd.Flags().BoolVar(&convertOcean, "ocean", false, "write ocean layer of water assembled from natural=coastline ways")
+	convertCmd.Flags().StringVar(&convertWater, "water-polygons", "", "write ocean layer of precomputed water polygons of .shp or geojson file in lon/lat")
+	convertCmd.MarkFlagsMutuallyExclusive("bbox", "polygon")
+	convertCmd.MarkFlagsMutuallyExclusive("ocean", "water-polygons")
+}
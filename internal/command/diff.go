@@ -0,0 +1,152 @@
+package command
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/your-map/mbtiles-tool/configs/constname"
+	"github.com/your-map/mbtiles-tool/internal/component/output"
+	"github.com/your-map/mbtiles-tool/internal/diff"
+	"github.com/your-map/mbtiles-tool/internal/mbt"
+)
+
+var (
+	diffJSON     bool
+	diffFeatures bool
+	diffPatch    string
+	diffMaxTiles int
+)
+
+// diffCmd Command for compare two mbtiles files
+var diffCmd = &cobra.Command{
+	Use:     constname.UseDiffCmd,
+	Short:   constname.ShortDiffCmd,
+	Long:    constname.LongDiffCmd,
+	Example: constname.ExampleDiffCmd,
+	Args:    cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		oldTileset, err := mbt.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = oldTileset.Close()
+		}()
+
+		newTileset, err := mbt.Open(args[1])
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = newTileset.Close()
+		}()
+
+		options := diff.Options{Features: diffFeatures}
+		if diffPatch != "" {
+			options.Patch, err = mbt.Create(diffPatch)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				_ = options.Patch.Close()
+			}()
+		}
+
+		report, err := diff.NewDiffer(oldTileset, newTileset, options).Run()
+		if err != nil {
+			return err
+		}
+
+		if diffJSON {
+			return output.JSON(report)
+		}
+
+		printDiff(report)
+		if diffPatch != "" {
+			output.Green("Patch written to file: ", diffPatch)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	diffCmd.Flags().BoolVar(&diffJSON, "json", false, "print diff as json")
+	diffCmd.Flags().BoolVar(&diffFeatures, "features", true, "compare features of changed tiles")
+	diffCmd.Flags().StringVar(&diffPatch, "patch", "", "write added, changed and removed tiles to new mbtiles file")
+	diffCmd.Flags().IntVar(&diffMaxTiles, "max-tiles", 20, "count of tiles to show in details")
+}
+
+func printDiff(report *diff.Report) {
+	output.Title(fmt.Sprintf("%s -> %s", report.Old, report.New))
+
+	output.Title("Metadata")
+	if len(report.Metadata) == 0 {
+		output.Gray("No changes")
+	} else {
+		rows := make([][]string, 0, len(report.Metadata))
+		for _, change := range report.Metadata {
+			rows = append(rows, []string{change.Name, truncate(change.Old, 50), truncate(change.New, 50)})
+		}
+		output.Table([]string{"Name", "Old", "New"}, rows)
+	}
+
+	output.Title("Tiles")
+	zoomRows := make([][]string, 0, len(report.Zooms))
+	for _, zoom := range report.Zooms {
+		zoomRows = append(zoomRows, []string{
+			strconv.Itoa(zoom.Zoom),
+			strconv.Itoa(zoom.Added),
+			strconv.Itoa(zoom.Removed),
+			strconv.Itoa(zoom.Changed),
+			strconv.Itoa(zoom.Unchanged),
+		})
+	}
+	output.Table([]string{"Zoom", "Added", "Removed", "Changed", "Unchanged"}, zoomRows)
+
+	if len(report.Tiles) == 0 {
+		return
+	}
+
+	output.Title("Changed tiles")
+	rows := make([][]string, 0)
+	for i, tile := range report.Tiles {
+		if i >= diffMaxTiles {
+			output.Gray(fmt.Sprintf("%d more tiles are not shown", len(report.Tiles)-diffMaxTiles))
+			break
+		}
+
+		name := fmt.Sprintf("%d/%d/%d", tile.Zoom, tile.X, tile.Y)
+		if len(tile.Layers) == 0 {
+			rows = append(rows, []string{name, string(tile.Change), "", ""})
+			continue
+		}
+
+		for _, layer := range tile.Layers {
+			rows = append(rows, []string{name, string(tile.Change), layer.Layer, layerChanges(layer)})
+		}
+	}
+	output.Table([]string{"Tile", "Change", "Layer", "Features"}, rows)
+}
+
+func layerChanges(layer diff.LayerDiff) string {
+	changes := []string{fmt.Sprintf("%d -> %d", layer.OldCount, layer.NewCount)}
+
+	for _, change := range []struct {
+		name  string
+		count int
+	}{
+		{"added", layer.Added},
+		{"removed", layer.Removed},
+		{"attributes", layer.AttributesChanged},
+		{"geometry", layer.GeometryChanged},
+	} {
+		if change.count > 0 {
+			changes = append(changes, fmt.Sprintf("%s %d", change.name, change.count))
+		}
+	}
+
+	return strings.Join(changes, ", ")
+}
@@ -0,0 +1,44 @@
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/your-map/mbtiles-tool/configs/constname"
+	"github.com/your-map/mbtiles-tool/internal/component/output"
+	"github.com/your-map/mbtiles-tool/pkg/tiles"
+)
+
+var (
+	filterOutput  string
+	filterOptions tiles.FilterOptions
+)
+
+// filterCmd Command for extract osm elements by tags
+var filterCmd = &cobra.Command{
+	Use:     constname.UseFilterCmd,
+	Short:   constname.ShortFilterCmd,
+	Long:    constname.LongFilterCmd,
+	Example: constname.ExampleFilterCmd,
+	Args:    cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := tiles.NewMap(args[0]).Filter(filterOutput, args[1:], filterOptions)
+		if err != nil {
+			return err
+		}
+
+		output.Green(fmt.Sprintf(
+			"Success filter file: %s, %d nodes, %d ways, %d relations",
+			filterOutput, result.Nodes, result.Ways, result.Relations,
+		))
+
+		return nil
+	},
+}
+
+func init() {
+	filterCmd.Flags().StringVarP(&filterOutput, "output", "o", "", "osm pbf file of extract")
+	filterCmd.Flags().StringVar(&filterOptions.Compression, "compression", "zlib", "compression of blobs: zlib, zstd or none")
+	filterCmd.Flags().BoolVar(&filterOptions.CacheIndex, "cache-index", false, "keep blob index beside input file for next runs")
+	_ = filterCmd.MarkFlagRequired("output")
+}
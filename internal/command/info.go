@@ -0,0 +1,149 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/your-map/mbtiles-tool/configs/constname"
+	"github.com/your-map/mbtiles-tool/internal/component/output"
+	"github.com/your-map/mbtiles-tool/internal/mbt"
+)
+
+var (
+	infoJSON    bool
+	infoLargest int
+)
+
+// infoCmd Command for inspect mbtiles file
+var infoCmd = &cobra.Command{
+	Use:     constname.UseInfoCmd,
+	Short:   constname.ShortInfoCmd,
+	Long:    constname.LongInfoCmd,
+	Example: constname.ExampleInfoCmd,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if infoLargest < 0 {
+			return errors.New("largest must not be negative")
+		}
+
+		tileset, err := mbt.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = tileset.Close()
+		}()
+
+		info, err := tileset.Info(infoLargest)
+		if err != nil {
+			return err
+		}
+
+		if infoJSON {
+			return output.JSON(info)
+		}
+
+		printInfo(info)
+
+		return nil
+	},
+}
+
+func init() {
+	infoCmd.Flags().BoolVar(&infoJSON, "json", false, "print info as json")
+	infoCmd.Flags().IntVar(&infoLargest, "largest", 10, "count of the largest tiles to show")
+}
+
+func printInfo(info *mbt.Info) {
+	output.Title(info.File)
+	summary := fmt.Sprintf("%d tiles, %s", info.TileCount, formatSize(info.TotalSize))
+	if info.Compression != "" {
+		summary += ", compression " + info.Compression
+	}
+	output.Gray(summary)
+
+	output.Title("Metadata")
+	names := make([]string, 0, len(info.Metadata))
+	for name := range info.Metadata {
+		// vector layers are shown in own table
+		if name != "json" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	metadataRows := make([][]string, 0, len(names))
+	for _, name := range names {
+		metadataRows = append(metadataRows, []string{name, info.Metadata[name]})
+	}
+	output.Table([]string{"Name", "Value"}, metadataRows)
+
+	output.Title("Vector layers")
+	layerRows := make([][]string, 0, len(info.VectorLayers))
+	for _, layer := range info.VectorLayers {
+		fields := make([]string, 0, len(layer.Fields))
+		for field, fieldType := range layer.Fields {
+			fields = append(fields, field+":"+fieldType)
+		}
+		sort.Strings(fields)
+
+		layerRows = append(layerRows, []string{
+			layer.ID,
+			fmt.Sprintf("%d-%d", layer.MinZoom, layer.MaxZoom),
+			strconv.Itoa(len(fields)),
+			truncate(strings.Join(fields, ", "), 80),
+		})
+	}
+	output.Table([]string{"Layer", "Zoom", "Fields", "Field types"}, layerRows)
+
+	output.Title("Zoom levels")
+	zoomRows := make([][]string, 0, len(info.Zooms))
+	for _, zoom := range info.Zooms {
+		zoomRows = append(zoomRows, []string{
+			strconv.Itoa(zoom.Zoom),
+			strconv.Itoa(zoom.Count),
+			formatSize(zoom.TotalSize),
+			formatSize(zoom.AvgSize),
+			formatSize(zoom.MaxSize),
+		})
+	}
+	output.Table([]string{"Zoom", "Tiles", "Total", "Average", "Max"}, zoomRows)
+
+	output.Title("Largest tiles")
+	tileRows := make([][]string, 0, len(info.LargestTiles))
+	for _, tile := range info.LargestTiles {
+		tileRows = append(tileRows, []string{
+			fmt.Sprintf("%d/%d/%d", tile.Zoom, tile.X, tile.Y),
+			formatSize(tile.Size),
+		})
+	}
+	output.Table([]string{"Tile", "Size"}, tileRows)
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func truncate(str string, length int) string {
+	runes := []rune(str)
+	if len(runes) <= length {
+		return str
+	}
+
+	return string(runes[:length-1]) + "…"
+}
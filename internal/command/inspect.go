@@ -0,0 +1,176 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/paulmach/orb/encoding/mvt"
+	"github.com/paulmach/orb/geojson"
+	"github.com/paulmach/orb/maptile"
+	"github.com/spf13/cobra"
+	"github.com/your-map/mbtiles-tool/configs/constname"
+	"github.com/your-map/mbtiles-tool/internal/component/output"
+	"github.com/your-map/mbtiles-tool/internal/mbt"
+)
+
+const (
+	coordinatesWGS84 = "wgs84"
+	coordinatesTile  = "tile"
+)
+
+var (
+	inspectCoordinates string
+	inspectSummary     bool
+)
+
+// tileCollection Feature collection of one tile in the format of tippecanoe-decode
+type tileCollection struct {
+	Type       string                 `json:"type"`
+	Properties map[string]interface{} `json:"properties"`
+	Features   []layerCollection      `json:"features"`
+}
+
+type layerCollection struct {
+	Type       string                 `json:"type"`
+	Properties map[string]interface{} `json:"properties"`
+	Features   []*geojson.Feature     `json:"features"`
+}
+
+// inspectTileCmd Command for decode one tile to geojson
+var inspectTileCmd = &cobra.Command{
+	Use:     constname.UseInspectTileCmd,
+	Short:   constname.ShortInspectTileCmd,
+	Long:    constname.LongInspectTileCmd,
+	Example: constname.ExampleInspectTileCmd,
+	Args:    cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if inspectCoordinates != coordinatesWGS84 && inspectCoordinates != coordinatesTile {
+			return fmt.Errorf("unknown coordinates %q, use %s or %s", inspectCoordinates, coordinatesWGS84, coordinatesTile)
+		}
+
+		tile, err := parseTile(args[1])
+		if err != nil {
+			return err
+		}
+
+		tileset, err := mbt.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = tileset.Close()
+		}()
+
+		data, err := tileset.Tile(int(tile.Z), int(tile.X), int(tile.Y))
+		if err != nil {
+			return fmt.Errorf("tile %s: %w", args[1], err)
+		}
+
+		var layers mvt.Layers
+		if inspectCoordinates == coordinatesWGS84 {
+			layers, err = mbt.DecodeTileWGS84(data, tile)
+		} else {
+			layers, err = mbt.DecodeTile(data)
+		}
+		if err != nil {
+			return fmt.Errorf("cannot decode tile %s: %w", args[1], err)
+		}
+
+		summaries := mbt.SummarizeLayers(layers)
+		if inspectSummary {
+			printLayerSummaries(tile, len(data), summaries)
+			return nil
+		}
+
+		return output.JSON(newTileCollection(tile, layers, summaries))
+	},
+}
+
+func init() {
+	inspectTileCmd.Flags().StringVar(&inspectCoordinates, "coordinates", coordinatesWGS84, "coordinates of geometry: wgs84 or tile")
+	inspectTileCmd.Flags().BoolVar(&inspectSummary, "summary", false, "print feature counts and attributes of layers as table instead of geojson")
+}
+
+// newTileCollection collection of layers with feature counts and attributes
+// of summaries of layers in properties
+func newTileCollection(tile maptile.Tile, layers mvt.Layers, summaries []mbt.LayerSummary) *tileCollection {
+	collection := &tileCollection{
+		Type: "FeatureCollection",
+		Properties: map[string]interface{}{
+			"zoom": tile.Z,
+			"x":    tile.X,
+			"y":    tile.Y,
+		},
+		Features: make([]layerCollection, 0, len(layers)),
+	}
+
+	for i, layer := range layers {
+		collection.Features = append(collection.Features, layerCollection{
+			Type: "FeatureCollection",
+			Properties: map[string]interface{}{
+				"layer":         layer.Name,
+				"version":       layer.Version,
+				"extent":        layer.Extent,
+				"feature_count": summaries[i].FeatureCount,
+				"geometries":    summaries[i].Geometries,
+				"attributes":    summaries[i].Attributes,
+			},
+			Features: layer.Features,
+		})
+	}
+
+	return collection
+}
+
+func printLayerSummaries(tile maptile.Tile, size int, summaries []mbt.LayerSummary) {
+	output.Title(fmt.Sprintf("Tile %d/%d/%d", tile.Z, tile.X, tile.Y))
+	output.Gray(fmt.Sprintf("%d layers, %s", len(summaries), formatSize(int64(size))))
+
+	for _, summary := range summaries {
+		geometries := make([]string, 0, len(summary.Geometries))
+		for geometry, count := range summary.Geometries {
+			geometries = append(geometries, fmt.Sprintf("%s: %d", geometry, count))
+		}
+		sort.Strings(geometries)
+
+		output.Title(fmt.Sprintf("Layer %s", summary.Name))
+		output.Gray(fmt.Sprintf("%d features (%s), extent %d, version %d",
+			summary.FeatureCount, strings.Join(geometries, ", "), summary.Extent, summary.Version))
+
+		rows := make([][]string, 0, len(summary.Attributes))
+		for _, attr := range summary.Attributes {
+			rows = append(rows, []string{
+				attr.Name,
+				strconv.Itoa(attr.Count),
+				strings.Join(attr.Types, ", "),
+				truncate(strings.Join(attr.Values, ", "), 60),
+			})
+		}
+		output.Table([]string{"Attribute", "Count", "Types", "Values"}, rows)
+	}
+}
+
+// parseTile parse tile coordinates in z/x/y format
+func parseTile(value string) (maptile.Tile, error) {
+	parts := strings.Split(value, "/")
+	if len(parts) != 3 {
+		return maptile.Tile{}, fmt.Errorf("invalid tile %q, use z/x/y", value)
+	}
+
+	z, errZ := strconv.ParseUint(parts[0], 10, 32)
+	x, errX := strconv.ParseUint(parts[1], 10, 32)
+	y, errY := strconv.ParseUint(parts[2], 10, 32)
+	if err := errors.Join(errZ, errX, errY); err != nil {
+		return maptile.Tile{}, fmt.Errorf("invalid tile %q: %w", value, err)
+	}
+
+	tile := maptile.New(uint32(x), uint32(y), maptile.Zoom(z))
+	if z > 30 || !tile.Valid() {
+		return maptile.Tile{}, fmt.Errorf("tile %q out of range", value)
+	}
+
+	return tile, nil
+}
@@ -0,0 +1,133 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/your-map/mbtiles-tool/configs/constname"
+	"github.com/your-map/mbtiles-tool/internal/component/output"
+	"github.com/your-map/mbtiles-tool/internal/mbt"
+)
+
+var metaJSON bool
+
+// metaCmd Command for change metadata of mbtiles file
+var metaCmd = &cobra.Command{
+	Use:     constname.UseMetaCmd,
+	Short:   constname.ShortMetaCmd,
+	Long:    constname.LongMetaCmd,
+	Example: constname.ExampleMetaCmd,
+}
+
+var metaGetCmd = &cobra.Command{
+	Use:   constname.UseMetaGetCmd,
+	Short: constname.ShortMetaGetCmd,
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tileset, err := mbt.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = tileset.Close()
+		}()
+
+		metadata, err := tileset.Metadata()
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 2 {
+			value, ok := metadata[args[1]]
+			if !ok {
+				return fmt.Errorf("metadata key %q not found", args[1])
+			}
+
+			// Raw value without styles for use in scripts
+			fmt.Println(value)
+			return nil
+		}
+
+		if metaJSON {
+			return output.JSON(metadata)
+		}
+
+		names := make([]string, 0, len(metadata))
+		for name := range metadata {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		rows := make([][]string, 0, len(names))
+		for _, name := range names {
+			rows = append(rows, []string{name, truncate(metadata[name], 80)})
+		}
+		output.Table([]string{"Name", "Value"}, rows)
+
+		return nil
+	},
+}
+
+var metaSetCmd = &cobra.Command{
+	Use:   constname.UseMetaSetCmd,
+	Short: constname.ShortMetaSetCmd,
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, value := args[1], args[2]
+		if err := mbt.CheckMetadata(name, value); err != nil {
+			return err
+		}
+
+		tileset, err := mbt.OpenWritable(args[0])
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = tileset.Close()
+		}()
+
+		if err = tileset.SetMetadata(name, value); err != nil {
+			return err
+		}
+
+		output.Green(fmt.Sprintf("Success set %s of %s", name, args[0]))
+
+		return nil
+	},
+}
+
+var metaDeleteCmd = &cobra.Command{
+	Use:   constname.UseMetaDeleteCmd,
+	Short: constname.ShortMetaDeleteCmd,
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tileset, err := mbt.OpenWritable(args[0])
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = tileset.Close()
+		}()
+
+		for _, name := range args[1:] {
+			deleted, err := tileset.DeleteMetadata(name)
+			if err != nil {
+				return err
+			}
+
+			if deleted == 0 {
+				output.Yellow(fmt.Sprintf("Metadata key %s not found", name))
+				continue
+			}
+			output.Green(fmt.Sprintf("Success delete %s of %s", name, args[0]))
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	metaGetCmd.Flags().BoolVar(&metaJSON, "json", false, "print all metadata as json")
+	metaCmd.AddCommand(metaGetCmd, metaSetCmd, metaDeleteCmd)
+}
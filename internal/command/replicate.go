@@ -0,0 +1,80 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/your-map/mbtiles-tool/configs/constname"
+	"github.com/your-map/mbtiles-tool/internal/component/output"
+	"github.com/your-map/mbtiles-tool/pkg/tiles"
+)
+
+var (
+	replicateSource   string
+	replicateURL      string
+	replicateMaxDiffs int
+	replicateFollow   bool
+	replicateInterval time.Duration
+)
+
+// replicateCmd Command for follow osm replication
+var replicateCmd = &cobra.Command{
+	Use:     constname.UseReplicateCmd,
+	Short:   constname.ShortReplicateCmd,
+	Long:    constname.LongReplicateCmd,
+	Example: constname.ExampleReplicateCmd,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if replicateFollow && replicateInterval <= 0 {
+			return fmt.Errorf("interval %s must be positive", replicateInterval)
+		}
+
+		options := tiles.ReplicateOptions{
+			Source:   replicateSource,
+			URL:      replicateURL,
+			MaxDiffs: replicateMaxDiffs,
+			OnApplied: func(sequence int64, update *tiles.UpdateResult) {
+				output.Gray(fmt.Sprintf(
+					"Sequence %d: %d nodes, %d ways, %d tiles written, %d tiles deleted",
+					sequence, update.Changes.Nodes, update.Changes.Ways, update.Tiles.TilesWritten, update.Tiles.TilesDeleted,
+				))
+			},
+			OnError: func(err error) {
+				output.Red(fmt.Sprintf("Reading replication failed, retry in %s: %v", replicateInterval, err))
+			},
+		}
+		if replicateFollow {
+			options.Interval = replicateInterval
+		}
+
+		// Interrupt stops after the diff being applied, its sequence is saved
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		result, err := tiles.NewMap(args[0]).Replicate(ctx, options)
+		if err != nil {
+			return err
+		}
+
+		if result.Applied == result.Start {
+			output.Green(fmt.Sprintf("Tileset is up to date with sequence %d", result.Applied))
+			return nil
+		}
+
+		output.Green(fmt.Sprintf("Success replicate: sequence %d -> %d, latest %d", result.Start, result.Applied, result.Latest))
+
+		return nil
+	},
+}
+
+func init() {
+	replicateCmd.Flags().StringVar(&replicateSource, "source", "", "osm pbf file the tileset was converted from")
+	replicateCmd.Flags().StringVar(&replicateURL, "url", "", "replication url or local directory, replication url of metadata by default")
+	replicateCmd.Flags().IntVar(&replicateMaxDiffs, "max-diffs", 0, "limit of applied diffs, 0 applies all available diffs")
+	replicateCmd.Flags().BoolVar(&replicateFollow, "follow", false, "keep running and apply new diffs until interrupted")
+	replicateCmd.Flags().DurationVar(&replicateInterval, "interval", time.Minute, "wait between checks of new diffs with --follow")
+	_ = replicateCmd.MarkFlagRequired("source")
+}
@@ -25,6 +25,16 @@ func Run() {
 	// Add all command in your app
 	cmd.AddCommand(
 		convertCmd,
+		serveCmd,
+		infoCmd,
+		inspectTileCmd,
+		validateCmd,
+		diffCmd,
+		updateCmd,
+		replicateCmd,
+		filterCmd,
+		clipCmd,
+		metaCmd,
 	)
 
 	if err := fang.Execute(
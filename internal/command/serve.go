@@ -0,0 +1,80 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/your-map/mbtiles-tool/configs/constname"
+	"github.com/your-map/mbtiles-tool/internal/component/output"
+	"github.com/your-map/mbtiles-tool/internal/server"
+)
+
+const shutdownTimeout = 5 * time.Second
+
+var (
+	serveAddr   string
+	serveMaxAge int
+)
+
+// serveCmd Command for serve mbtiles over http
+var serveCmd = &cobra.Command{
+	Use:     constname.UseServeCmd,
+	Short:   constname.ShortServeCmd,
+	Long:    constname.LongServeCmd,
+	Example: constname.ExampleServeCmd,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tileServer, err := server.NewServer(args[0], serveMaxAge)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = tileServer.Close()
+		}()
+
+		httpServer := &http.Server{
+			Addr:              serveAddr,
+			Handler:           tileServer,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+			_ = httpServer.Shutdown(shutdownCtx)
+		}()
+
+		for _, name := range tileServer.Names() {
+			output.Green(fmt.Sprintf("Serve tileset %s: http://%s/%s/tiles.json", name, displayAddr(serveAddr), name))
+		}
+
+		if err = httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "localhost:8080", "address to listen on")
+	serveCmd.Flags().IntVar(&serveMaxAge, "max-age", 3600, "max-age of the Cache-Control header for tiles in seconds")
+}
+
+func displayAddr(addr string) string {
+	if len(addr) > 0 && addr[0] == ':' {
+		return "localhost" + addr
+	}
+
+	return addr
+}
@@ -0,0 +1,48 @@
+package command
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/your-map/mbtiles-tool/configs/constname"
+	"github.com/your-map/mbtiles-tool/internal/component/output"
+	"github.com/your-map/mbtiles-tool/pkg/tiles"
+)
+
+var (
+	updateSource   string
+	updateSequence int64
+)
+
+// updateCmd Command for apply osm changes to mbtiles file
+var updateCmd = &cobra.Command{
+	Use:     constname.UseUpdateCmd,
+	Short:   constname.ShortUpdateCmd,
+	Long:    constname.LongUpdateCmd,
+	Example: constname.ExampleUpdateCmd,
+	Args:    cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := tiles.NewMap(args[0]).Update(updateSource, args[1:], updateSequence)
+		if err != nil {
+			return err
+		}
+
+		output.Title(args[0])
+		output.Table([]string{"Changed", "Count"}, [][]string{
+			{"nodes", strconv.Itoa(result.Changes.Nodes)},
+			{"ways", strconv.Itoa(result.Changes.Ways)},
+			{"relations", strconv.Itoa(result.Changes.Relations)},
+		})
+		output.Gray(fmt.Sprintf("%d tiles checked, %d unchanged", result.Tiles.TilesChecked, result.Tiles.TilesUnchanged))
+		output.Green(fmt.Sprintf("Success update: %d tiles written, %d tiles deleted", result.Tiles.TilesWritten, result.Tiles.TilesDeleted))
+
+		return nil
+	},
+}
+
+func init() {
+	updateCmd.Flags().StringVar(&updateSource, "source", "", "osm pbf file the tileset was converted from")
+	updateCmd.Flags().Int64Var(&updateSequence, "sequence", -1, "replication sequence of the last change file")
+	_ = updateCmd.MarkFlagRequired("source")
+}
@@ -0,0 +1,111 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/your-map/mbtiles-tool/configs/constname"
+	"github.com/your-map/mbtiles-tool/internal/component/output"
+	"github.com/your-map/mbtiles-tool/internal/mbt"
+	"github.com/your-map/mbtiles-tool/internal/validate"
+)
+
+var errValidation = errors.New("validation failed")
+
+// maxTableFindings Limit of findings of one check shown in table, the rest is only counted
+const maxTableFindings = 20
+
+var (
+	validateJSON   bool
+	validateStrict bool
+)
+
+// validateCmd Command for check mbtiles file
+var validateCmd = &cobra.Command{
+	Use:     constname.UseValidateCmd,
+	Short:   constname.ShortValidateCmd,
+	Long:    constname.LongValidateCmd,
+	Example: constname.ExampleValidateCmd,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tileset, err := mbt.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = tileset.Close()
+		}()
+
+		report, err := validate.NewValidator(tileset).Run()
+		if err != nil {
+			return err
+		}
+
+		failed := report.Errors > 0 || (validateStrict && report.Warnings > 0)
+
+		if validateJSON {
+			if err = output.JSON(report); err != nil {
+				return err
+			}
+
+			// Stdout has only the report for json, failure is given by exit code
+			if failed {
+				_ = tileset.Close()
+				os.Exit(1)
+			}
+
+			return nil
+		}
+
+		printReport(report)
+
+		if failed {
+			return fmt.Errorf("%w: %d errors, %d warnings", errValidation, report.Errors, report.Warnings)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	validateCmd.Flags().BoolVar(&validateJSON, "json", false, "print report as json")
+	validateCmd.Flags().BoolVar(&validateStrict, "strict", false, "fail on warnings too")
+}
+
+func printReport(report *validate.Report) {
+	output.Title(report.File)
+	output.Gray(fmt.Sprintf("%d tiles checked", report.TilesChecked))
+
+	// Table shows a limited count of findings of each check, json has all findings
+	shown := make(map[string]int)
+	hidden := make([]string, 0)
+	rows := make([][]string, 0, len(report.Findings))
+	for _, finding := range report.Findings {
+		key := string(finding.Severity) + ":" + finding.Check
+		if shown[key]++; shown[key] > maxTableFindings {
+			if shown[key] == maxTableFindings+1 {
+				hidden = append(hidden, key)
+			}
+			continue
+		}
+		rows = append(rows, []string{string(finding.Severity), finding.Check, finding.Tile, finding.Message})
+	}
+	if len(rows) > 0 {
+		output.Table([]string{"Severity", "Check", "Tile", "Message"}, rows)
+	}
+
+	for _, key := range hidden {
+		output.Gray(fmt.Sprintf("%d more findings of %s are not shown", shown[key]-maxTableFindings, key))
+	}
+
+	switch {
+	case report.Errors > 0:
+		output.Red(fmt.Sprintf("%d errors, %d warnings", report.Errors, report.Warnings))
+	case report.Warnings > 0:
+		output.Yellow(fmt.Sprintf("No errors, %d warnings", report.Warnings))
+	default:
+		output.Green("Tileset is valid")
+	}
+}
@@ -1,14 +1,19 @@
 package output
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 
 	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/charmbracelet/lipgloss/v2/table"
 )
 
 var (
-	green = lipgloss.Color("#04B575")
-	red   = lipgloss.Color("#D4634C")
+	green  = lipgloss.Color("#04B575")
+	red    = lipgloss.Color("#D4634C")
+	yellow = lipgloss.Color("#E5C07B")
+	gray   = lipgloss.Color("#7D8590")
 )
 
 func output(colorText string) {
@@ -22,3 +27,41 @@ func Green(str ...string) {
 func Red(str ...string) {
 	output(lipgloss.NewStyle().Foreground(red).Render(str...))
 }
+
+func Yellow(str ...string) {
+	output(lipgloss.NewStyle().Foreground(yellow).Render(str...))
+}
+
+func Gray(str ...string) {
+	output(lipgloss.NewStyle().Foreground(gray).Render(str...))
+}
+
+// Title Print bold header of section
+func Title(str ...string) {
+	output(lipgloss.NewStyle().Bold(true).Foreground(green).MarginTop(1).Render(str...))
+}
+
+// Table Print rows with headers in rounded border
+func Table(headers []string, rows [][]string) {
+	t := table.New().
+		Border(lipgloss.RoundedBorder()).
+		BorderStyle(lipgloss.NewStyle().Foreground(gray)).
+		StyleFunc(func(row, _ int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return lipgloss.NewStyle().Bold(true).Padding(0, 1)
+			}
+			return lipgloss.NewStyle().Padding(0, 1)
+		}).
+		Headers(headers...).
+		Rows(rows...)
+
+	output(t.Render())
+}
+
+// JSON Print value as indented json for scripts
+func JSON(value any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(value)
+}
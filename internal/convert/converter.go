@@ -1,24 +1,39 @@
 package convert
 
 import (
-	"io"
-
 	"github.com/your-map/mbtiles-tool/internal/mbt"
 	"github.com/your-map/mbtiles-tool/internal/osm"
 )
 
 type Converter struct {
-	File io.Reader
+	Reader osm.Reader
+	Output string
+	// Metadata name, description and other descriptive metadata of tileset
+	Metadata mbt.Metadata
+	// AttributeTypes types of attributes overriding mbt.DefaultAttributeTypes
+	AttributeTypes map[string]mbt.AttributeType
+	// IDEncoding encoding of feature ids, mbt.IDTypeCode if empty
+	IDEncoding mbt.IDEncoding
+	// IDProperties write osm id and type to properties of features
+	IDProperties bool
+	// Languages languages of name attributes, raw name tags are written if empty
+	Languages []mbt.Language
+	// Transliterate transliterate names to cyrillic or latin of languages
+	Transliterate bool
+	// Labels placement of label points of areas, mbt.DefaultLabelOptions if nil
+	Labels *mbt.LabelOptions
+	// Merge layers where features with equal attributes are merged, nothing is merged by default
+	Merge mbt.MergeOptions
+	// Ocean source of polygons of the ocean layer, the layer is not written by default
+	Ocean mbt.OceanOptions
 }
 
-func NewConverter(r io.Reader) *Converter {
-	return &Converter{File: r}
+func NewConverter(reader osm.Reader, output string) *Converter {
+	return &Converter{Reader: reader, Output: output}
 }
 
 func (c *Converter) OsmConvert() error {
-	newOSM := osm.NewOSM(c.File)
-
-	newMBT, err := mbt.NewMBT()
+	newMBT, err := mbt.NewMBT(c.Output)
 	if err != nil {
 		return err
 	}
@@ -29,14 +44,36 @@ func (c *Converter) OsmConvert() error {
 		}
 	}(newMBT)
 
-	dataChan, err := newOSM.Read()
+	newMBT.SetAttributeTypes(c.AttributeTypes)
+	if c.IDEncoding != "" || c.IDProperties {
+		encoding := c.IDEncoding
+		if encoding == "" {
+			encoding = mbt.IDTypeCode
+		}
+		newMBT.SetFeatureIDs(encoding, c.IDProperties)
+	}
+
+	if len(c.Languages) > 0 {
+		newMBT.SetLanguages(c.Languages, c.Transliterate)
+	}
+
+	if c.Labels != nil {
+		newMBT.SetLabels(*c.Labels)
+	}
+
+	newMBT.SetMerge(c.Merge)
+	if err = newMBT.SetOcean(c.Ocean); err != nil {
+		return err
+	}
+
+	dataChan, err := c.Reader.Read()
 	if err != nil {
 		return err
 	}
 
 	for data := range dataChan {
 		if data.Header != nil {
-			err = newMBT.WriteMetaData(data.Header)
+			err = newMBT.WriteMetaData(data.Header, c.Metadata)
 			if err != nil {
 				return err
 			}
@@ -50,6 +87,10 @@ func (c *Converter) OsmConvert() error {
 		}
 	}
 
+	if err = c.Reader.Err(); err != nil {
+		return err
+	}
+
 	err = newMBT.GenerateTiles()
 	if err != nil {
 		return err
@@ -0,0 +1,100 @@
+package convert
+
+import (
+	"time"
+
+	"github.com/your-map/mbtiles-tool/internal/mbt"
+	"github.com/your-map/mbtiles-tool/internal/osm"
+	"github.com/your-map/mbtiles-tool/internal/osm/proto"
+)
+
+// Updater Apply osm change files to tileset converted from source pbf
+type Updater struct {
+	// Header header block of source pbf
+	Header *proto.HeaderBlock
+
+	mbt       *mbt.MBT
+	sequence  int64
+	timestamp time.Time
+}
+
+// NewUpdater load source pbf of existing tileset from reader, tiles are not rendered
+func NewUpdater(reader osm.Reader, output string) (*Updater, error) {
+	// Check that tileset exists before open it for writing
+	tileset, err := mbt.Open(output)
+	if err != nil {
+		return nil, err
+	}
+	if err = tileset.Close(); err != nil {
+		return nil, err
+	}
+
+	newMBT, err := mbt.NewMBT(output)
+	if err != nil {
+		return nil, err
+	}
+
+	dataChan, err := reader.Read()
+	if err != nil {
+		_ = newMBT.Close()
+		return nil, err
+	}
+
+	var header *proto.HeaderBlock
+	for data := range dataChan {
+		if data.Header != nil {
+			header = data.Header
+			newMBT.ReadFeatures(header)
+		}
+
+		if data.Block != nil {
+			if err = newMBT.WriteBlockData(data.Block); err != nil {
+				_ = newMBT.Close()
+				return nil, err
+			}
+		}
+	}
+
+	if err = reader.Err(); err != nil {
+		_ = newMBT.Close()
+		return nil, err
+	}
+
+	return &Updater{Header: header, mbt: newMBT, sequence: -1}, nil
+}
+
+// Apply apply change to loaded data, sequence -1 means unknown sequence
+func (u *Updater) Apply(change *osm.OsmChange, sequence int64) mbt.ChangeStats {
+	if sequence >= 0 {
+		u.sequence = sequence
+	}
+	if change.Timestamp.After(u.timestamp) {
+		u.timestamp = change.Timestamp
+	}
+
+	return u.mbt.Apply(change)
+}
+
+// Replay apply change already written to tileset, tiles are not rendered
+func (u *Updater) Replay(change *osm.OsmChange) {
+	u.mbt.Apply(change)
+	u.mbt.ForgetChanges()
+}
+
+// Commit write changed tiles and replication state of applied changes
+func (u *Updater) Commit() (*mbt.UpdateStats, error) {
+	stats, err := u.mbt.UpdateTiles()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = u.mbt.SetReplicationState(u.sequence, u.timestamp); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func (u *Updater) Close() error {
+	return u.mbt.Close()
+}
@@ -0,0 +1,272 @@
+package diff
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+
+	"github.com/your-map/mbtiles-tool/internal/mbt"
+)
+
+type Change string
+
+var (
+	Added   Change = "added"
+	Removed Change = "removed"
+	Changed Change = "changed"
+)
+
+// MetadataChange Difference of one metadata key, empty value means missing key
+type MetadataChange struct {
+	Name string `json:"name"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+// ZoomDiff Count of tile changes on zoom level
+type ZoomDiff struct {
+	Zoom      int `json:"zoom"`
+	Added     int `json:"added"`
+	Removed   int `json:"removed"`
+	Changed   int `json:"changed"`
+	Unchanged int `json:"unchanged"`
+}
+
+// TileDiff Change of one tile with feature changes of layers
+type TileDiff struct {
+	Zoom   int         `json:"z"`
+	X      int         `json:"x"`
+	Y      int         `json:"y"`
+	Change Change      `json:"change"`
+	Layers []LayerDiff `json:"layers,omitempty"`
+}
+
+// Report Difference of two tilesets
+type Report struct {
+	Old      string           `json:"old"`
+	New      string           `json:"new"`
+	Metadata []MetadataChange `json:"metadata"`
+	Zooms    []ZoomDiff       `json:"zooms"`
+	Tiles    []TileDiff       `json:"tiles"`
+}
+
+// Options Options of comparing
+type Options struct {
+	// Features compare features of changed tiles
+	Features bool
+	// Patch write added and changed tiles to this tileset, removed tiles are written as empty
+	Patch *mbt.Tileset
+}
+
+type tileKey struct {
+	z, x, y int
+}
+
+// Differ Compare tiles and metadata of two tilesets
+type Differ struct {
+	old     *mbt.Tileset
+	new     *mbt.Tileset
+	options Options
+	zooms   map[int]*ZoomDiff
+	report  *Report
+	patch   *mbt.Batch
+}
+
+func NewDiffer(old, new *mbt.Tileset, options Options) *Differ {
+	return &Differ{
+		old:     old,
+		new:     new,
+		options: options,
+		zooms:   make(map[int]*ZoomDiff),
+		report: &Report{
+			Old:      old.File,
+			New:      new.File,
+			Metadata: make([]MetadataChange, 0),
+			Zooms:    make([]ZoomDiff, 0),
+			Tiles:    make([]TileDiff, 0),
+		},
+	}
+}
+
+// Run compare tilesets and write patch
+func (d *Differ) Run() (*Report, error) {
+	if err := d.diffMetadata(); err != nil {
+		return nil, err
+	}
+
+	if d.options.Patch != nil {
+		batch, err := d.options.Patch.NewBatch()
+		if err != nil {
+			return nil, err
+		}
+		d.patch = batch
+	}
+
+	if err := d.diffTiles(); err != nil {
+		if d.patch != nil {
+			_ = d.patch.Rollback()
+		}
+		return nil, err
+	}
+
+	if d.patch != nil {
+		if err := d.patch.Commit(); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, zoom := range d.zooms {
+		d.report.Zooms = append(d.report.Zooms, *zoom)
+	}
+	sort.Slice(d.report.Zooms, func(i, j int) bool {
+		return d.report.Zooms[i].Zoom < d.report.Zooms[j].Zoom
+	})
+
+	return d.report, nil
+}
+
+func (d *Differ) diffMetadata() error {
+	oldMetadata, err := d.old.Metadata()
+	if err != nil {
+		return err
+	}
+
+	newMetadata, err := d.new.Metadata()
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]bool)
+	for name := range oldMetadata {
+		names[name] = true
+	}
+	for name := range newMetadata {
+		names[name] = true
+	}
+
+	for name := range names {
+		if oldMetadata[name] != newMetadata[name] {
+			d.report.Metadata = append(d.report.Metadata, MetadataChange{
+				Name: name,
+				Old:  oldMetadata[name],
+				New:  newMetadata[name],
+			})
+		}
+	}
+	sort.Slice(d.report.Metadata, func(i, j int) bool {
+		return d.report.Metadata[i].Name < d.report.Metadata[j].Name
+	})
+
+	if d.options.Patch != nil {
+		for name, value := range newMetadata {
+			if err = d.options.Patch.SetMetadata(name, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (d *Differ) diffTiles() error {
+	// Only hashes of old tiles are kept in memory, data is read again for changed tiles
+	oldHashes := make(map[tileKey]uint64)
+	err := d.old.EachTile(func(z, x, y int, data []byte) error {
+		oldHashes[tileKey{z, x, y}] = hash(data)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = d.new.EachTile(func(z, x, y int, data []byte) error {
+		key := tileKey{z, x, y}
+		oldHash, exists := oldHashes[key]
+		delete(oldHashes, key)
+
+		switch {
+		case !exists:
+			d.zoom(z).Added++
+			d.report.Tiles = append(d.report.Tiles, TileDiff{Zoom: z, X: x, Y: y, Change: Added})
+			return d.writePatch(z, x, y, data)
+		case oldHash != hash(data):
+			d.zoom(z).Changed++
+			return d.changedTile(z, x, y, data)
+		default:
+			d.zoom(z).Unchanged++
+			return nil
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	removed := make([]tileKey, 0, len(oldHashes))
+	for key := range oldHashes {
+		removed = append(removed, key)
+	}
+	sort.Slice(removed, func(i, j int) bool {
+		a, b := removed[i], removed[j]
+		if a.z != b.z {
+			return a.z < b.z
+		}
+		if a.x != b.x {
+			return a.x < b.x
+		}
+		return a.y < b.y
+	})
+
+	for _, key := range removed {
+		d.zoom(key.z).Removed++
+		d.report.Tiles = append(d.report.Tiles, TileDiff{Zoom: key.z, X: key.x, Y: key.y, Change: Removed})
+		if err = d.writePatch(key.z, key.x, key.y, []byte{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *Differ) changedTile(z, x, y int, data []byte) error {
+	tileDiff := TileDiff{Zoom: z, X: x, Y: y, Change: Changed}
+
+	if d.options.Features {
+		oldData, err := d.old.Tile(z, x, y)
+		if err != nil {
+			return err
+		}
+
+		layers, err := diffLayers(oldData, data)
+		if err != nil {
+			return fmt.Errorf("tile %d/%d/%d: %w", z, x, y, err)
+		}
+		tileDiff.Layers = layers
+	}
+
+	d.report.Tiles = append(d.report.Tiles, tileDiff)
+
+	return d.writePatch(z, x, y, data)
+}
+
+func (d *Differ) writePatch(z, x, y int, data []byte) error {
+	if d.patch == nil {
+		return nil
+	}
+
+	return d.patch.PutTile(z, x, y, data)
+}
+
+func (d *Differ) zoom(z int) *ZoomDiff {
+	if _, ok := d.zooms[z]; !ok {
+		d.zooms[z] = &ZoomDiff{Zoom: z}
+	}
+
+	return d.zooms[z]
+}
+
+func hash(data []byte) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write(data)
+
+	return h.Sum64()
+}
@@ -0,0 +1,196 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/encoding/mvt"
+	"github.com/paulmach/orb/geojson"
+	"github.com/your-map/mbtiles-tool/internal/mbt"
+)
+
+// maxFeatureChanges Limit of detailed feature changes of one layer in one tile
+const maxFeatureChanges = 10
+
+// LayerDiff Feature changes of one layer in changed tile
+type LayerDiff struct {
+	Layer             string          `json:"layer"`
+	OldCount          int             `json:"old_count"`
+	NewCount          int             `json:"new_count"`
+	Added             int             `json:"added"`
+	Removed           int             `json:"removed"`
+	AttributesChanged int             `json:"attributes_changed"`
+	GeometryChanged   int             `json:"geometry_changed"`
+	Features          []FeatureChange `json:"features,omitempty"`
+}
+
+// FeatureChange Change of one feature matched by id
+type FeatureChange struct {
+	Feature    string            `json:"feature"`
+	Change     Change            `json:"change"`
+	Geometry   bool              `json:"geometry,omitempty"`
+	Attributes []AttributeChange `json:"attributes,omitempty"`
+}
+
+// AttributeChange Old and new value of attribute, nil means missing attribute
+type AttributeChange struct {
+	Name string      `json:"name"`
+	Old  interface{} `json:"old"`
+	New  interface{} `json:"new"`
+}
+
+func diffLayers(oldData, newData []byte) ([]LayerDiff, error) {
+	oldLayers, err := mbt.DecodeTile(oldData)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode old tile: %w", err)
+	}
+
+	newLayers, err := mbt.DecodeTile(newData)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode new tile: %w", err)
+	}
+
+	oldByName := layersByName(oldLayers)
+	newByName := layersByName(newLayers)
+
+	names := make([]string, 0)
+	for name := range oldByName {
+		names = append(names, name)
+	}
+	for name := range newByName {
+		if _, ok := oldByName[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	diffs := make([]LayerDiff, 0)
+	for _, name := range names {
+		layerDiff := diffFeatures(name, oldByName[name], newByName[name])
+		if layerDiff.Added+layerDiff.Removed+layerDiff.AttributesChanged+layerDiff.GeometryChanged > 0 {
+			diffs = append(diffs, layerDiff)
+		}
+	}
+
+	return diffs, nil
+}
+
+func diffFeatures(name string, oldFeatures, newFeatures []*geojson.Feature) LayerDiff {
+	layerDiff := LayerDiff{
+		Layer:    name,
+		OldCount: len(oldFeatures),
+		NewCount: len(newFeatures),
+	}
+
+	oldByKey := featuresByKey(oldFeatures)
+	newByKey := featuresByKey(newFeatures)
+
+	keys := make([]string, 0, len(oldByKey)+len(newByKey))
+	for key := range oldByKey {
+		keys = append(keys, key)
+	}
+	for key := range newByKey {
+		if _, ok := oldByKey[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		oldFeature, inOld := oldByKey[key]
+		newFeature, inNew := newByKey[key]
+
+		change := FeatureChange{Feature: key}
+		switch {
+		case !inOld:
+			layerDiff.Added++
+			change.Change = Added
+		case !inNew:
+			layerDiff.Removed++
+			change.Change = Removed
+		default:
+			change.Change = Changed
+			change.Attributes = diffAttributes(oldFeature.Properties, newFeature.Properties)
+			change.Geometry = !orb.Equal(oldFeature.Geometry, newFeature.Geometry)
+
+			if len(change.Attributes) > 0 {
+				layerDiff.AttributesChanged++
+			}
+			if change.Geometry {
+				layerDiff.GeometryChanged++
+			}
+			if len(change.Attributes) == 0 && !change.Geometry {
+				continue
+			}
+		}
+
+		if len(layerDiff.Features) < maxFeatureChanges {
+			layerDiff.Features = append(layerDiff.Features, change)
+		}
+	}
+
+	return layerDiff
+}
+
+func diffAttributes(oldProperties, newProperties geojson.Properties) []AttributeChange {
+	changes := make([]AttributeChange, 0)
+
+	for name, oldValue := range oldProperties {
+		if newValue, ok := newProperties[name]; !ok || !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, AttributeChange{Name: name, Old: oldValue, New: newProperties[name]})
+		}
+	}
+
+	for name, newValue := range newProperties {
+		if _, ok := oldProperties[name]; !ok {
+			changes = append(changes, AttributeChange{Name: name, New: newValue})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+
+	return changes
+}
+
+func layersByName(layers mvt.Layers) map[string][]*geojson.Feature {
+	byName := make(map[string][]*geojson.Feature, len(layers))
+	for _, layer := range layers {
+		byName[layer.Name] = append(byName[layer.Name], layer.Features...)
+	}
+
+	return byName
+}
+
+// featuresByKey match features by mvt id, by osm id and type from properties
+// or by geometry type and position in layer, repeated keys get a suffix
+func featuresByKey(features []*geojson.Feature) map[string]*geojson.Feature {
+	byKey := make(map[string]*geojson.Feature, len(features))
+	seen := make(map[string]int)
+
+	for i, feature := range features {
+		var key string
+		switch {
+		case feature.ID != nil:
+			key = fmt.Sprintf("id %v", feature.ID)
+		case feature.Properties["id"] != nil:
+			key = fmt.Sprintf("%v %v", feature.Properties["type"], feature.Properties["id"])
+		case feature.Geometry != nil:
+			key = fmt.Sprintf("%s #%d", feature.Geometry.GeoJSONType(), i)
+		default:
+			key = fmt.Sprintf("#%d", i)
+		}
+
+		seen[key]++
+		if seen[key] > 1 {
+			key = fmt.Sprintf("%s (%d)", key, seen[key])
+		}
+
+		byKey[key] = feature
+	}
+
+	return byKey
+}
@@ -0,0 +1,45 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+)
+
+func newFeature(id int, point orb.Point, properties geojson.Properties) *geojson.Feature {
+	feature := geojson.NewFeature(point)
+	feature.ID = id
+	feature.Properties = properties
+	return feature
+}
+
+func TestDiffFeatures(t *testing.T) {
+	oldFeatures := []*geojson.Feature{
+		newFeature(1, orb.Point{1, 1}, geojson.Properties{"name": "a"}),
+		newFeature(2, orb.Point{2, 2}, geojson.Properties{"name": "b"}),
+		newFeature(3, orb.Point{3, 3}, geojson.Properties{"name": "c"}),
+	}
+	newFeatures := []*geojson.Feature{
+		newFeature(1, orb.Point{1, 1}, geojson.Properties{"name": "a"}),
+		newFeature(2, orb.Point{2, 5}, geojson.Properties{"name": "b", "shop": "bakery"}),
+		newFeature(4, orb.Point{4, 4}, geojson.Properties{"name": "d"}),
+	}
+
+	got := diffFeatures("points", oldFeatures, newFeatures)
+
+	want := LayerDiff{Layer: "points", OldCount: 3, NewCount: 3, Added: 1, Removed: 1, AttributesChanged: 1, GeometryChanged: 1}
+	if got.Added != want.Added || got.Removed != want.Removed ||
+		got.AttributesChanged != want.AttributesChanged || got.GeometryChanged != want.GeometryChanged {
+		t.Errorf("diffFeatures() = %+v, want %+v", got, want)
+	}
+
+	if len(got.Features) != 3 {
+		t.Fatalf("diffFeatures() features = %+v, want 3 changes", got.Features)
+	}
+
+	changed := got.Features[0]
+	if changed.Feature != "id 2" || !changed.Geometry || len(changed.Attributes) != 1 || changed.Attributes[0].Name != "shop" {
+		t.Errorf("diffFeatures() change = %+v", changed)
+	}
+}
@@ -0,0 +1,103 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/your-map/mbtiles-tool/internal/osm"
+)
+
+var ErrInvalidExpression = errors.New("invalid filter expression")
+
+// Expression Tag condition in osmium style: [nwr/]key[=value,...] or [nwr/]key!=value,...
+type Expression struct {
+	// Types element types of condition, empty means all types
+	Types map[osm.MemberType]bool
+	Key   string
+	// Values allowed values, empty means any value
+	Values []string
+	// Negate key must exist with value other than Values
+	Negate bool
+}
+
+// ParseExpression parse expression like "w/highway=primary,secondary", "n/amenity" or "highway!=footway"
+func ParseExpression(value string) (*Expression, error) {
+	expression := &Expression{}
+	rest := strings.TrimSpace(value)
+
+	if prefix, condition, ok := strings.Cut(rest, "/"); ok && isTypes(prefix) {
+		expression.Types = make(map[osm.MemberType]bool)
+		for _, char := range prefix {
+			switch char {
+			case 'n':
+				expression.Types[osm.NodeMember] = true
+			case 'w':
+				expression.Types[osm.WayMember] = true
+			case 'r':
+				expression.Types[osm.RelationMember] = true
+			}
+		}
+		rest = condition
+	}
+
+	key, values, hasValue := strings.Cut(rest, "=")
+	if hasValue && strings.HasSuffix(key, "!") {
+		expression.Negate = true
+		key = strings.TrimSuffix(key, "!")
+	}
+
+	expression.Key = strings.TrimSpace(key)
+	if expression.Key == "" {
+		return nil, fmt.Errorf("%w: %q has no key", ErrInvalidExpression, value)
+	}
+
+	if hasValue && values != "*" {
+		for _, v := range strings.Split(values, ",") {
+			expression.Values = append(expression.Values, strings.TrimSpace(v))
+		}
+	}
+	if expression.Negate && len(expression.Values) == 0 {
+		return nil, fmt.Errorf("%w: %q has no values", ErrInvalidExpression, value)
+	}
+
+	return expression, nil
+}
+
+// Match check element type and tags
+func (e *Expression) Match(elementType osm.MemberType, tags map[string]string) bool {
+	if len(e.Types) > 0 && !e.Types[elementType] {
+		return false
+	}
+
+	value, ok := tags[e.Key]
+	if !ok {
+		return false
+	}
+
+	if len(e.Values) == 0 {
+		return true
+	}
+
+	for _, allowed := range e.Values {
+		if value == allowed {
+			return !e.Negate
+		}
+	}
+
+	return e.Negate
+}
+
+func isTypes(prefix string) bool {
+	if prefix == "" {
+		return false
+	}
+
+	for _, char := range prefix {
+		if char != 'n' && char != 'w' && char != 'r' {
+			return false
+		}
+	}
+
+	return true
+}
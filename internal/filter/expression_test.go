@@ -0,0 +1,93 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/your-map/mbtiles-tool/internal/osm"
+)
+
+func TestExpressionMatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		expression  string
+		elementType osm.MemberType
+		tags        map[string]string
+		want        bool
+	}{
+		{
+			name:        "any type with key",
+			expression:  "highway",
+			elementType: osm.NodeMember,
+			tags:        map[string]string{"highway": "crossing"},
+			want:        true,
+		},
+		{
+			name:        "other type",
+			expression:  "w/highway",
+			elementType: osm.NodeMember,
+			tags:        map[string]string{"highway": "crossing"},
+			want:        false,
+		},
+		{
+			name:        "one of values",
+			expression:  "wr/highway=primary,secondary",
+			elementType: osm.WayMember,
+			tags:        map[string]string{"highway": "secondary"},
+			want:        true,
+		},
+		{
+			name:        "other value",
+			expression:  "w/highway=primary",
+			elementType: osm.WayMember,
+			tags:        map[string]string{"highway": "footway"},
+			want:        false,
+		},
+		{
+			name:        "any value",
+			expression:  "n/amenity=*",
+			elementType: osm.NodeMember,
+			tags:        map[string]string{"amenity": "cafe"},
+			want:        true,
+		},
+		{
+			name:        "negated value",
+			expression:  "highway!=footway",
+			elementType: osm.WayMember,
+			tags:        map[string]string{"highway": "primary"},
+			want:        true,
+		},
+		{
+			name:        "negated without key",
+			expression:  "highway!=footway",
+			elementType: osm.WayMember,
+			tags:        map[string]string{"building": "yes"},
+			want:        false,
+		},
+		{
+			name:        "key with slash",
+			expression:  "name/alt=x",
+			elementType: osm.NodeMember,
+			tags:        map[string]string{"name/alt": "x"},
+			want:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expression, err := ParseExpression(tt.expression)
+			if err != nil {
+				t.Fatalf("ParseExpression() error = %v", err)
+			}
+			if got := expression.Match(tt.elementType, tt.tags); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseExpressionInvalid(t *testing.T) {
+	for _, value := range []string{"", "w/", "=primary", "n/!=x"} {
+		if _, err := ParseExpression(value); err == nil {
+			t.Errorf("ParseExpression(%q) expected error", value)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package filter
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"github.com/your-map/mbtiles-tool/internal/osm"
+	osmp "github.com/your-map/mbtiles-tool/internal/osm/proto"
+)
+
+// Filter Extract elements matching any expression from pbf file with referenced
+// members, so geometry of written ways and relations is complete
+type Filter struct {
+	File        string
+	Expressions []*Expression
+	Compression osm.Compression
+	// Index blob index of file, later passes read only blobs with needed
+	// element types in parallel, file is read sequentially without index
+	Index *osm.Index
+
+	extract *osm.Extract
+}
+
+func NewFilter(file string, expressions []*Expression, compression osm.Compression) *Filter {
+	f := &Filter{
+		File:        file,
+		Expressions: expressions,
+		Compression: compression,
+	}
+	f.extract = osm.NewExtract(f.each, compression)
+
+	return f
+}
+
+// Run filter the file in three passes: matched relations and ways, nodes of ways
+// referenced by relations and writing of kept elements
+func (f *Filter) Run(w io.Writer) (*osm.ExtractResult, error) {
+	if len(f.Expressions) == 0 {
+		return nil, errors.New("no filter expressions")
+	}
+
+	if err := f.matchWaysAndRelations(); err != nil {
+		return nil, err
+	}
+
+	if err := f.extract.CollectWayNodes(); err != nil {
+		return nil, err
+	}
+
+	return f.extract.Write(w, func(node *osm.Node) bool {
+		return f.match(osm.NodeMember, node.Tags)
+	}, nil)
+}
+
+func (f *Filter) match(elementType osm.MemberType, tags map[string]string) bool {
+	for _, expression := range f.Expressions {
+		if expression.Match(elementType, tags) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *Filter) matchWaysAndRelations() error {
+	members := make(map[int64][]osm.Member)
+
+	err := f.each([]osm.MemberType{osm.WayMember, osm.RelationMember}, func(elements *osm.Elements) {
+		for _, way := range elements.Ways {
+			if f.match(osm.WayMember, way.Tags) {
+				f.extract.KeepWay(way)
+			}
+		}
+
+		for _, relation := range elements.Relations {
+			members[relation.ID] = relation.Members
+			if f.match(osm.RelationMember, relation.Tags) {
+				f.extract.KeepRelation(relation.ID)
+			}
+		}
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	// Members of kept relations are kept too
+	f.extract.CompleteRelations(members)
+
+	return nil
+}
+
+// each read the file again and decode primitive blocks, with index only blocks
+// with elements of types are read, nil types means all blocks
+func (f *Filter) each(types []osm.MemberType, onBlock func(elements *osm.Elements), onHeader func(header *osmp.HeaderBlock)) error {
+	file, err := os.Open(f.File)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var reader osm.Reader = osm.NewOSM(file)
+	if f.Index != nil {
+		blobs := f.Index.Blobs
+		if types != nil {
+			blobs = f.Index.Select(types...)
+		}
+		reader = osm.NewIndexedReader(file, blobs)
+	}
+
+	dataChan, err := reader.Read()
+	if err != nil {
+		return err
+	}
+
+	for data := range dataChan {
+		if data.Header != nil && onHeader != nil {
+			onHeader(data.Header)
+		}
+
+		if data.Block != nil {
+			onBlock(osm.Decode(data.Block))
+		}
+	}
+
+	return reader.Err()
+}
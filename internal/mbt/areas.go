@@ -0,0 +1,329 @@
+package mbt
+
+import (
+	"math"
+	"sort"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/encoding/mvt"
+	"github.com/paulmach/orb/planar"
+)
+
+// areaKeys Keys of closed ways which are areas with any value
+var areaKeys = map[string]bool{
+	"building":      true,
+	"building:part": true,
+	"landuse":       true,
+	"leisure":       true,
+	"amenity":       true,
+	"shop":          true,
+	"tourism":       true,
+	"historic":      true,
+	"military":      true,
+	"water":         true,
+	"place":         true,
+	"area:highway":  true,
+}
+
+// lineValues Values of natural and waterway keys which are lines even on closed ways
+var lineValues = map[string]map[string]bool{
+	"natural":  {"coastline": true, "cliff": true, "ridge": true, "arete": true, "tree_row": true, "earth_bank": true},
+	"waterway": {"river": true, "stream": true, "canal": true, "drain": true, "ditch": true, "weir": true, "dam": true},
+}
+
+// isArea closed way is area by area=yes or tags of areas, area=no makes a line
+func (w *WayData) isArea() bool {
+	if len(w.Refs) < 4 || w.Refs[0] != w.Refs[len(w.Refs)-1] {
+		return false
+	}
+
+	switch w.Tags["area"] {
+	case "yes":
+		return true
+	case "no":
+		return false
+	}
+
+	for key := range w.Tags {
+		if areaKeys[key] {
+			return true
+		}
+	}
+
+	for key, values := range lineValues {
+		if value, ok := w.Tags[key]; ok && !values[value] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// polygon polygon of area way from its nodes
+func (w *WayData) polygon() orb.Polygon {
+	ring := make(orb.Ring, len(w.Nodes))
+	for i, node := range w.Nodes {
+		ring[i] = orb.Point{node.Lon, node.Lat}
+	}
+
+	return orb.Polygon{ring}
+}
+
+// bound bound of nodes of way, false for way without nodes
+func (w *WayData) bound() (orb.Bound, bool) {
+	if len(w.Nodes) == 0 {
+		return orb.Bound{}, false
+	}
+
+	bound := orb.Bound{Min: orb.Point{w.Nodes[0].Lon, w.Nodes[0].Lat}, Max: orb.Point{w.Nodes[0].Lon, w.Nodes[0].Lat}}
+	for _, node := range w.Nodes[1:] {
+		bound = bound.Extend(orb.Point{node.Lon, node.Lat})
+	}
+
+	return bound, true
+}
+
+// maxSplitPasses Passes of splitting rings where they touch or cross themselves,
+// rounded crossing points may make new crossings, rings still crossing are dropped
+const maxSplitPasses = 4
+
+// cleanPolygons round polygons projected to tile to integer coordinates of
+// encoding and remove repeated points, spikes and rings without area, which
+// small areas get on low zooms, rings touching or crossing themselves after
+// simplification and rounding are split, features without exterior ring are removed
+func cleanPolygons(layer *mvt.Layer) {
+	at := 0
+	for _, feature := range layer.Features {
+		switch geometry := feature.Geometry.(type) {
+		case orb.Polygon:
+			feature.Geometry = nil
+			if polygons := cleanPolygon(geometry); len(polygons) == 1 {
+				feature.Geometry = polygons[0]
+			} else if len(polygons) > 1 {
+				feature.Geometry = polygons
+			}
+		case orb.MultiPolygon:
+			polygons := make(orb.MultiPolygon, 0, len(geometry))
+			for _, polygon := range geometry {
+				polygons = append(polygons, cleanPolygon(polygon)...)
+			}
+			feature.Geometry = polygons
+			if len(polygons) == 0 {
+				feature.Geometry = nil
+			}
+		}
+
+		if feature.Geometry != nil {
+			layer.Features[at] = feature
+			at++
+		}
+	}
+
+	layer.Features = layer.Features[:at]
+}
+
+// cleanPolygon polygons of simple rings of polygon, holes are kept in the part
+// of exterior ring containing them, nil if exterior ring is degenerate
+func cleanPolygon(polygon orb.Polygon) orb.MultiPolygon {
+	var polygons orb.MultiPolygon
+	for i, ring := range polygon {
+		for _, loop := range simpleRings(cleanRing(ring)) {
+			if i == 0 {
+				polygons = append(polygons, orb.Polygon{loop})
+			} else if part := containingPolygon(polygons, loop); part >= 0 {
+				polygons[part] = append(polygons[part], loop)
+			}
+		}
+
+		if len(polygons) == 0 {
+			return nil
+		}
+	}
+
+	return polygons
+}
+
+func cleanRing(ring orb.Ring) orb.Ring {
+	result := make(orb.Ring, 0, len(ring))
+	for _, point := range ring {
+		point = orb.Point{math.Round(point[0]), math.Round(point[1])}
+		if len(result) > 0 && result[len(result)-1] == point {
+			continue
+		}
+
+		// Spike a-b-a is removed with its top
+		if len(result) > 1 && result[len(result)-2] == point {
+			result = result[:len(result)-1]
+			continue
+		}
+		result = append(result, point)
+	}
+
+	return result
+}
+
+// simpleRings rounded ring split to loops at points where it touches or crosses
+// itself, loops winding against the ring are twists and are dropped
+func simpleRings(ring orb.Ring) []orb.Ring {
+	orientation := ring.Orientation()
+	if len(ring) < 4 || orientation == 0 {
+		return nil
+	}
+
+	var result []orb.Ring
+	pending := []orb.Ring{ring}
+	for pass := 0; pass < maxSplitPasses && len(pending) > 0; pass++ {
+		var next []orb.Ring
+		for _, r := range pending {
+			noded, crossed := nodeRing(r)
+			for _, loop := range splitRing(noded) {
+				if loop = cleanRing(loop); len(loop) < 4 || loop.Orientation() != orientation {
+					continue
+				}
+
+				// Loops of a ring with new points are checked again
+				if crossed {
+					next = append(next, loop)
+				} else {
+					result = append(result, loop)
+				}
+			}
+		}
+		pending = next
+	}
+
+	return result
+}
+
+// nodeRing ring with points where segments cross or touch other segments added
+// to both segments, crossing points are rounded, false if no point is added
+func nodeRing(ring orb.Ring) (orb.Ring, bool) {
+	splits := make(map[int][]orb.Point)
+	split := func(segment int, point orb.Point) {
+		if point != ring[segment] && point != ring[segment+1] {
+			splits[segment] = append(splits[segment], point)
+		}
+	}
+
+	overlappingSegments(ring, func(i, j int) bool {
+		a, b, c, d := ring[i], ring[i+1], ring[j], ring[j+1]
+		d1, d2, d3, d4 := cross(c, d, a), cross(c, d, b), cross(a, b, c), cross(a, b, d)
+		if d1*d2 < 0 && d3*d4 < 0 {
+			t := d1 / (d1 - d2)
+			point := orb.Point{math.Round(a[0] + t*(b[0]-a[0])), math.Round(a[1] + t*(b[1]-a[1]))}
+			split(i, point)
+			split(j, point)
+			return true
+		}
+
+		// Ends of segment on the other one, like segments of collinear spikes
+		if d1 == 0 && onSegment(c, d, a) {
+			split(j, a)
+		}
+		if d2 == 0 && onSegment(c, d, b) {
+			split(j, b)
+		}
+		if d3 == 0 && onSegment(a, b, c) {
+			split(i, c)
+		}
+		if d4 == 0 && onSegment(a, b, d) {
+			split(i, d)
+		}
+		return true
+	})
+
+	if len(splits) == 0 {
+		return ring, false
+	}
+
+	noded := make(orb.Ring, 0, len(ring)+len(splits))
+	for i := 0; i < len(ring)-1; i++ {
+		noded = append(noded, ring[i])
+
+		points := splits[i]
+		start := ring[i]
+		sort.Slice(points, func(a, b int) bool {
+			return planar.DistanceSquared(start, points[a]) < planar.DistanceSquared(start, points[b])
+		})
+		for k, point := range points {
+			if k == 0 || point != points[k-1] {
+				noded = append(noded, point)
+			}
+		}
+	}
+
+	return append(noded, noded[0]), true
+}
+
+// splitRing loops of closed ring between repeated points
+func splitRing(ring orb.Ring) []orb.Ring {
+	var loops []orb.Ring
+	path := make(orb.Ring, 0, len(ring))
+	index := make(map[orb.Point]int, len(ring))
+	for _, point := range ring[:len(ring)-1] {
+		if at, ok := index[point]; ok {
+			loop := append(orb.Ring{}, path[at:]...)
+			loops = append(loops, append(loop, point))
+			for _, p := range path[at+1:] {
+				delete(index, p)
+			}
+			path = path[:at+1]
+			continue
+		}
+
+		index[point] = len(path)
+		path = append(path, point)
+	}
+
+	return append(loops, append(path, path[0]))
+}
+
+// containingPolygon index of polygon with exterior ring containing all points
+// of hole, -1 if hole is outside of all polygons
+func containingPolygon(polygons orb.MultiPolygon, hole orb.Ring) int {
+	if len(polygons) == 1 {
+		return 0
+	}
+
+	for i, polygon := range polygons {
+		inside := true
+		for _, point := range hole {
+			if inside = planar.RingContains(polygon[0], point); !inside {
+				break
+			}
+		}
+		if inside {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// orientPolygons set winding order of the mvt spec to polygons projected to tile:
+// exterior rings have positive area with y axis down, interior rings negative
+func orientPolygons(layer *mvt.Layer) {
+	for _, feature := range layer.Features {
+		switch geometry := feature.Geometry.(type) {
+		case orb.Polygon:
+			orientPolygon(geometry)
+		case orb.MultiPolygon:
+			for _, polygon := range geometry {
+				orientPolygon(polygon)
+			}
+		}
+	}
+}
+
+func orientPolygon(polygon orb.Polygon) {
+	for i, ring := range polygon {
+		want := orb.CCW
+		if i > 0 {
+			want = orb.CW
+		}
+
+		if ring.Orientation() != want {
+			ring.Reverse()
+		}
+	}
+}
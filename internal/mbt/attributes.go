@@ -0,0 +1,144 @@
+package mbt
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// AttributeTypesKey Metadata key of attribute types set for convert, updates use the same types
+const AttributeTypesKey = "attribute_types"
+
+var ErrInvalidAttributeType = errors.New("invalid attribute type")
+
+type AttributeType string
+
+var (
+	AttributeString  AttributeType = "string"
+	AttributeNumber  AttributeType = "number"
+	AttributeBoolean AttributeType = "boolean"
+)
+
+// DefaultAttributeTypes Types of osm keys with numeric and boolean values,
+// other keys are written as strings
+var DefaultAttributeTypes = map[string]AttributeType{
+	"population":      AttributeNumber,
+	"ele":             AttributeNumber,
+	"lanes":           AttributeNumber,
+	"height":          AttributeNumber,
+	"building:levels": AttributeNumber,
+	"layer":           AttributeNumber,
+	"capacity":        AttributeNumber,
+	"admin_level":     AttributeNumber,
+	"oneway":          AttributeBoolean,
+	"bridge":          AttributeBoolean,
+	"tunnel":          AttributeBoolean,
+	"wheelchair":      AttributeBoolean,
+}
+
+// ParseAttributeType parse type name of attribute
+func ParseAttributeType(value string) (AttributeType, error) {
+	switch attributeType := AttributeType(strings.ToLower(value)); attributeType {
+	case AttributeString, AttributeNumber, AttributeBoolean:
+		return attributeType, nil
+	default:
+		return "", fmt.Errorf("%w: %q, use string, number or boolean", ErrInvalidAttributeType, value)
+	}
+}
+
+// SetAttributeTypes override default types of attributes, they are saved to metadata
+func (m *MBT) SetAttributeTypes(types map[string]AttributeType) {
+	for key, attributeType := range types {
+		m.attributeTypes[key] = attributeType
+		m.customTypes[key] = attributeType
+	}
+}
+
+// setTags write tags to properties of feature with types of attributes,
+// name tags are normalized when languages are set
+func (m *MBT) setTags(properties map[string]interface{}, tags map[string]string) {
+	for key, value := range tags {
+		if len(m.languages) > 0 && isNameKey(key) {
+			continue
+		}
+		properties[key] = m.attributeValue(key, value)
+	}
+
+	if len(m.languages) > 0 {
+		m.setNames(properties, tags)
+	}
+}
+
+// loadAttributeTypes types of attributes of existing tileset
+func (m *MBT) loadAttributeTypes() error {
+	m.attributeTypes = make(map[string]AttributeType, len(DefaultAttributeTypes))
+	for key, attributeType := range DefaultAttributeTypes {
+		m.attributeTypes[key] = attributeType
+	}
+	m.customTypes = make(map[string]AttributeType)
+
+	var raw string
+	err := m.db.QueryRow("SELECT value FROM metadata WHERE name = ?", AttributeTypesKey).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	types := make(map[string]AttributeType)
+	if err = json.Unmarshal([]byte(raw), &types); err != nil {
+		return fmt.Errorf("invalid metadata %s: %w", AttributeTypesKey, err)
+	}
+	m.SetAttributeTypes(types)
+
+	return nil
+}
+
+// attributeValue value of tag converted to type of attribute, values which
+// can not be converted are written as strings
+func (m *MBT) attributeValue(key, value string) interface{} {
+	switch m.attributeTypes[key] {
+	case AttributeNumber:
+		if number, ok := parseNumber(value); ok {
+			return number
+		}
+	case AttributeBoolean:
+		if boolean, ok := parseBoolean(value); ok {
+			return boolean
+		}
+	}
+
+	return value
+}
+
+// parseNumber integer values are int64, other values are float64
+func parseNumber(value string) (interface{}, bool) {
+	value = strings.TrimSpace(value)
+
+	if integer, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return integer, true
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+		return nil, false
+	}
+
+	return number, true
+}
+
+func parseBoolean(value string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "yes", "true", "1":
+		return true, true
+	case "no", "false", "0":
+		return false, true
+	default:
+		return false, false
+	}
+}
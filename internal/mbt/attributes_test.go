@@ -0,0 +1,41 @@
+package mbt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMBT_attributeValue(t *testing.T) {
+	m := &MBT{attributeTypes: DefaultAttributeTypes}
+
+	tests := []struct {
+		key   string
+		value string
+		want  interface{}
+	}{
+		{key: "population", value: "22256", want: int64(22256)},
+		{key: "ele", value: "1200.5", want: 1200.5},
+		{key: "ele", value: "1200 m", want: "1200 m"},
+		{key: "lanes", value: "NaN", want: "NaN"},
+		{key: "oneway", value: "yes", want: true},
+		{key: "oneway", value: "no", want: false},
+		{key: "oneway", value: "-1", want: "-1"},
+		{key: "name", value: "42", want: "42"},
+	}
+	for _, tt := range tests {
+		if got := m.attributeValue(tt.key, tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("attributeValue(%q, %q) = %#v, want %#v", tt.key, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseAttributeType(t *testing.T) {
+	for _, value := range []string{"string", "Number", "boolean"} {
+		if _, err := ParseAttributeType(value); err != nil {
+			t.Errorf("ParseAttributeType(%q) error = %v", value, err)
+		}
+	}
+	if _, err := ParseAttributeType("int"); err == nil {
+		t.Error("ParseAttributeType(\"int\") expected error")
+	}
+}
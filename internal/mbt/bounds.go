@@ -0,0 +1,158 @@
+package mbt
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/encoding/mvt"
+	"github.com/paulmach/orb/maptile"
+	"github.com/your-map/mbtiles-tool/internal/osm/proto"
+)
+
+// Limits of web mercator tiles
+const (
+	maxMercatorLat = 85.0511287798
+	maxMercatorLon = 180.0
+)
+
+// Metadata keys of bounds and center computed from written features, bounds
+// and center set by user differ from them and are not changed by updates
+const (
+	ComputedBoundsKey = "computed_bounds"
+	ComputedCenterKey = "computed_center"
+)
+
+// boundsCollector Bounds of features written to tiles of maxZoom and the tile
+// with the most features, geometry of maxZoom is precise enough for bounds
+type boundsCollector struct {
+	bound orb.Bound
+	found bool
+
+	densest      maptile.Tile
+	densestCount int
+}
+
+// add collect features of encoded layers of tile, layers are projected to tile
+func (b *boundsCollector) add(layers []*mvt.Layer, tile maptile.Tile) {
+	count := 0
+	for _, layer := range layers {
+		extent := float64(layer.Extent)
+		if extent == 0 {
+			extent = mvt.DefaultExtent
+		}
+
+		for _, feature := range layer.Features {
+			projected := feature.Geometry.Bound()
+			b.extend(tilePoint(tile, projected.Min, extent))
+			b.extend(tilePoint(tile, projected.Max, extent))
+		}
+		count += len(layer.Features)
+	}
+
+	if count > b.densestCount {
+		b.densest = tile
+		b.densestCount = count
+	}
+}
+
+func (b *boundsCollector) extend(point orb.Point) {
+	if !b.found {
+		b.bound = orb.Bound{Min: point, Max: point}
+		b.found = true
+		return
+	}
+	b.bound = b.bound.Extend(point)
+}
+
+// metadata bounds and center of written features, bound of header is used
+// when nothing is written, false if both are unknown
+func (b *boundsCollector) metadata(header *orb.Bound) (string, string, bool) {
+	bound := b.bound
+	if !b.found {
+		if header == nil {
+			return "", "", false
+		}
+		bound = *header
+	}
+	bound = clampBound(bound)
+
+	center := bound.Center()
+	if b.densestCount > 0 {
+		center = clampBound(b.densest.Bound()).Center()
+	}
+
+	bounds := fmt.Sprintf("%f,%f,%f,%f", bound.Min.Lon(), bound.Min.Lat(), bound.Max.Lon(), bound.Max.Lat())
+	centerValue := fmt.Sprintf("%f,%f,%d", center.Lon(), center.Lat(), fitZoom(bound))
+
+	return bounds, centerValue, true
+}
+
+// merge bounds and center of metadata extended by collected features for
+// updates, the center is kept if it is inside of bounds. False if nothing is collected
+func (b *boundsCollector) merge(bounds, center string) (string, string, bool) {
+	if !b.found {
+		return "", "", false
+	}
+
+	merged := *b
+	if values, err := parseNumbers(bounds); err == nil && len(values) == 4 {
+		merged.extend(orb.Point{values[0], values[1]})
+		merged.extend(orb.Point{values[2], values[3]})
+	}
+	mergedBounds, mergedCenter, _ := merged.metadata(nil)
+
+	if values, err := parseNumbers(center); err == nil && len(values) == 3 && merged.bound.Contains(orb.Point{values[0], values[1]}) {
+		mergedCenter = center
+	}
+
+	return mergedBounds, mergedCenter, true
+}
+
+// headerBound bound of header bbox in nanodegrees
+func headerBound(bbox *proto.HeaderBBox) orb.Bound {
+	return orb.Bound{
+		Min: orb.Point{float64(bbox.GetLeft()) / 1e9, float64(bbox.GetBottom()) / 1e9},
+		Max: orb.Point{float64(bbox.GetRight()) / 1e9, float64(bbox.GetTop()) / 1e9},
+	}
+}
+
+// clampBound limit bound to the area of web mercator tiles
+func clampBound(bound orb.Bound) orb.Bound {
+	clamp := func(p orb.Point) orb.Point {
+		return orb.Point{
+			max(-maxMercatorLon, min(maxMercatorLon, p.Lon())),
+			max(-maxMercatorLat, min(maxMercatorLat, p.Lat())),
+		}
+	}
+
+	return orb.Bound{Min: clamp(bound.Min), Max: clamp(bound.Max)}
+}
+
+// fitZoom the highest zoom of tileset showing the whole bound on a viewport
+// of two 512px tiles in width and height
+func fitZoom(bound orb.Bound) int {
+	topLeft := maptile.Fraction(orb.Point{bound.Min.Lon(), bound.Max.Lat()}, 0)
+	bottomRight := maptile.Fraction(orb.Point{bound.Max.Lon(), bound.Min.Lat()}, 0)
+
+	span := max(bottomRight.X()-topLeft.X(), bottomRight.Y()-topLeft.Y())
+	if span <= 0 {
+		return maxZoom
+	}
+
+	zoom := int(math.Floor(math.Log2(2 / span)))
+
+	return max(minZoom, min(maxZoom, zoom))
+}
+
+// tilePoint coordinates of point projected to tile with extent
+func tilePoint(tile maptile.Tile, p orb.Point, extent float64) orb.Point {
+	n := float64(uint32(1) << tile.Z)
+	x := (float64(tile.X) + p.X()/extent) / n
+	y := (float64(tile.Y) + p.Y()/extent) / n
+
+	lon := x*360 - 180
+	lat := math.Atan(math.Sinh(math.Pi*(1-2*y))) * 180 / math.Pi
+
+	return orb.Point{lon, lat}
+}
@@ -0,0 +1,56 @@
+package mbt
+
+import (
+	"math"
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/maptile"
+)
+
+func TestFitZoom(t *testing.T) {
+	tests := []struct {
+		name  string
+		bound orb.Bound
+		want  int
+	}{
+		{name: "world", bound: orb.Bound{Min: orb.Point{-180, -85}, Max: orb.Point{180, 85}}, want: 1},
+		{name: "country", bound: orb.Bound{Min: orb.Point{1.41, 42.43}, Max: orb.Point{1.79, 42.66}}, want: 10},
+		{name: "point", bound: orb.Bound{Min: orb.Point{1.5, 42.5}, Max: orb.Point{1.5, 42.5}}, want: maxZoom},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fitZoom(tt.bound); got != tt.want {
+				t.Errorf("fitZoom() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTilePoint(t *testing.T) {
+	tile := maptile.At(orb.Point{1.5, 42.5}, maxZoom)
+	bound := tile.Bound()
+
+	topLeft := tilePoint(tile, orb.Point{0, 0}, 4096)
+	bottomRight := tilePoint(tile, orb.Point{4096, 4096}, 4096)
+
+	if math.Abs(topLeft.Lon()-bound.Min.Lon()) > 1e-9 || math.Abs(topLeft.Lat()-bound.Max.Lat()) > 1e-9 {
+		t.Errorf("tilePoint() top left = %v, want %v", topLeft, orb.Point{bound.Min.Lon(), bound.Max.Lat()})
+	}
+	if math.Abs(bottomRight.Lon()-bound.Max.Lon()) > 1e-9 || math.Abs(bottomRight.Lat()-bound.Min.Lat()) > 1e-9 {
+		t.Errorf("tilePoint() bottom right = %v, want %v", bottomRight, orb.Point{bound.Max.Lon(), bound.Min.Lat()})
+	}
+}
+
+func TestBoundsCollector_metadata(t *testing.T) {
+	header := orb.Bound{Min: orb.Point{-180, -90}, Max: orb.Point{180, 90}}
+
+	bounds, _, ok := (&boundsCollector{}).metadata(&header)
+	if !ok || bounds != "-180.000000,-85.051129,180.000000,85.051129" {
+		t.Errorf("metadata() bounds = %q, %v, want bounds of header limited to web mercator", bounds, ok)
+	}
+
+	if _, _, ok = (&boundsCollector{}).metadata(nil); ok {
+		t.Errorf("metadata() without features and header = %v, want false", ok)
+	}
+}
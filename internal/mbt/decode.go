@@ -0,0 +1,148 @@
+package mbt
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+
+	"github.com/paulmach/orb/encoding/mvt"
+	"github.com/paulmach/orb/geojson"
+	"github.com/paulmach/orb/maptile"
+)
+
+const maxSampleValues = 5
+
+// LayerSummary Feature counts and attributes of decoded tile layer
+type LayerSummary struct {
+	Name         string             `json:"name"`
+	Version      uint32             `json:"version"`
+	Extent       uint32             `json:"extent"`
+	FeatureCount int                `json:"feature_count"`
+	Geometries   map[string]int     `json:"geometries"`
+	Attributes   []AttributeSummary `json:"attributes"`
+}
+
+// AttributeSummary Occurrences, value types and sample values of attribute
+type AttributeSummary struct {
+	Name   string   `json:"name"`
+	Count  int      `json:"count"`
+	Types  []string `json:"types"`
+	Values []string `json:"values"`
+}
+
+// DecodeTile decompress tile data and decode mvt layers in tile coordinates
+func DecodeTile(data []byte) (mvt.Layers, error) {
+	switch DetectCompression(data) {
+	case CompressionGzip:
+		return mvt.UnmarshalGzipped(data)
+	case CompressionZlib:
+		r, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+
+		raw, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return mvt.Unmarshal(raw)
+	default:
+		return mvt.Unmarshal(data)
+	}
+}
+
+// DecodeTileWGS84 decode mvt layers of tile with geometry projected from tile
+// coordinates to wgs84
+func DecodeTileWGS84(data []byte, tile maptile.Tile) (mvt.Layers, error) {
+	layers, err := DecodeTile(data)
+	if err != nil {
+		return nil, err
+	}
+	layers.ProjectToWGS84(tile)
+
+	return layers, nil
+}
+
+// SummarizeLayers count features, geometry types and attributes of every layer
+func SummarizeLayers(layers mvt.Layers) []LayerSummary {
+	summaries := make([]LayerSummary, 0, len(layers))
+
+	for _, layer := range layers {
+		summary := LayerSummary{
+			Name:         layer.Name,
+			Version:      layer.Version,
+			Extent:       layer.Extent,
+			FeatureCount: len(layer.Features),
+			Geometries:   make(map[string]int),
+			Attributes:   summarizeAttributes(layer.Features),
+		}
+
+		for _, feature := range layer.Features {
+			if feature.Geometry != nil {
+				summary.Geometries[feature.Geometry.GeoJSONType()]++
+			}
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	return summaries
+}
+
+func summarizeAttributes(features []*geojson.Feature) []AttributeSummary {
+	byName := make(map[string]*AttributeSummary)
+	seen := make(map[string]map[string]bool)
+
+	for _, feature := range features {
+		for name, value := range feature.Properties {
+			attr, ok := byName[name]
+			if !ok {
+				attr = &AttributeSummary{Name: name}
+				byName[name] = attr
+				seen[name] = make(map[string]bool)
+			}
+			attr.Count++
+
+			valueType := attributeType(value)
+			if !slices.Contains(attr.Types, valueType) {
+				attr.Types = append(attr.Types, valueType)
+			}
+
+			sample := fmt.Sprint(value)
+			if len(attr.Values) < maxSampleValues && !seen[name][sample] {
+				seen[name][sample] = true
+				attr.Values = append(attr.Values, sample)
+			}
+		}
+	}
+
+	attributes := make([]AttributeSummary, 0, len(byName))
+	for _, attr := range byName {
+		sort.Strings(attr.Types)
+		attributes = append(attributes, *attr)
+	}
+
+	sort.Slice(attributes, func(i, j int) bool {
+		return attributes[i].Name < attributes[j].Name
+	})
+
+	return attributes
+}
+
+func attributeType(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float32, float64, int, int64, uint64:
+		return "number"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
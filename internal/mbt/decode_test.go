@@ -0,0 +1,126 @@
+package mbt
+
+import (
+	"bytes"
+	"compress/zlib"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/encoding/mvt"
+	"github.com/paulmach/orb/geojson"
+	"github.com/paulmach/orb/maptile"
+)
+
+// testTileData tile with cafe of Andorra la Vella in the points layer
+func testTileData(t *testing.T, tile maptile.Tile, compression string) []byte {
+	t.Helper()
+
+	feature := geojson.NewFeature(orb.Point{1.5218, 42.5063})
+	feature.Properties = map[string]interface{}{"amenity": "cafe", "ele": 1023}
+	layers := mvt.Layers{{Name: "points", Version: 2, Extent: 4096, Features: []*geojson.Feature{feature}}}
+	layers.ProjectToTile(tile)
+
+	var data []byte
+	var err error
+	switch compression {
+	case CompressionGzip:
+		data, err = mvt.MarshalGzipped(layers)
+	case CompressionZlib:
+		if data, err = mvt.Marshal(layers); err == nil {
+			var buffer bytes.Buffer
+			w := zlib.NewWriter(&buffer)
+			_, _ = w.Write(data)
+			err = w.Close()
+			data = buffer.Bytes()
+		}
+	default:
+		data, err = mvt.Marshal(layers)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func TestDecodeTile(t *testing.T) {
+	tile := maptile.At(orb.Point{1.5218, 42.5063}, 14)
+	// Point in tile coordinates of extent 4096
+	want := orb.Point{1060, 3641}
+
+	for _, compression := range []string{CompressionNone, CompressionGzip, CompressionZlib} {
+		t.Run(compression, func(t *testing.T) {
+			data := testTileData(t, tile, compression)
+
+			layers, err := DecodeTile(data)
+			if err != nil {
+				t.Fatalf("DecodeTile() error = %v", err)
+			}
+			if len(layers) != 1 || len(layers[0].Features) != 1 {
+				t.Fatalf("DecodeTile() = %v, want one layer with one feature", layers)
+			}
+			if got := layers[0].Features[0].Geometry; got != want {
+				t.Errorf("DecodeTile() point = %v, want %v", got, want)
+			}
+
+			layers, err = DecodeTileWGS84(data, tile)
+			if err != nil {
+				t.Fatalf("DecodeTileWGS84() error = %v", err)
+			}
+			point := layers[0].Features[0].Geometry.(orb.Point)
+			// Precision of tile coordinates is 1/4096 of tile
+			if math.Abs(point.Lon()-1.5218) > 1e-5 || math.Abs(point.Lat()-42.5063) > 1e-5 {
+				t.Errorf("DecodeTileWGS84() point = %v, want %v", point, orb.Point{1.5218, 42.5063})
+			}
+		})
+	}
+}
+
+func TestDecodeTile_invalid(t *testing.T) {
+	for name, data := range map[string][]byte{"gzip": {0x1f, 0x8b, 1}, "zlib": {0x78, 0x9c, 1}, "mvt": {0x1a, 10, 1}} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := DecodeTile(data); err == nil {
+				t.Errorf("DecodeTile() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestSummarizeLayers(t *testing.T) {
+	feature := func(geometry orb.Geometry, properties map[string]interface{}) *geojson.Feature {
+		f := geojson.NewFeature(geometry)
+		f.Properties = properties
+		return f
+	}
+
+	layers := mvt.Layers{
+		{Name: "points", Version: 2, Extent: 4096, Features: []*geojson.Feature{
+			feature(orb.Point{1, 1}, map[string]interface{}{"amenity": "cafe", "ele": 1200.5}),
+			feature(orb.Point{1, 2}, map[string]interface{}{"amenity": "bar", "open": true}),
+			feature(orb.MultiPoint{{1, 3}}, map[string]interface{}{"amenity": "cafe", "open": "yes"}),
+		}},
+		{Name: "empty", Version: 2, Extent: 512},
+	}
+
+	want := []LayerSummary{
+		{
+			Name:         "points",
+			Version:      2,
+			Extent:       4096,
+			FeatureCount: 3,
+			Geometries:   map[string]int{"Point": 2, "MultiPoint": 1},
+			Attributes: []AttributeSummary{
+				{Name: "amenity", Count: 3, Types: []string{"string"}, Values: []string{"cafe", "bar"}},
+				{Name: "ele", Count: 1, Types: []string{"number"}, Values: []string{"1200.5"}},
+				{Name: "open", Count: 2, Types: []string{"boolean", "string"}, Values: []string{"true", "yes"}},
+			},
+		},
+		{Name: "empty", Version: 2, Extent: 512, Geometries: map[string]int{}, Attributes: []AttributeSummary{}},
+	}
+
+	if got := SummarizeLayers(layers); !reflect.DeepEqual(got, want) {
+		t.Errorf("SummarizeLayers() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,154 @@
+package mbt
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/paulmach/orb/geojson"
+	"github.com/paulmach/orb/maptile"
+)
+
+// Metadata keys of feature id options set for convert, written only when they are not default
+const (
+	FeatureIDEncodingKey   = "feature_id_encoding"
+	FeatureIDPropertiesKey = "feature_id_properties"
+)
+
+var ErrInvalidIDEncoding = errors.New("invalid feature id encoding")
+
+// IDEncoding Encoding of osm id and type to mvt feature id
+type IDEncoding string
+
+var (
+	// IDTypeCode osm id * 10 + type code: 1 node, 2 way, 3 relation
+	IDTypeCode IDEncoding = "type-code"
+	// IDOSM osm id only, ids of nodes and ways can be the same
+	IDOSM IDEncoding = "osm"
+	// IDNone features without id
+	IDNone IDEncoding = "none"
+)
+
+// Type codes of osm elements in feature ids
+const (
+	nodeTypeCode     = 1
+	wayTypeCode      = 2
+	relationTypeCode = 3
+)
+
+// elementTypes Names of osm element types by type code
+var elementTypes = map[int64]string{
+	nodeTypeCode:     "node",
+	wayTypeCode:      "way",
+	relationTypeCode: "relation",
+}
+
+// ParseIDEncoding parse name of feature id encoding
+func ParseIDEncoding(value string) (IDEncoding, error) {
+	switch encoding := IDEncoding(value); encoding {
+	case IDTypeCode, IDOSM, IDNone:
+		return encoding, nil
+	default:
+		return "", fmt.Errorf("%w: %q, use type-code, osm or none", ErrInvalidIDEncoding, value)
+	}
+}
+
+// SetFeatureIDs set encoding of feature ids, with properties id and type of
+// osm element are written to properties too, options are saved to metadata
+func (m *MBT) SetFeatureIDs(encoding IDEncoding, properties bool) {
+	m.idEncoding = encoding
+	m.idProperties = properties
+}
+
+// loadFeatureIDs feature id options of existing tileset
+func (m *MBT) loadFeatureIDs() error {
+	m.idEncoding = IDTypeCode
+
+	var encoding string
+	err := m.db.QueryRow("SELECT value FROM metadata WHERE name = ?", FeatureIDEncodingKey).Scan(&encoding)
+	switch {
+	case err == nil:
+		if m.idEncoding, err = ParseIDEncoding(encoding); err != nil {
+			return err
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		return err
+	}
+
+	var properties string
+	err = m.db.QueryRow("SELECT value FROM metadata WHERE name = ?", FeatureIDPropertiesKey).Scan(&properties)
+	switch {
+	case err == nil:
+		m.idProperties = properties == "true"
+	case !errors.Is(err, sql.ErrNoRows):
+		return err
+	}
+
+	return nil
+}
+
+// featureMetadata metadata of feature id options which are not default
+func (m *MBT) featureMetadata(fields map[string]string) {
+	if m.idEncoding != IDTypeCode {
+		fields[FeatureIDEncodingKey] = string(m.idEncoding)
+	}
+	if m.idProperties {
+		fields[FeatureIDPropertiesKey] = "true"
+	}
+}
+
+// typeCodeID id of osm element unique across types, used as key of features before encoding
+func typeCodeID(id int64, typeCode int64) int64 {
+	return id*10 + typeCode
+}
+
+// encodeFeatureID mvt id of feature by encoding, negative ids of new elements are not written
+func (m *MBT) encodeFeatureID(key int64) interface{} {
+	switch m.idEncoding {
+	case IDNone:
+		return nil
+	case IDOSM:
+		key /= 10
+	}
+
+	if key < 0 {
+		return nil
+	}
+
+	return uint64(key)
+}
+
+// decodedFeatureKey key of feature decoded from tile of tileset like the key of
+// written feature. Ids are decoded by encoding, features without ids by osm id
+// and type of properties. Water is counted by tile and index like in convert,
+// other features without id by geometry type and attributes
+func (m *MBT) decodedFeatureKey(layer string, feature *geojson.Feature, tile maptile.Tile, index int) interface{} {
+	if layer == oceanLayer {
+		return tileFeature{tile: tile, index: index}
+	}
+
+	if id, ok := toFloat(feature.ID); ok {
+		switch m.idEncoding {
+		case IDTypeCode:
+			return int64(id)
+		case IDOSM:
+			// Only nodes are points, other layers have features of ways
+			if layer == pointsLayer {
+				return typeCodeID(int64(id), nodeTypeCode)
+			}
+			return typeCodeID(int64(id), wayTypeCode)
+		}
+	}
+
+	id, okID := toFloat(feature.Properties["id"])
+	elementType, okType := feature.Properties["type"].(string)
+	if okID && okType {
+		for typeCode, name := range elementTypes {
+			if name == elementType {
+				return typeCodeID(int64(id), typeCode)
+			}
+		}
+	}
+
+	return fmt.Sprint(geometryType(feature.Geometry), feature.Properties)
+}
@@ -0,0 +1,26 @@
+package mbt
+
+import "testing"
+
+func TestMBT_encodeFeatureID(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding IDEncoding
+		key      int64
+		want     interface{}
+	}{
+		{name: "type code node", encoding: IDTypeCode, key: typeCodeID(123, nodeTypeCode), want: uint64(1231)},
+		{name: "type code way", encoding: IDTypeCode, key: typeCodeID(123, wayTypeCode), want: uint64(1232)},
+		{name: "osm", encoding: IDOSM, key: typeCodeID(123, wayTypeCode), want: uint64(123)},
+		{name: "none", encoding: IDNone, key: typeCodeID(123, wayTypeCode), want: nil},
+		{name: "negative id", encoding: IDTypeCode, key: typeCodeID(-5, nodeTypeCode), want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MBT{idEncoding: tt.encoding}
+			if got := m.encodeFeatureID(tt.key); got != tt.want {
+				t.Errorf("encodeFeatureID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package mbt
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// Info Summary of the content of tileset
+type Info struct {
+	File         string            `json:"file"`
+	Metadata     map[string]string `json:"metadata"`
+	VectorLayers []VectorLayer     `json:"vector_layers"`
+	Compression  string            `json:"compression"`
+	TileCount    int               `json:"tile_count"`
+	TotalSize    int64             `json:"total_size"`
+	Zooms        []ZoomStat        `json:"zooms"`
+	LargestTiles []TileSize        `json:"largest_tiles"`
+}
+
+// ZoomStat Count and sizes of tiles on one zoom level
+type ZoomStat struct {
+	Zoom      int   `json:"zoom"`
+	Count     int   `json:"count"`
+	TotalSize int64 `json:"total_size"`
+	AvgSize   int64 `json:"avg_size"`
+	MaxSize   int64 `json:"max_size"`
+}
+
+// TileSize Size of tile with xyz coordinates
+type TileSize struct {
+	Zoom int   `json:"z"`
+	X    int   `json:"x"`
+	Y    int   `json:"y"`
+	Size int64 `json:"size"`
+}
+
+// Info collect metadata, layers and tile statistics of tileset
+func (t *Tileset) Info(largest int) (*Info, error) {
+	metadata, err := t.Metadata()
+	if err != nil {
+		return nil, err
+	}
+
+	vectorLayers, err := ParseVectorLayers(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	zooms, err := t.ZoomStats()
+	if err != nil {
+		return nil, err
+	}
+
+	largestTiles, err := t.LargestTiles(largest)
+	if err != nil {
+		return nil, err
+	}
+
+	compression, err := t.Compression()
+	if err != nil {
+		return nil, err
+	}
+
+	info := &Info{
+		File:         t.File,
+		Metadata:     metadata,
+		VectorLayers: vectorLayers,
+		Compression:  compression,
+		Zooms:        zooms,
+		LargestTiles: largestTiles,
+	}
+
+	for _, zoom := range zooms {
+		info.TileCount += zoom.Count
+		info.TotalSize += zoom.TotalSize
+	}
+
+	return info, nil
+}
+
+// ZoomStats return tile count and sizes grouped by zoom level
+func (t *Tileset) ZoomStats() ([]ZoomStat, error) {
+	rows, err := t.db.Query(`
+		SELECT zoom_level, COUNT(*), COALESCE(SUM(LENGTH(tile_data)), 0), COALESCE(MAX(LENGTH(tile_data)), 0)
+		FROM tiles
+		GROUP BY zoom_level
+		ORDER BY zoom_level
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	zooms := make([]ZoomStat, 0)
+	for rows.Next() {
+		var zoom ZoomStat
+		if err = rows.Scan(&zoom.Zoom, &zoom.Count, &zoom.TotalSize, &zoom.MaxSize); err != nil {
+			return nil, err
+		}
+
+		if zoom.Count > 0 {
+			zoom.AvgSize = zoom.TotalSize / int64(zoom.Count)
+		}
+		zooms = append(zooms, zoom)
+	}
+
+	return zooms, rows.Err()
+}
+
+// LargestTiles return the largest tiles with xyz coordinates
+func (t *Tileset) LargestTiles(limit int) ([]TileSize, error) {
+	rows, err := t.db.Query(`
+		SELECT zoom_level, tile_column, tile_row, COALESCE(LENGTH(tile_data), 0) AS size
+		FROM tiles
+		ORDER BY size DESC, zoom_level, tile_column, tile_row
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tiles := make([]TileSize, 0)
+	for rows.Next() {
+		var tile TileSize
+		if err = rows.Scan(&tile.Zoom, &tile.X, &tile.Y, &tile.Size); err != nil {
+			return nil, err
+		}
+
+		tile.Y = FlipY(tile.Zoom, tile.Y)
+		tiles = append(tiles, tile)
+	}
+
+	return tiles, rows.Err()
+}
+
+// Compression detect compression by magic bytes of all tiles, mixed if tiles
+// are compressed differently and empty if tileset has no tiles
+func (t *Tileset) Compression() (string, error) {
+	rows, err := t.db.Query("SELECT DISTINCT SUBSTR(tile_data, 1, 2) FROM tiles")
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	compression := ""
+	for rows.Next() {
+		var magic []byte
+		if err = rows.Scan(&magic); err != nil {
+			return "", err
+		}
+
+		detected := DetectCompression(magic)
+		if compression != "" && detected != compression {
+			compression = CompressionMixed
+			break
+		}
+		compression = detected
+	}
+
+	return compression, rows.Err()
+}
+
+// ParseVectorLayers parse vector_layers from the json row of metadata
+func ParseVectorLayers(metadata map[string]string) ([]VectorLayer, error) {
+	content := struct {
+		VectorLayers []VectorLayer `json:"vector_layers"`
+	}{}
+
+	if raw := metadata["json"]; raw != "" {
+		if err := json.Unmarshal([]byte(raw), &content); err != nil {
+			return nil, err
+		}
+	}
+
+	if content.VectorLayers == nil {
+		return make([]VectorLayer, 0), nil
+	}
+
+	sort.Slice(content.VectorLayers, func(i, j int) bool {
+		return content.VectorLayers[i].ID < content.VectorLayers[j].ID
+	})
+
+	return content.VectorLayers, nil
+}
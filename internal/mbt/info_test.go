@@ -0,0 +1,93 @@
+package mbt
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+// memoryTileset tileset in memory with tiles of tms rows, tile data nil are NULL
+func memoryTileset(t *testing.T, tiles []TileSize, data [][]byte) *Tileset {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every connection has its own database in memory
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	if _, err = db.Exec(schema); err != nil {
+		t.Fatal(err)
+	}
+	for i, tile := range tiles {
+		_, err = db.Exec("INSERT INTO tiles VALUES (?, ?, ?, ?)", tile.Zoom, tile.X, tile.Y, data[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return &Tileset{File: ":memory:", db: db}
+}
+
+func TestTileset_Info(t *testing.T) {
+	gzip := []byte{0x1f, 0x8b, 8, 0}
+	zlib := []byte{0x78, 0x9c, 1}
+	raw := []byte{0x1a, 2, 3, 4, 5}
+	tiles := []TileSize{{Zoom: 0}, {Zoom: 1, X: 1, Y: 1}, {Zoom: 1}}
+
+	tests := []struct {
+		name        string
+		tiles       []TileSize
+		data        [][]byte
+		compression string
+		zooms       []ZoomStat
+		largest     []TileSize
+	}{
+		{name: "empty", zooms: []ZoomStat{}, largest: []TileSize{}},
+		{
+			name:        "gzip",
+			tiles:       tiles,
+			data:        [][]byte{gzip, gzip, append(gzip, 0)},
+			compression: CompressionGzip,
+			zooms:       []ZoomStat{{Zoom: 0, Count: 1, TotalSize: 4, AvgSize: 4, MaxSize: 4}, {Zoom: 1, Count: 2, TotalSize: 9, AvgSize: 4, MaxSize: 5}},
+			largest:     []TileSize{{Zoom: 1, X: 0, Y: 1, Size: 5}, {Zoom: 0, Size: 4}},
+		},
+		{
+			name:        "mixed",
+			tiles:       tiles,
+			data:        [][]byte{raw, zlib, gzip},
+			compression: CompressionMixed,
+			zooms:       []ZoomStat{{Zoom: 0, Count: 1, TotalSize: 5, AvgSize: 5, MaxSize: 5}, {Zoom: 1, Count: 2, TotalSize: 7, AvgSize: 3, MaxSize: 4}},
+			largest:     []TileSize{{Zoom: 0, Size: 5}, {Zoom: 1, X: 0, Y: 1, Size: 4}},
+		},
+		{
+			name:        "null data",
+			tiles:       tiles,
+			data:        [][]byte{nil, nil, nil},
+			compression: CompressionNone,
+			zooms:       []ZoomStat{{Zoom: 0, Count: 1}, {Zoom: 1, Count: 2}},
+			largest:     []TileSize{{Zoom: 0}, {Zoom: 1, X: 0, Y: 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := memoryTileset(t, tt.tiles, tt.data).Info(2)
+			if err != nil {
+				t.Fatalf("Info() error = %v", err)
+			}
+			if info.Compression != tt.compression {
+				t.Errorf("Info() compression = %q, want %q", info.Compression, tt.compression)
+			}
+			if !reflect.DeepEqual(info.Zooms, tt.zooms) {
+				t.Errorf("Info() zooms = %v, want %v", info.Zooms, tt.zooms)
+			}
+			if !reflect.DeepEqual(info.LargestTiles, tt.largest) {
+				t.Errorf("Info() largest tiles = %v, want %v", info.LargestTiles, tt.largest)
+			}
+		})
+	}
+}
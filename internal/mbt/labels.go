@@ -0,0 +1,154 @@
+package mbt
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/planar"
+	"github.com/paulmach/orb/project"
+)
+
+// LabelOptionsKey Metadata key of label options set for convert, written only when they are not default
+const LabelOptionsKey = "label_options"
+
+var ErrInvalidLabelPlacement = errors.New("invalid label placement")
+
+// LabelPlacement Position of label point of area
+type LabelPlacement string
+
+var (
+	// LabelPolylabel pole of inaccessibility, the inner point most distant from the outline
+	LabelPolylabel LabelPlacement = "polylabel"
+	// LabelCentroid centroid of area, pole of inaccessibility if centroid is outside
+	LabelCentroid LabelPlacement = "centroid"
+	// LabelNone areas without label points
+	LabelNone LabelPlacement = "none"
+)
+
+// mercatorTileSize Size of 256px tile on zoom 0 in meters of web mercator
+const mercatorTileSize = 2 * math.Pi * 6378137
+
+// LabelOptions Options of label points of areas
+type LabelOptions struct {
+	Placement LabelPlacement `json:"placement"`
+	// Precision precision of pole of inaccessibility in meters
+	Precision float64 `json:"precision"`
+	// MinArea the smallest area of polygon with label in square pixels of 256px tiles on zoom of tile
+	MinArea float64 `json:"min_area"`
+}
+
+// DefaultLabelOptions Label options of convert without flags
+var DefaultLabelOptions = LabelOptions{
+	Placement: LabelPolylabel,
+	Precision: 10,
+	MinArea:   64,
+}
+
+// ParseLabelPlacement parse name of label placement
+func ParseLabelPlacement(value string) (LabelPlacement, error) {
+	switch placement := LabelPlacement(value); placement {
+	case LabelPolylabel, LabelCentroid, LabelNone:
+		return placement, nil
+	default:
+		return "", fmt.Errorf("%w: %q, use polylabel, centroid or none", ErrInvalidLabelPlacement, value)
+	}
+}
+
+// SetLabels set placement of label points of areas, options are saved to metadata
+func (m *MBT) SetLabels(options LabelOptions) {
+	m.labels = options
+}
+
+// loadLabels label options of existing tileset
+func (m *MBT) loadLabels() error {
+	m.labels = DefaultLabelOptions
+
+	var value string
+	err := m.db.QueryRow("SELECT value FROM metadata WHERE name = ?", LabelOptionsKey).Scan(&value)
+	switch {
+	case err == nil:
+		return json.Unmarshal([]byte(value), &m.labels)
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	default:
+		return err
+	}
+}
+
+// labelMetadata metadata of label options which are not default
+func (m *MBT) labelMetadata(fields map[string]string) error {
+	if m.labels == DefaultLabelOptions {
+		return nil
+	}
+
+	value, err := json.Marshal(m.labels)
+	if err != nil {
+		return err
+	}
+	fields[LabelOptionsKey] = string(value)
+
+	return nil
+}
+
+// placeLabel find label point and area of area way in web mercator,
+// ways which are not areas have no label
+func (m *MBT) placeLabel(way *WayData) {
+	way.Label = nil
+	way.Area = 0
+
+	if m.labels.Placement == LabelNone || !way.polygonal {
+		return
+	}
+
+	polygon := way.polygon()
+	for i, point := range polygon[0] {
+		polygon[0][i] = project.WGS84.ToMercator(point)
+	}
+
+	way.Area = math.Abs(planar.Area(polygon))
+	if way.Area == 0 {
+		return
+	}
+
+	var label orb.Point
+	centroid, _ := planar.CentroidArea(polygon)
+	if m.labels.Placement == LabelCentroid && planar.PolygonContains(polygon, centroid) {
+		label = centroid
+	} else {
+		label = polylabel(polygon, m.labels.Precision)
+	}
+
+	label = project.Mercator.ToWGS84(label)
+	way.Label = &PointData{ID: way.ID, Lat: label.Lat(), Lon: label.Lon()}
+}
+
+// labelVisible area is large enough on zoom for label
+func (m *MBT) labelVisible(way *WayData, zoom int) bool {
+	pixel := mercatorTileSize / (256 * math.Pow(2, float64(zoom)))
+
+	return way.Area/(pixel*pixel) >= m.labels.MinArea
+}
+
+func (m *MBT) findLabelsInTile(bounds struct{ MinLat, MaxLat, MinLon, MaxLon float64 }, zoom int) []*WayData {
+	var labels []*WayData
+
+	for _, way := range m.waysCache {
+		label := way.Label
+		if label == nil || !m.labelVisible(way, zoom) {
+			continue
+		}
+
+		if label.Lat >= bounds.MinLat && label.Lat <= bounds.MaxLat &&
+			label.Lon >= bounds.MinLon && label.Lon <= bounds.MaxLon {
+			labels = append(labels, way)
+		}
+	}
+
+	sortWays(labels)
+
+	return labels
+}
@@ -0,0 +1,117 @@
+package mbt
+
+import (
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/planar"
+)
+
+func TestWayData_isArea(t *testing.T) {
+	closed := []int64{1, 2, 3, 1}
+
+	tests := []struct {
+		name string
+		refs []int64
+		tags map[string]string
+		want bool
+	}{
+		{name: "building", refs: closed, tags: map[string]string{"building": "yes"}, want: true},
+		{name: "open way", refs: []int64{1, 2, 3, 4}, tags: map[string]string{"building": "yes"}},
+		{name: "roundabout", refs: closed, tags: map[string]string{"highway": "primary"}},
+		{name: "pedestrian area", refs: closed, tags: map[string]string{"highway": "pedestrian", "area": "yes"}, want: true},
+		{name: "area no", refs: closed, tags: map[string]string{"leisure": "track", "area": "no"}},
+		{name: "natural wood", refs: closed, tags: map[string]string{"natural": "wood"}, want: true},
+		{name: "coastline", refs: closed, tags: map[string]string{"natural": "coastline"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			way := &WayData{Refs: tt.refs, Tags: tt.tags}
+			if got := way.isArea(); got != tt.want {
+				t.Errorf("isArea() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolylabel(t *testing.T) {
+	// L shape, centroid is outside of the polygon
+	polygon := orb.Polygon{{{0, 0}, {10, 0}, {10, 2}, {2, 2}, {2, 10}, {0, 10}, {0, 0}}}
+
+	centroid, _ := planar.CentroidArea(polygon)
+	if planar.PolygonContains(polygon, centroid) {
+		t.Fatalf("centroid %v of test polygon is inside", centroid)
+	}
+
+	label := polylabel(polygon, 0.1)
+	if !planar.PolygonContains(polygon, label) {
+		t.Errorf("polylabel() = %v is outside of polygon", label)
+	}
+	if distance := polygonDistance(label, polygon); distance < 0.9 {
+		t.Errorf("polylabel() = %v with distance %v to outline, want about 1", label, distance)
+	}
+}
+
+func TestMBT_labelVisible(t *testing.T) {
+	m := &MBT{labels: DefaultLabelOptions}
+	// 200 x 200 m is about 441 square pixels on zoom 14 and 27 on zoom 12
+	way := &WayData{Area: 200 * 200}
+
+	if !m.labelVisible(way, 14) {
+		t.Errorf("labelVisible() on zoom 14 = false, want true")
+	}
+	if m.labelVisible(way, 12) {
+		t.Errorf("labelVisible() on zoom 12 = true, want false")
+	}
+}
+
+func TestCleanRing(t *testing.T) {
+	ring := orb.Ring{{0, 0}, {10.2, 0}, {10, 0.1}, {10, 10}, {12, 12}, {10, 10}, {0, 10}, {0, 0}}
+	want := orb.Ring{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}
+
+	if got := cleanRing(ring); !got.Equal(want) {
+		t.Errorf("cleanRing() = %v, want %v", got, want)
+	}
+}
+
+func TestCleanPolygon(t *testing.T) {
+	tests := []struct {
+		name    string
+		polygon orb.Polygon
+		want    int
+	}{
+		{
+			name:    "simple",
+			polygon: orb.Polygon{{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}},
+			want:    1,
+		},
+		{
+			name:    "touching itself",
+			polygon: orb.Polygon{{{0, 0}, {2, 0}, {2, 2}, {4, 2}, {4, 4}, {2, 4}, {2, 2}, {0, 2}, {0, 0}}},
+			want:    2,
+		},
+		{
+			name:    "point on segment",
+			polygon: orb.Polygon{{{0, 0}, {10, 0}, {10, 10}, {5, 0}, {0, 10}, {0, 0}}},
+			want:    2,
+		},
+		{
+			name:    "crossing itself",
+			polygon: orb.Polygon{{{0, 0}, {10, 0}, {10, 10}, {4, -1}, {0, 10}, {0, 0}}},
+			want:    2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cleanPolygon(tt.polygon)
+			if len(got) != tt.want {
+				t.Fatalf("cleanPolygon() = %v, want %d polygons", got, tt.want)
+			}
+			for _, polygon := range got {
+				if !isSimple(polygon[0]) {
+					t.Errorf("cleanPolygon() ring %v is not simple", polygon[0])
+				}
+			}
+		})
+	}
+}
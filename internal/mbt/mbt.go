@@ -2,9 +2,12 @@ package mbt
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log"
 	"math"
+	"sort"
+	"strconv"
 
 	_ "github.com/mattn/go-sqlite3"
 
@@ -13,6 +16,7 @@ import (
 	"github.com/paulmach/orb/geojson"
 	"github.com/paulmach/orb/maptile"
 	"github.com/paulmach/orb/simplify"
+	"github.com/your-map/mbtiles-tool/internal/osm"
 	"github.com/your-map/mbtiles-tool/internal/osm/proto"
 )
 
@@ -29,58 +33,161 @@ type WayData struct {
 	Refs  []int64
 	Tags  map[string]string
 	Nodes []*PointData
+	// Locations of nodes from the way itself, only for files with LocationsOnWays
+	Locations []*PointData
+	// Label label point of area and Area its area in square meters of web mercator
+	Label *PointData
+	Area  float64
+
+	// Way is area and bound of its nodes, they are set with geometry of way
+	polygonal bool
+	box       orb.Bound
+}
+
+const (
+	minZoom = 0
+	maxZoom = 14
+)
+
+// minPolygonArea Area of one pixel of 256px tile in units of tile extent,
+// smaller polygons are not visible and get self intersections when simplified
+const minPolygonArea = 16 * 16
+
+// Names of layers written to tiles
+const (
+	pointsLayer   = "points"
+	linesLayer    = "lines"
+	polygonsLayer = "polygons"
+	labelsLayer   = "labels"
+	oceanLayer    = "ocean"
+)
+
+// layerDescriptions Descriptions of layers in vector_layers
+var layerDescriptions = map[string]string{
+	pointsLayer:   "OSM nodes",
+	linesLayer:    "OSM ways",
+	polygonsLayer: "OSM closed ways of areas",
+	labelsLayer:   "Label points of areas",
+	oceanLayer:    "Sea water from coastlines or water polygons",
+}
+
+// isPolygonLayer layer of polygons which are clipped to tile and cleaned
+func isPolygonLayer(name string) bool {
+	return name == polygonsLayer || name == oceanLayer
 }
 
 type MBT struct {
-	db           *sql.DB
-	layerStats   map[string]*LayerStat
-	vectorLayers map[string]*VectorLayer
-	fieldTypes   map[string]map[string]string
+	db    *sql.DB
+	stats *statsCollector
+
+	// Types of attributes of features, customTypes are types set for convert
+	attributeTypes map[string]AttributeType
+	customTypes    map[string]AttributeType
+
+	// Bounds and center of written features, metadata set for convert
+	// and bbox of header replace them
+	bounds     *boundsCollector
+	metadata   Metadata
+	headerBBox *orb.Bound
+
+	// Placement of label points of areas
+	labels LabelOptions
+
+	// Layers where features with equal attributes are merged
+	merge MergeOptions
+
+	// Source of ocean polygons and water polygons inside of data, water of
+	// tiles of the last two zooms is kept for clipping of child tiles
+	ocean       OceanOptions
+	water       orb.MultiPolygon
+	waterTiles  map[maptile.Tile]orb.MultiPolygon
+	parentWater map[maptile.Tile]orb.MultiPolygon
+	waterZoom   int
+	oceanBuilt  bool
+	oceanStale  bool
+
+	// Languages of name attributes, raw name tags are written without languages
+	languages     []Language
+	transliterate bool
+
+	// Encoding of feature ids and writing of osm id and type to properties
+	idEncoding   IDEncoding
+	idProperties bool
 
 	// Кэши для данных OSM
 	nodesCache map[int64]*PointData
-	waysCache  []*WayData
-	allPoints  []*PointData
+	waysCache  map[int64]*WayData
+
+	// Ways have locations of nodes, nodes without tags are not cached
+	locationsOnWays bool
+
+	// Ways by id of node and bounds of changed data, filled only for updates
+	nodeWays map[int64][]int64
+	dirty    []orb.Bound
+	// Changed vertices without tags, used only with locations on ways
+	vertices map[int64]*PointData
 }
 
-func NewMBT() (*MBT, error) {
-	db, err := sql.Open("sqlite3", "test.mbtiles")
+// NewMBT open mbtiles file for writing, the file is created if not exists
+func NewMBT(file string) (*MBT, error) {
+	db, err := sql.Open("sqlite3", dsn(file, "rwc"))
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS metadata (
-			name  text,
-			value text
-		);
-		
-		CREATE TABLE IF NOT EXISTS tiles (
-			zoom_level  integer,
-			tile_column integer,
-			tile_row    integer,
-			tile_data   blob
-		);
-		
-		CREATE UNIQUE INDEX IF NOT EXISTS tile_index 
-		ON tiles (zoom_level, tile_column, tile_row);
-	`)
+	_, err = db.Exec(schema)
 	if err != nil {
 		return nil, err
 	}
 
-	return &MBT{
-		db:           db,
-		layerStats:   make(map[string]*LayerStat),
-		vectorLayers: make(map[string]*VectorLayer),
-		fieldTypes:   make(map[string]map[string]string),
-		nodesCache:   make(map[int64]*PointData),
-		waysCache:    make([]*WayData, 0),
-		allPoints:    make([]*PointData, 0),
-	}, nil
+	m := &MBT{
+		db:         db,
+		stats:      newStatsCollector(),
+		bounds:     &boundsCollector{},
+		nodesCache: make(map[int64]*PointData),
+		waysCache:  make(map[int64]*WayData),
+	}
+
+	if err = m.loadAttributeTypes(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	if err = m.loadFeatureIDs(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	if err = m.loadLanguages(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	if err = m.loadLabels(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	if err = m.loadMerge(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	if err = m.loadOcean(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// ReadFeatures use optional features of header for reading of next blocks
+func (m *MBT) ReadFeatures(header *proto.HeaderBlock) {
+	m.locationsOnWays = osm.HasOptionalFeature(header, osm.FeatureLocationsOnWays)
 }
 
-func (m *MBT) WriteMetaData(metaData *proto.HeaderBlock) error {
+// WriteMetaData write metadata of tileset from header, non-empty fields of info replace default values
+func (m *MBT) WriteMetaData(metaData *proto.HeaderBlock, info Metadata) error {
 	stmt, err := m.db.Prepare("INSERT OR REPLACE INTO metadata (name, value) VALUES (?, ?)")
 	if err != nil {
 		return err
@@ -88,18 +195,21 @@ func (m *MBT) WriteMetaData(metaData *proto.HeaderBlock) error {
 	defer stmt.Close()
 
 	metadataFields := map[string]string{
-		"name":    "OSM Data",
-		"version": "1.3",
-		"format":  "pbf",
-		"type":    "overlay",
-		"minzoom": "0",
-		"maxzoom": "14",
+		NameKey:    "OSM Data",
+		VersionKey: "1.3",
+		"format":   "pbf",
+		TypeKey:    TypeOverlay,
+		"minzoom":  strconv.Itoa(minZoom),
+		"maxzoom":  strconv.Itoa(maxZoom),
 	}
 
-	if len(metaData.RequiredFeatures) > 0 {
-		metadataFields["name"] = metaData.RequiredFeatures[0]
+	m.metadata = info
+	for name, value := range info.fields() {
+		metadataFields[name] = value
 	}
 
+	m.ReadFeatures(metaData)
+
 	if metaData.Writingprogram != nil {
 		metadataFields["writingprogram"] = *metaData.Writingprogram
 	}
@@ -108,12 +218,41 @@ func (m *MBT) WriteMetaData(metaData *proto.HeaderBlock) error {
 		metadataFields["source"] = *metaData.Source
 	}
 
-	if metaData.Bbox != nil {
-		grid := NewGrid(metaData.Bbox)
-		bounds, center := grid.Execute()
+	if metaData.OsmosisReplicationTimestamp != nil {
+		metadataFields[ReplicationTimestampKey] = formatReplicationTimestamp(metaData.GetOsmosisReplicationTimestamp())
+	}
+
+	if metaData.OsmosisReplicationSequenceNumber != nil {
+		metadataFields[ReplicationSequenceKey] = strconv.FormatInt(metaData.GetOsmosisReplicationSequenceNumber(), 10)
+	}
+
+	if metaData.OsmosisReplicationBaseUrl != nil {
+		metadataFields[ReplicationBaseURLKey] = metaData.GetOsmosisReplicationBaseUrl()
+	}
+
+	if len(m.customTypes) > 0 {
+		types, err := json.Marshal(m.customTypes)
+		if err != nil {
+			return err
+		}
+		metadataFields[AttributeTypesKey] = string(types)
+	}
+
+	m.featureMetadata(metadataFields)
+	m.languageMetadata(metadataFields)
+	if err = m.labelMetadata(metadataFields); err != nil {
+		return err
+	}
+	if err = m.mergeMetadata(metadataFields); err != nil {
+		return err
+	}
+	if err = m.oceanMetadata(metadataFields); err != nil {
+		return err
+	}
 
-		metadataFields["bounds"] = bounds
-		metadataFields["center"] = center
+	if metaData.Bbox != nil {
+		bound := headerBound(metaData.Bbox)
+		m.headerBBox = &bound
 	}
 
 	for name, value := range metadataFields {
@@ -133,47 +272,50 @@ func (m *MBT) WriteBlockData(data *proto.PrimitiveBlock) error {
 	for _, group := range data.GetPrimitivegroup() {
 		// Обрабатываем обычные nodes
 		for _, node := range group.GetNodes() {
-			m.analyzePrimitive("nodes", node.GetKeys(), node.GetVals(), stringTable, "Point")
-
 			point := m.processNode(node, data, stringTable)
-			m.nodesCache[point.ID] = point
-			m.allPoints = append(m.allPoints, point)
+			m.cacheNode(point)
 		}
 
 		// Обрабатываем dense nodes
 		if dense := group.GetDense(); dense != nil {
-			m.analyzeDenseNodes(dense, stringTable)
-
 			densePoints := m.processDenseNodes(dense, data, stringTable)
 			for _, point := range densePoints {
-				m.nodesCache[point.ID] = point
-				m.allPoints = append(m.allPoints, point)
+				m.cacheNode(point)
 			}
 		}
 
 		// Обрабатываем ways
 		for _, way := range group.GetWays() {
-			m.analyzePrimitive("ways", way.GetKeys(), way.GetVals(), stringTable, "LineString")
-
-			wayData := m.processWay(way, stringTable)
-			m.waysCache = append(m.waysCache, wayData)
-		}
-
-		for _, relation := range group.GetRelations() {
-			m.analyzePrimitive("relations", relation.GetKeys(), relation.GetVals(), stringTable, "GeometryCollection")
+			wayData := m.processWay(way, data, stringTable)
+			m.waysCache[wayData.ID] = wayData
 		}
 	}
 
 	return nil
 }
 
+// cacheNode cache node for points and geometry of ways, with locations on ways
+// nodes without tags are only vertices of ways and are not needed
+func (m *MBT) cacheNode(point *PointData) {
+	if m.locationsOnWays && len(point.Tags) == 0 {
+		return
+	}
+
+	m.nodesCache[point.ID] = point
+}
+
 // После обработки всех блоков вызываем генерацию тайлов
 func (m *MBT) GenerateTiles() error {
 	// Восстанавливаем геометрию для ways
 	m.reconstructWayGeometry()
 
+	// Полигоны воды из береговых линий или файла
+	if err := m.buildOcean(); err != nil {
+		return err
+	}
+
 	// Генерируем тайлы для разных уровней масштабирования
-	for zoom := 0; zoom <= 14; zoom++ {
+	for zoom := minZoom; zoom <= maxZoom; zoom++ {
 		log.Printf("Generating tiles for zoom %d", zoom)
 		err := m.generateTilesForZoom(zoom)
 		if err != nil {
@@ -191,28 +333,25 @@ func (m *MBT) generateTilesForZoom(zoom int) error {
 	}
 	defer stmt.Close()
 
-	totalTiles := 1 << zoom // 2^zoom
+	bound, ok := m.dataBound()
+	if !ok {
+		return nil
+	}
 
-	for x := 0; x < totalTiles; x++ {
-		for y := 0; y < totalTiles; y++ {
-			tileBounds := m.getTileBounds(x, y, zoom)
+	// Перебираем только тайлы, покрывающие данные
+	minX, minY, maxX, maxY := tileRange(bound, zoom)
 
-			// Находим объекты в bounding box тайла
-			pointsInTile := m.findPointsInTile(tileBounds)
-			waysInTile := m.findWaysInTile(tileBounds)
+	for x := minX; x <= maxX; x++ {
+		for y := minY; y <= maxY; y++ {
+			tileData, err := m.renderTile(zoom, x, y)
+			if err != nil {
+				return err
+			}
 
-			// Создаем MVT тайл только если есть данные
-			if len(pointsInTile) > 0 || len(waysInTile) > 0 {
-				tileData, err := m.createMVTForTile(pointsInTile, waysInTile, zoom, x, y)
+			if len(tileData) > 0 {
+				_, err = stmt.Exec(zoom, x, FlipY(zoom, y), tileData)
 				if err != nil {
-					return fmt.Errorf("failed to create MVT for tile %d/%d/%d: %w", zoom, x, y, err)
-				}
-
-				if len(tileData) > 0 {
-					_, err = stmt.Exec(zoom, x, y, tileData)
-					if err != nil {
-						return fmt.Errorf("failed to save tile %d/%d/%d: %w", zoom, x, y, err)
-					}
+					return fmt.Errorf("failed to save tile %d/%d/%d: %w", zoom, x, y, err)
 				}
 			}
 		}
@@ -221,56 +360,89 @@ func (m *MBT) generateTilesForZoom(zoom int) error {
 	return nil
 }
 
+// renderTile encode tile by xyz coordinates, empty data means tile without features
+func (m *MBT) renderTile(zoom, x, y int) ([]byte, error) {
+	tileBounds := m.getTileBounds(x, y, zoom)
+
+	// Находим объекты в bounding box тайла
+	pointsInTile := m.findPointsInTile(tileBounds)
+	waysInTile := m.findWaysInTile(tileBounds)
+	labelsInTile := m.findLabelsInTile(tileBounds, zoom)
+	waterInTile := m.waterInTile(zoom, x, y)
+
+	// Создаем MVT тайл только если есть данные
+	if len(pointsInTile) == 0 && len(waysInTile) == 0 && len(labelsInTile) == 0 && len(waterInTile) == 0 {
+		return []byte{}, nil
+	}
+
+	tileData, err := m.createMVTForTile(pointsInTile, waysInTile, labelsInTile, waterInTile, zoom, x, y)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MVT for tile %d/%d/%d: %w", zoom, x, y, err)
+	}
+
+	return tileData, nil
+}
+
 // Основной метод создания MVT тайла
-func (m *MBT) createMVTForTile(points []*PointData, ways []*WayData, zoom, x, y int) ([]byte, error) {
+func (m *MBT) createMVTForTile(points []*PointData, ways []*WayData, labels []*WayData, water orb.MultiPolygon, zoom, x, y int) ([]byte, error) {
 	// Создаем тайл
 	tile := maptile.New(uint32(x), uint32(y), maptile.Zoom(zoom))
 
 	// Создаем FeatureCollection для точек
 	pointFeatures := make([]*geojson.Feature, 0)
 	for _, point := range points {
-		feature := geojson.NewFeature(orb.Point{point.Lon, point.Lat})
-		feature.Properties = make(map[string]interface{})
-		feature.Properties["id"] = point.ID
-		feature.Properties["type"] = "node"
-		for k, v := range point.Tags {
-			feature.Properties[k] = v
-		}
+		feature := m.newFeature(orb.Point{point.Lon, point.Lat}, point.ID, nodeTypeCode, point.Tags)
 		pointFeatures = append(pointFeatures, feature)
 	}
 
-	// Создаем FeatureCollection для линий
+	// Создаем FeatureCollection для линий и полигонов
 	lineFeatures := make([]*geojson.Feature, 0)
+	polygonFeatures := make([]*geojson.Feature, 0)
 	for _, way := range ways {
-		if len(way.Nodes) >= 2 {
+		switch {
+		case way.polygonal:
+			polygonFeatures = append(polygonFeatures, m.newFeature(way.polygon(), way.ID, wayTypeCode, way.Tags))
+		case len(way.Nodes) >= 2:
 			lineString := make(orb.LineString, len(way.Nodes))
 			for i, node := range way.Nodes {
 				lineString[i] = orb.Point{node.Lon, node.Lat}
 			}
-
-			feature := geojson.NewFeature(lineString)
-			feature.Properties = make(map[string]interface{})
-			feature.Properties["id"] = way.ID
-			feature.Properties["type"] = "way"
-			for k, v := range way.Tags {
-				feature.Properties[k] = v
-			}
-			lineFeatures = append(lineFeatures, feature)
+			lineFeatures = append(lineFeatures, m.newFeature(lineString, way.ID, wayTypeCode, way.Tags))
 		}
 	}
 
+	// Объединяем объекты с одинаковыми атрибутами
+	if m.merge.Lines {
+		lineFeatures = m.mergeLines(lineFeatures)
+	}
+	if m.merge.Polygons && zoom <= m.merge.PolygonsMaxZoom {
+		polygonFeatures = m.mergePolygons(polygonFeatures)
+	}
+
+	// Точки подписей площадных объектов
+	labelFeatures := make([]*geojson.Feature, 0, len(labels))
+	for _, way := range labels {
+		feature := m.newFeature(orb.Point{way.Label.Lon, way.Label.Lat}, way.ID, wayTypeCode, way.Tags)
+		labelFeatures = append(labelFeatures, feature)
+	}
+
 	// Создаем слои MVT
 	layers := make([]*mvt.Layer, 0)
 
+	// Слой воды под остальными слоями
+	if len(water) > 0 {
+		oceanCollection := &geojson.FeatureCollection{
+			Features: waterFeatures(water),
+		}
+		layers = append(layers, mvt.NewLayer(oceanLayer, oceanCollection))
+	}
+
 	// Слой точек
 	if len(pointFeatures) > 0 {
 		pointCollection := &geojson.FeatureCollection{
 			Features: pointFeatures,
 		}
-		pointLayer := &mvt.Layer{
-			Name:     "points",
-			Features: pointCollection.Features,
-		}
+		pointLayer := mvt.NewLayer(pointsLayer, pointCollection)
 		layers = append(layers, pointLayer)
 	}
 
@@ -279,13 +451,26 @@ func (m *MBT) createMVTForTile(points []*PointData, ways []*WayData, zoom, x, y
 		lineCollection := &geojson.FeatureCollection{
 			Features: lineFeatures,
 		}
-		lineLayer := &mvt.Layer{
-			Name:     "lines",
-			Features: lineCollection.Features,
-		}
+		lineLayer := mvt.NewLayer(linesLayer, lineCollection)
 		layers = append(layers, lineLayer)
 	}
 
+	// Слой полигонов
+	if len(polygonFeatures) > 0 {
+		polygonCollection := &geojson.FeatureCollection{
+			Features: polygonFeatures,
+		}
+		layers = append(layers, mvt.NewLayer(polygonsLayer, polygonCollection))
+	}
+
+	// Слой подписей
+	if len(labelFeatures) > 0 {
+		labelCollection := &geojson.FeatureCollection{
+			Features: labelFeatures,
+		}
+		layers = append(layers, mvt.NewLayer(labelsLayer, labelCollection))
+	}
+
 	if len(layers) == 0 {
 		return []byte{}, nil
 	}
@@ -293,8 +478,29 @@ func (m *MBT) createMVTForTile(points []*PointData, ways []*WayData, zoom, x, y
 	// Проецируем и упрощаем геометрию для тайла
 	for _, layer := range layers {
 		layer.ProjectToTile(tile)
+		if isPolygonLayer(layer.Name) {
+			// Areas may cover the tile without nodes in it
+			layer.Clip(mvt.MapboxGLDefaultExtentBound)
+		}
 		layer.Simplify(simplify.DouglasPeucker(1.0))
-		layer.RemoveEmpty(1.0, 1.0)
+		layer.RemoveEmpty(1.0, minPolygonArea)
+		if isPolygonLayer(layer.Name) {
+			cleanPolygons(layer)
+			orientPolygons(layer)
+		}
+		m.stats.add(layer, tile)
+
+		// Features are counted by type code ids, ids of tile use encoding of tileset,
+		// water polygons are not osm elements and have no ids
+		for _, feature := range layer.Features {
+			if id, ok := feature.ID.(int64); ok {
+				feature.ID = m.encodeFeatureID(id)
+			}
+		}
+	}
+
+	if zoom == maxZoom {
+		m.bounds.add(layers, tile)
 	}
 
 	// Кодируем в MVT
@@ -306,6 +512,20 @@ func (m *MBT) createMVTForTile(points []*PointData, ways []*WayData, zoom, x, y
 	return dataMvt, nil
 }
 
+// newFeature feature of osm element, id is unique across types of elements
+func (m *MBT) newFeature(geometry orb.Geometry, id, typeCode int64, tags map[string]string) *geojson.Feature {
+	feature := geojson.NewFeature(geometry)
+	feature.ID = typeCodeID(id, typeCode)
+	feature.Properties = make(map[string]interface{})
+	if m.idProperties {
+		feature.Properties["id"] = id
+		feature.Properties["type"] = elementTypes[typeCode]
+	}
+	m.setTags(feature.Properties, tags)
+
+	return feature
+}
+
 // Вспомогательные методы
 func (m *MBT) processNode(node *proto.Node, block *proto.PrimitiveBlock, stringTable [][]byte) *PointData {
 	lat, lon := m.decodeCoordinates(node.GetLat(), node.GetLon(), block)
@@ -367,24 +587,57 @@ func (m *MBT) processDenseNodes(dense *proto.DenseNodes, block *proto.PrimitiveB
 	return points
 }
 
-func (m *MBT) processWay(way *proto.Way, stringTable [][]byte) *WayData {
-	return &WayData{
+func (m *MBT) processWay(way *proto.Way, block *proto.PrimitiveBlock, stringTable [][]byte) *WayData {
+	// Ссылки на nodes закодированы дельтами
+	refs := make([]int64, len(way.GetRefs()))
+	var ref int64
+	for i, delta := range way.GetRefs() {
+		ref += delta
+		refs[i] = ref
+	}
+
+	wayData := &WayData{
 		ID:   way.GetId(),
-		Refs: way.GetRefs(),
+		Refs: refs,
 		Tags: m.extractTags(way.GetKeys(), way.GetVals(), stringTable),
 	}
+
+	// Координаты nodes тоже закодированы дельтами
+	if m.locationsOnWays && len(way.GetLat()) == len(refs) && len(way.GetLon()) == len(refs) {
+		wayData.Locations = make([]*PointData, len(refs))
+		var lat, lon int64
+		for i := range refs {
+			lat += way.GetLat()[i]
+			lon += way.GetLon()[i]
+			nodeLat, nodeLon := m.decodeCoordinates(lat, lon, block)
+			wayData.Locations[i] = &PointData{ID: refs[i], Lat: nodeLat, Lon: nodeLon}
+		}
+	}
+
+	return wayData
 }
 
 func (m *MBT) reconstructWayGeometry() {
 	for _, way := range m.waysCache {
-		var nodes []*PointData
-		for _, ref := range way.Refs {
-			if node, exists := m.nodesCache[ref]; exists {
-				nodes = append(nodes, node)
-			}
+		m.reconstructWay(way)
+	}
+}
+
+// reconstructWay geometry of way from cached nodes, location of the way is used
+// for nodes without cache, so changed nodes replace locations of the pbf file
+func (m *MBT) reconstructWay(way *WayData) {
+	var nodes []*PointData
+	for i, ref := range way.Refs {
+		if node, exists := m.nodesCache[ref]; exists {
+			nodes = append(nodes, node)
+		} else if i < len(way.Locations) && way.Locations[i] != nil {
+			nodes = append(nodes, way.Locations[i])
 		}
-		way.Nodes = nodes
 	}
+	way.Nodes = nodes
+	way.polygonal = way.isArea() && len(nodes) >= 4
+	way.box, _ = way.bound()
+	m.placeLabel(way)
 }
 
 func (m *MBT) decodeCoordinates(lat, lon int64, block *proto.PrimitiveBlock) (float64, float64) {
@@ -404,8 +657,9 @@ func (m *MBT) getTileBounds(x, y, zoom int) struct{ MinLat, MaxLat, MinLon, MaxL
 	minLon := float64(x)/n*360.0 - 180.0
 	maxLon := float64(x+1)/n*360.0 - 180.0
 
-	minLat := math.Atan(math.Sinh(math.Pi*(1-2*float64(y)/n))) * 180.0 / math.Pi
-	maxLat := math.Atan(math.Sinh(math.Pi*(1-2*float64(y+1)/n))) * 180.0 / math.Pi
+	// Строки тайлов идут с севера на юг, нижняя граница у строки y+1
+	minLat := math.Atan(math.Sinh(math.Pi*(1-2*float64(y+1)/n))) * 180.0 / math.Pi
+	maxLat := math.Atan(math.Sinh(math.Pi*(1-2*float64(y)/n))) * 180.0 / math.Pi
 
 	return struct{ MinLat, MaxLat, MinLon, MaxLon float64 }{
 		MinLat: minLat,
@@ -418,20 +672,35 @@ func (m *MBT) getTileBounds(x, y, zoom int) struct{ MinLat, MaxLat, MinLon, MaxL
 func (m *MBT) findPointsInTile(bounds struct{ MinLat, MaxLat, MinLon, MaxLon float64 }) []*PointData {
 	var points []*PointData
 
-	for _, point := range m.allPoints {
+	for _, point := range m.nodesCache {
 		if point.Lat >= bounds.MinLat && point.Lat <= bounds.MaxLat &&
 			point.Lon >= bounds.MinLon && point.Lon <= bounds.MaxLon {
 			points = append(points, point)
 		}
 	}
 
+	// Порядок не зависит от обхода map, тайл кодируется одинаково
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].ID < points[j].ID
+	})
+
 	return points
 }
 
 func (m *MBT) findWaysInTile(bounds struct{ MinLat, MaxLat, MinLon, MaxLon float64 }) []*WayData {
 	var ways []*WayData
 
+	tile := orb.Bound{Min: orb.Point{bounds.MinLon, bounds.MinLat}, Max: orb.Point{bounds.MaxLon, bounds.MaxLat}}
+
 	for _, way := range m.waysCache {
+		// Площади могут покрывать тайл без своих точек в нем
+		if way.polygonal {
+			if way.box.Intersects(tile) {
+				ways = append(ways, way)
+			}
+			continue
+		}
+
 		// Простая проверка - если хотя бы одна точка way попадает в тайл
 		for _, node := range way.Nodes {
 			if node.Lat >= bounds.MinLat && node.Lat <= bounds.MaxLat &&
@@ -442,9 +711,18 @@ func (m *MBT) findWaysInTile(bounds struct{ MinLat, MaxLat, MinLon, MaxLon float
 		}
 	}
 
+	sortWays(ways)
+
 	return ways
 }
 
+// sortWays order ways by id, tile is encoded the same for the same data
+func sortWays(ways []*WayData) {
+	sort.Slice(ways, func(i, j int) bool {
+		return ways[i].ID < ways[j].ID
+	})
+}
+
 func (m *MBT) extractTags(keys, vals []uint32, stringTable [][]byte) map[string]string {
 	tags := make(map[string]string)
 	for i := 0; i < len(keys) && i < len(vals); i++ {
@@ -0,0 +1,64 @@
+package mbt
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/your-map/mbtiles-tool/internal/osm"
+	"github.com/your-map/mbtiles-tool/internal/osm/proto"
+)
+
+// locationsOnWaysMBT tileset with residential way 1 of untagged nodes 1 and 2
+// at 42.5,1.5 and 42.501,1.501, the block has locations on ways
+func locationsOnWaysMBT(t *testing.T) *MBT {
+	t.Helper()
+
+	m, err := NewMBT(filepath.Join(t.TempDir(), "low.mbtiles"))
+	if err != nil {
+		t.Fatalf("NewMBT() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = m.Close()
+	})
+
+	m.ReadFeatures(&proto.HeaderBlock{OptionalFeatures: []string{osm.FeatureLocationsOnWays}})
+
+	id := int64(1)
+	block := &proto.PrimitiveBlock{
+		Stringtable: &proto.StringTable{S: [][]byte{{}, []byte("highway"), []byte("residential")}},
+		Primitivegroup: []*proto.PrimitiveGroup{{
+			Ways: []*proto.Way{{
+				Id:   &id,
+				Keys: []uint32{1},
+				Vals: []uint32{2},
+				Refs: []int64{1, 1},
+				Lat:  []int64{425000000, 10000},
+				Lon:  []int64{15000000, 10000},
+			}},
+		}},
+	}
+	if err = m.WriteBlockData(block); err != nil {
+		t.Fatalf("WriteBlockData() error = %v", err)
+	}
+
+	return m
+}
+
+func TestMBT_GenerateTiles_locationsOnWays(t *testing.T) {
+	m := locationsOnWaysMBT(t)
+	if len(m.nodesCache) != 0 {
+		t.Fatalf("nodesCache has %d nodes, want vertices of ways not cached", len(m.nodesCache))
+	}
+
+	if err := m.GenerateTiles(); err != nil {
+		t.Fatalf("GenerateTiles() error = %v", err)
+	}
+
+	var zooms int
+	if err := m.db.QueryRow("SELECT COUNT(DISTINCT zoom_level) FROM tiles").Scan(&zooms); err != nil {
+		t.Fatal(err)
+	}
+	if zooms != maxZoom-minZoom+1 {
+		t.Errorf("tiles on %d zooms, want way on all %d zooms", zooms, maxZoom-minZoom+1)
+	}
+}
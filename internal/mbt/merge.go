@@ -0,0 +1,554 @@
+package mbt
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+	"github.com/paulmach/orb/planar"
+)
+
+// MergeOptionsKey Metadata key of merge options set for convert, written only when merge is enabled
+const MergeOptionsKey = "merge_options"
+
+var ErrInvalidMergeLayer = errors.New("invalid merge layer")
+
+// MergeOptions Layers where features with equal attributes are merged in each tile
+type MergeOptions struct {
+	// Lines join lines sharing end points
+	Lines bool `json:"lines"`
+	// Polygons join polygons sharing edges on zooms up to PolygonsMaxZoom
+	Polygons        bool `json:"polygons"`
+	PolygonsMaxZoom int  `json:"polygons_max_zoom"`
+}
+
+// DefaultMergeMaxZoom The highest zoom of merged polygons, on higher zooms
+// polygons stay separate for interaction with osm elements
+const DefaultMergeMaxZoom = 12
+
+// ParseMergeLayers merge options of layer names, lines and polygons can be merged
+func ParseMergeLayers(layers []string, polygonsMaxZoom int) (MergeOptions, error) {
+	options := MergeOptions{PolygonsMaxZoom: polygonsMaxZoom}
+
+	for _, layer := range layers {
+		switch layer {
+		case linesLayer:
+			options.Lines = true
+		case polygonsLayer:
+			options.Polygons = true
+		default:
+			return MergeOptions{}, fmt.Errorf("%w: %q, use lines or polygons", ErrInvalidMergeLayer, layer)
+		}
+	}
+
+	return options, nil
+}
+
+// SetMerge set layers with merged features, options are saved to metadata
+func (m *MBT) SetMerge(options MergeOptions) {
+	m.merge = options
+}
+
+// loadMerge merge options of existing tileset
+func (m *MBT) loadMerge() error {
+	var value string
+	err := m.db.QueryRow("SELECT value FROM metadata WHERE name = ?", MergeOptionsKey).Scan(&value)
+	switch {
+	case err == nil:
+		return json.Unmarshal([]byte(value), &m.merge)
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	default:
+		return err
+	}
+}
+
+// mergeMetadata metadata of merge options if any layer is merged
+func (m *MBT) mergeMetadata(fields map[string]string) error {
+	if !m.merge.Lines && !m.merge.Polygons {
+		return nil
+	}
+
+	value, err := json.Marshal(m.merge)
+	if err != nil {
+		return err
+	}
+	fields[MergeOptionsKey] = string(value)
+
+	return nil
+}
+
+// propertiesKey key of properties of feature, features with the same key have equal
+// attributes, osm id and type of id properties are not compared
+func (m *MBT) propertiesKey(feature *geojson.Feature) string {
+	keys := make([]string, 0, len(feature.Properties))
+	for key := range feature.Properties {
+		if m.idProperties && (key == "id" || key == "type") {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var builder strings.Builder
+	for _, key := range keys {
+		value := feature.Properties[key]
+		_, _ = fmt.Fprintf(&builder, "%s=%T:%v\x00", key, value, value)
+	}
+
+	return builder.String()
+}
+
+// groupFeatures features by equal properties in order of the first feature of group
+func (m *MBT) groupFeatures(features []*geojson.Feature) [][]*geojson.Feature {
+	index := make(map[string]int)
+	groups := make([][]*geojson.Feature, 0)
+
+	for _, feature := range features {
+		key := m.propertiesKey(feature)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], feature)
+	}
+
+	return groups
+}
+
+// mergeLines join lines with equal properties through end points shared by
+// exactly two of them, oneway lines are not reversed, merged line keeps id
+// and properties of its first line
+func (m *MBT) mergeLines(features []*geojson.Feature) []*geojson.Feature {
+	result := make([]*geojson.Feature, 0, len(features))
+
+	for _, group := range m.groupFeatures(features) {
+		if len(group) == 1 {
+			result = append(result, group...)
+			continue
+		}
+
+		reversible := !isOneway(group[0].Properties["oneway"])
+		for _, line := range joinLines(group, reversible) {
+			result = append(result, line)
+		}
+	}
+
+	return result
+}
+
+func isOneway(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != "no" && v != "false" && v != "0"
+	default:
+		return true
+	}
+}
+
+func joinLines(features []*geojson.Feature, reversible bool) []*geojson.Feature {
+	lines := make([]orb.LineString, len(features))
+	for i, feature := range features {
+		lines[i] = feature.Geometry.(orb.LineString)
+	}
+
+	joined, first := joinLineStrings(lines, reversible)
+	result := make([]*geojson.Feature, len(joined))
+	for i, line := range joined {
+		result[i] = geojson.NewFeature(line)
+		result[i].ID = features[first[i]].ID
+		result[i].Properties = features[first[i]].Properties
+	}
+
+	return result
+}
+
+// joinLineStrings join lines through end points shared by exactly two lines,
+// lines are reversed only if reversible, index of the first joined line is
+// returned for each result line
+func joinLineStrings(lines []orb.LineString, reversible bool) ([]orb.LineString, []int) {
+	ends := make(map[orb.Point][]int)
+	for i, line := range lines {
+		ends[line[0]] = append(ends[line[0]], i)
+		ends[line[len(line)-1]] = append(ends[line[len(line)-1]], i)
+	}
+
+	used := make([]bool, len(lines))
+
+	// next the other unused line at point shared by two lines
+	next := func(point orb.Point, current int) (int, bool) {
+		at := ends[point]
+		if len(at) != 2 {
+			return 0, false
+		}
+
+		other := at[0]
+		if other == current {
+			other = at[1]
+		}
+
+		return other, other != current && !used[other]
+	}
+
+	result := make([]orb.LineString, 0, len(lines))
+	first := make([]int, 0, len(lines))
+	for i := range lines {
+		if used[i] {
+			continue
+		}
+		used[i] = true
+		line := append(orb.LineString{}, lines[i]...)
+
+		// Forward from the end of line
+		for current := i; ; {
+			j, ok := next(line[len(line)-1], current)
+			if !ok {
+				break
+			}
+
+			switch {
+			case lines[j][0] == line[len(line)-1]:
+				line = append(line, lines[j][1:]...)
+			case reversible:
+				line = append(line, reversed(lines[j])[1:]...)
+			default:
+				j = -1
+			}
+			if j < 0 {
+				break
+			}
+			used[j] = true
+			current = j
+		}
+
+		// Backward from the start of line
+		for current := i; ; {
+			j, ok := next(line[0], current)
+			if !ok {
+				break
+			}
+
+			switch {
+			case lines[j][len(lines[j])-1] == line[0]:
+				line = append(append(orb.LineString{}, lines[j][:len(lines[j])-1]...), line...)
+			case reversible:
+				line = append(reversed(lines[j])[:len(lines[j])-1], line...)
+			default:
+				j = -1
+			}
+			if j < 0 {
+				break
+			}
+			used[j] = true
+			current = j
+		}
+
+		result = append(result, line)
+		first = append(first, i)
+	}
+
+	return result, first
+}
+
+func reversed(line orb.LineString) orb.LineString {
+	result := make(orb.LineString, len(line))
+	for i, point := range line {
+		result[len(line)-1-i] = point
+	}
+
+	return result
+}
+
+// edge Directed edge of ring
+type edge struct {
+	from, to orb.Point
+}
+
+// mergePolygons join polygons with equal properties which share edges, shared
+// edges are removed and the rest is assembled to rings, merged polygon keeps
+// id and properties of its first polygon
+func (m *MBT) mergePolygons(features []*geojson.Feature) []*geojson.Feature {
+	result := make([]*geojson.Feature, 0, len(features))
+
+	for _, group := range m.groupFeatures(features) {
+		if len(group) == 1 {
+			result = append(result, group...)
+			continue
+		}
+
+		for _, component := range adjacentPolygons(group) {
+			if len(component) > 1 {
+				if geometry, ok := unionPolygons(component); ok {
+					merged := geojson.NewFeature(geometry)
+					merged.ID = component[0].ID
+					merged.Properties = component[0].Properties
+					result = append(result, merged)
+					continue
+				}
+			}
+			result = append(result, component...)
+		}
+	}
+
+	return result
+}
+
+// nextEdge unused edge after current with the sharpest left turn, polygons touching
+// at a point are split to separate rings instead of a ring crossing itself
+func nextEdge(edges []edge, removed []bool, candidates []int, current edge) int {
+	next := -1
+	best := math.Inf(-1)
+	for _, candidate := range candidates {
+		if removed[candidate] {
+			continue
+		}
+
+		in := orb.Point{current.to[0] - current.from[0], current.to[1] - current.from[1]}
+		out := orb.Point{edges[candidate].to[0] - current.to[0], edges[candidate].to[1] - current.to[1]}
+		turn := math.Atan2(in[0]*out[1]-in[1]*out[0], in[0]*out[0]+in[1]*out[1])
+		if turn > best {
+			next = candidate
+			best = turn
+		}
+	}
+
+	return next
+}
+
+// adjacentPolygons groups of polygons connected by shared edges in order of features
+func adjacentPolygons(features []*geojson.Feature) [][]*geojson.Feature {
+	parent := make([]int, len(features))
+	for i := range parent {
+		parent[i] = i
+	}
+
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	owners := make(map[edge]int)
+	for i, feature := range features {
+		polygon, ok := feature.Geometry.(orb.Polygon)
+		if !ok {
+			continue
+		}
+
+		for _, ring := range polygon {
+			for k := 0; k < len(ring)-1; k++ {
+				// Neighbours share edge in the same or opposite direction
+				e := edge{ring[k], ring[k+1]}
+				if e.to[0] < e.from[0] || (e.to[0] == e.from[0] && e.to[1] < e.from[1]) {
+					e = edge{e.to, e.from}
+				}
+
+				if owner, ok := owners[e]; ok {
+					parent[find(i)] = find(owner)
+				} else {
+					owners[e] = i
+				}
+			}
+		}
+	}
+
+	index := make(map[int]int)
+	components := make([][]*geojson.Feature, 0)
+	for i, feature := range features {
+		root := find(i)
+		c, ok := index[root]
+		if !ok {
+			c = len(components)
+			index[root] = c
+			components = append(components, nil)
+		}
+		components[c] = append(components[c], feature)
+	}
+
+	return components
+}
+
+// unionPolygons union of polygons sharing edges, false if edges of polygons
+// can not be assembled to simple rings, like polygons overlapping or sharing
+// only a part of an edge
+func unionPolygons(features []*geojson.Feature) (orb.Geometry, bool) {
+	edges := make([]edge, 0)
+	index := make(map[edge]int)
+	removed := make([]bool, 0)
+
+	for _, feature := range features {
+		polygon, ok := feature.Geometry.(orb.Polygon)
+		if !ok {
+			return nil, false
+		}
+
+		for r, ring := range polygon {
+			// Exterior rings counterclockwise and holes clockwise, shared edges are opposite
+			ring = append(orb.Ring{}, ring...)
+			if (r == 0) != (ring.Orientation() == orb.CCW) {
+				ring.Reverse()
+			}
+
+			for k := 0; k < len(ring)-1; k++ {
+				e := edge{ring[k], ring[k+1]}
+				if opposite, ok := index[edge{e.to, e.from}]; ok && !removed[opposite] {
+					removed[opposite] = true
+					delete(index, edge{e.to, e.from})
+					continue
+				}
+				if _, ok := index[e]; ok {
+					continue
+				}
+
+				index[e] = len(edges)
+				edges = append(edges, e)
+				removed = append(removed, false)
+			}
+		}
+	}
+
+	outgoing := make(map[orb.Point][]int)
+	for i, e := range edges {
+		if !removed[i] {
+			outgoing[e.from] = append(outgoing[e.from], i)
+		}
+	}
+
+	var exteriors, holes []orb.Ring
+	for i, e := range edges {
+		if removed[i] {
+			continue
+		}
+
+		ring := orb.Ring{e.from}
+		removed[i] = true
+		current := e
+		for current.to != ring[0] {
+			ring = append(ring, current.to)
+
+			next := nextEdge(edges, removed, outgoing[current.to], current)
+			if next < 0 {
+				return nil, false
+			}
+			removed[next] = true
+			current = edges[next]
+		}
+		ring = append(ring, ring[0])
+
+		if len(ring) < 4 {
+			continue
+		}
+		if !isSimple(ring) {
+			return nil, false
+		}
+		if ring.Orientation() == orb.CCW {
+			exteriors = append(exteriors, ring)
+		} else {
+			holes = append(holes, ring)
+		}
+	}
+
+	if len(exteriors) == 0 {
+		return nil, false
+	}
+
+	polygons := make(orb.MultiPolygon, len(exteriors))
+	for i, exterior := range exteriors {
+		polygons[i] = orb.Polygon{exterior}
+	}
+
+	for _, hole := range holes {
+		for i := range polygons {
+			if planar.RingContains(polygons[i][0], hole[0]) {
+				polygons[i] = append(polygons[i], hole)
+				break
+			}
+		}
+	}
+
+	if len(polygons) == 1 {
+		return polygons[0], true
+	}
+
+	return polygons, true
+}
+
+// isSimple ring without crossing or touching of non adjacent segments
+func isSimple(ring orb.Ring) bool {
+	segments := len(ring) - 1
+	simple := true
+	overlappingSegments(ring, func(i, j int) bool {
+		// Adjacent segments share a point, the first and the last one the closing point
+		if j == i+1 || i == j+1 || (min(i, j) == 0 && max(i, j) == segments-1) {
+			return true
+		}
+
+		simple = !segmentsIntersect(ring[i], ring[i+1], ring[j], ring[j+1])
+		return simple
+	})
+
+	return simple
+}
+
+// overlappingSegments call fn for pairs of segments of ring overlapping by x,
+// segments are sorted by left end, fn returns false to stop
+func overlappingSegments(ring orb.Ring, fn func(i, j int) bool) {
+	order := make([]int, len(ring)-1)
+	for i := range order {
+		order[i] = i
+	}
+	left := func(i int) float64 { return math.Min(ring[i][0], ring[i+1][0]) }
+	sort.Slice(order, func(a, b int) bool { return left(order[a]) < left(order[b]) })
+
+	for a, i := range order {
+		right := math.Max(ring[i][0], ring[i+1][0])
+		for _, j := range order[a+1:] {
+			if left(j) > right {
+				break
+			}
+			if !fn(i, j) {
+				return
+			}
+		}
+	}
+}
+
+// segmentsIntersect segments cross or touch
+func segmentsIntersect(a, b, c, d orb.Point) bool {
+	d1 := cross(c, d, a)
+	d2 := cross(c, d, b)
+	d3 := cross(a, b, c)
+	d4 := cross(a, b, d)
+
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+
+	return (d1 == 0 && onSegment(c, d, a)) ||
+		(d2 == 0 && onSegment(c, d, b)) ||
+		(d3 == 0 && onSegment(a, b, c)) ||
+		(d4 == 0 && onSegment(a, b, d))
+}
+
+func cross(a, b, c orb.Point) float64 {
+	return (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+}
+
+func onSegment(a, b, p orb.Point) bool {
+	return min(a[0], b[0]) <= p[0] && p[0] <= max(a[0], b[0]) &&
+		min(a[1], b[1]) <= p[1] && p[1] <= max(a[1], b[1])
+}
@@ -0,0 +1,127 @@
+package mbt
+
+import (
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+	"github.com/paulmach/orb/planar"
+)
+
+func testFeature(id int64, geometry orb.Geometry, properties map[string]interface{}) *geojson.Feature {
+	feature := geojson.NewFeature(geometry)
+	feature.ID = id
+	feature.Properties = properties
+	return feature
+}
+
+func TestMBT_mergeLines(t *testing.T) {
+	primary := map[string]interface{}{"highway": "primary"}
+	oneway := map[string]interface{}{"highway": "primary", "oneway": true}
+
+	tests := []struct {
+		name     string
+		features []*geojson.Feature
+		want     []orb.LineString
+	}{
+		{
+			name: "chain",
+			features: []*geojson.Feature{
+				testFeature(1, orb.LineString{{1, 0}, {2, 0}}, primary),
+				testFeature(2, orb.LineString{{0, 0}, {1, 0}}, primary),
+				testFeature(3, orb.LineString{{3, 0}, {2, 0}}, primary),
+			},
+			want: []orb.LineString{{{0, 0}, {1, 0}, {2, 0}, {3, 0}}},
+		},
+		{
+			name: "different attributes",
+			features: []*geojson.Feature{
+				testFeature(1, orb.LineString{{0, 0}, {1, 0}}, primary),
+				testFeature(2, orb.LineString{{1, 0}, {2, 0}}, map[string]interface{}{"highway": "secondary"}),
+			},
+			want: []orb.LineString{{{0, 0}, {1, 0}}, {{1, 0}, {2, 0}}},
+		},
+		{
+			name: "junction",
+			features: []*geojson.Feature{
+				testFeature(1, orb.LineString{{0, 0}, {1, 0}}, primary),
+				testFeature(2, orb.LineString{{1, 0}, {2, 0}}, primary),
+				testFeature(3, orb.LineString{{1, 0}, {1, 1}}, primary),
+			},
+			want: []orb.LineString{{{0, 0}, {1, 0}}, {{1, 0}, {2, 0}}, {{1, 0}, {1, 1}}},
+		},
+		{
+			name: "oneway is not reversed",
+			features: []*geojson.Feature{
+				testFeature(1, orb.LineString{{0, 0}, {1, 0}}, oneway),
+				testFeature(2, orb.LineString{{2, 0}, {1, 0}}, oneway),
+				testFeature(3, orb.LineString{{2, 0}, {3, 0}}, oneway),
+			},
+			want: []orb.LineString{{{0, 0}, {1, 0}}, {{2, 0}, {1, 0}}, {{2, 0}, {3, 0}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&MBT{}).mergeLines(tt.features)
+			if len(got) != len(tt.want) {
+				t.Fatalf("mergeLines() = %d features, want %d", len(got), len(tt.want))
+			}
+			for i, feature := range got {
+				if !feature.Geometry.(orb.LineString).Equal(tt.want[i]) {
+					t.Errorf("mergeLines()[%d] = %v, want %v", i, feature.Geometry, tt.want[i])
+				}
+			}
+			if got[0].ID != int64(1) {
+				t.Errorf("mergeLines()[0].ID = %v, want 1", got[0].ID)
+			}
+		})
+	}
+}
+
+func TestMBT_mergePolygons(t *testing.T) {
+	forest := map[string]interface{}{"landuse": "forest"}
+
+	// Two squares sharing an edge and a separate square
+	left := orb.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}}
+	right := orb.Polygon{{{1, 0}, {1, 1}, {2, 1}, {2, 0}, {1, 0}}}
+	far := orb.Polygon{{{5, 5}, {6, 5}, {6, 6}, {5, 6}, {5, 5}}}
+
+	got := (&MBT{}).mergePolygons([]*geojson.Feature{
+		testFeature(1, left, forest),
+		testFeature(2, far, forest),
+		testFeature(3, right, forest),
+	})
+	if len(got) != 2 {
+		t.Fatalf("mergePolygons() = %d features, want 2", len(got))
+	}
+
+	merged, ok := got[0].Geometry.(orb.Polygon)
+	if !ok || len(merged) != 1 {
+		t.Fatalf("mergePolygons()[0] = %v, want polygon without holes", got[0].Geometry)
+	}
+	if area := planar.Area(merged); area != 2 {
+		t.Errorf("area of merged polygon = %v, want 2", area)
+	}
+	if len(merged[0]) != 7 {
+		t.Errorf("merged ring = %v, want 6 points without shared edge", merged[0])
+	}
+	if got[1].ID != int64(2) {
+		t.Errorf("mergePolygons()[1].ID = %v, want 2", got[1].ID)
+	}
+}
+
+func TestMBT_mergePolygons_overlapping(t *testing.T) {
+	forest := map[string]interface{}{"landuse": "forest"}
+
+	// Polygons share an edge but overlap, union of edges crosses itself
+	left := orb.Polygon{{{0, 0}, {2, 0}, {2, 1}, {2, 2}, {0, 2}, {0, 0}}}
+	right := orb.Polygon{{{2, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1.5}, {2, 2}, {2, 1}}}
+
+	got := (&MBT{}).mergePolygons([]*geojson.Feature{
+		testFeature(1, left, forest),
+		testFeature(2, right, forest),
+	})
+	if len(got) != 2 {
+		t.Errorf("mergePolygons() = %d features, want 2 not merged", len(got))
+	}
+}
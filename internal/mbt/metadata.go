@@ -3,10 +3,9 @@ package mbt
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-
-	"github.com/your-map/mbtiles-tool/internal/osm/proto"
 )
 
 type VectorLayer struct {
@@ -31,155 +30,119 @@ type LayerStat struct {
 }
 
 type Attribute struct {
-	Attribute string      `json:"attribute"`
-	Count     int         `json:"count"`
-	Type      string      `json:"type"`
-	Values    interface{} `json:"values,omitempty"`
-	Min       interface{} `json:"min,omitempty"`
-	Max       interface{} `json:"max,omitempty"`
+	Attribute string        `json:"attribute"`
+	Count     int           `json:"count"`
+	Type      string        `json:"type"`
+	Values    []interface{} `json:"values,omitempty"`
+	Min       interface{}   `json:"min,omitempty"`
+	Max       interface{}   `json:"max,omitempty"`
 }
 
-func (m *MBT) analyzePrimitive(layer string, keys, vals []uint32, stringTable [][]byte, geometry string) {
-	if _, exists := m.layerStats[layer]; !exists {
-		m.layerStats[layer] = &LayerStat{
-			Layer:    layer,
-			Geometry: geometry,
-			Count:    0,
-		}
-		m.fieldTypes[layer] = make(map[string]string)
+func (m *MBT) FinalizeMetadata() error {
+	jsonBytes, err := metadataJSON(m.stats)
+	if err != nil {
+		return err
 	}
 
-	m.layerStats[layer].Count++
+	stmt, err := m.db.Prepare("INSERT OR REPLACE INTO metadata (name, value) VALUES (?, ?)")
+	if err != nil {
+		return err
+	}
+	defer func(stmt *sql.Stmt) {
+		err = stmt.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+	}(stmt)
 
-	for i := 0; i < len(keys) && i < len(vals); i++ {
-		if int(keys[i]) < len(stringTable) && int(vals[i]) < len(stringTable) {
-			key := string(stringTable[keys[i]])
-			value := string(stringTable[vals[i]])
+	_, err = stmt.Exec("json", string(jsonBytes))
+	if err != nil {
+		return err
+	}
 
-			m.analyzeField(layer, key, value)
-		}
+	bounds, center, ok := m.bounds.metadata(m.headerBBox)
+	if !ok {
+		return nil
 	}
-}
 
-func (m *MBT) analyzeDenseNodes(dense *proto.DenseNodes, stringTable [][]byte) {
-	layer := "dense_nodes"
-	if _, exists := m.layerStats[layer]; !exists {
-		m.layerStats[layer] = &LayerStat{
-			Layer:    layer,
-			Geometry: "Point",
-			Count:    0,
+	// Computed values are kept for updates, they extend only computed bounds and center
+	if m.metadata.Bounds == "" {
+		for _, name := range []string{BoundsKey, ComputedBoundsKey} {
+			if _, err = stmt.Exec(name, bounds); err != nil {
+				return err
+			}
 		}
-		m.fieldTypes[layer] = make(map[string]string)
 	}
 
-	kvIndex := 0
-	keysVals := dense.GetKeysVals()
-
-	for i := 0; i < len(dense.GetId()); i++ {
-		m.layerStats[layer].Count++
-
-		for kvIndex < len(keysVals) && keysVals[kvIndex] != 0 {
-			keyIdx := keysVals[kvIndex]
-			kvIndex++
-			if kvIndex < len(keysVals) {
-				valIdx := keysVals[kvIndex]
-				kvIndex++
-
-				if int(keyIdx) < len(stringTable) && int(valIdx) < len(stringTable) {
-					key := string(stringTable[keyIdx])
-					value := string(stringTable[valIdx])
-					m.analyzeField(layer, key, value)
-				}
+	if m.metadata.Center == "" {
+		for _, name := range []string{CenterKey, ComputedCenterKey} {
+			if _, err = stmt.Exec(name, center); err != nil {
+				return err
 			}
 		}
-		kvIndex++
 	}
-}
 
-func (m *MBT) analyzeField(layer, key, value string) {
-	fieldType := determineFieldType(value)
-
-	if currentType, exists := m.fieldTypes[layer][key]; !exists {
-		m.fieldTypes[layer][key] = fieldType
-	} else if currentType != fieldType {
-		m.fieldTypes[layer][key] = "string"
-	}
+	return err
 }
 
-func determineFieldType(value string) string {
-	if _, err := fmt.Sscanf(value, "%f"); err == nil {
-		return "number"
-	}
-
-	if value == "true" || value == "false" || value == "yes" || value == "no" {
-		return "boolean"
+// metadataJSON json metadata with vector_layers and tilestats of collected features
+func metadataJSON(stats *statsCollector) ([]byte, error) {
+	jsonData := map[string]interface{}{
+		"vector_layers": stats.vectorLayers(),
+		"tilestats":     stats.tileStats(),
 	}
 
-	return "string"
+	return json.MarshalIndent(jsonData, "", "    ")
 }
 
-func (m *MBT) FinalizeMetadata() error {
-	var vectorLayers []VectorLayer
-	var layerStats []LayerStat
-
-	for layerName, stats := range m.layerStats {
-		vectorLayer := VectorLayer{
-			ID:      layerName,
-			MinZoom: 0,
-			MaxZoom: 14,
-			Fields:  make(map[string]string),
-		}
-
-		for fieldName, fieldType := range m.fieldTypes[layerName] {
-			vectorLayer.Fields[fieldName] = fieldType
+// updateMetadata change json metadata by features of updated tiles. Bounds
+// and center are extended by updated features only if they are still computed,
+// values set by user are kept
+func (m *MBT) updateMetadata(tx *sql.Tx, delta statsDelta) error {
+	names := []interface{}{"json", BoundsKey, CenterKey, ComputedBoundsKey, ComputedCenterKey}
+	rows, err := tx.Query("SELECT name, value FROM metadata WHERE name IN (?, ?, ?, ?, ?)", names...)
+	if err != nil {
+		return err
+	}
+	metadata := make(map[string]string)
+	for rows.Next() {
+		var name, value string
+		if err = rows.Scan(&name, &value); err != nil {
+			_ = rows.Close()
+			return err
 		}
-
-		stats.AttributeCount = len(m.fieldTypes[layerName])
-		stats.Attributes = m.collectAttributeStats(layerName)
-
-		vectorLayers = append(vectorLayers, vectorLayer)
-		layerStats = append(layerStats, *stats)
+		metadata[name] = value
 	}
-
-	jsonData := map[string]interface{}{
-		"vector_layers": vectorLayers,
-		"tilestats": TileStats{
-			LayerCount: len(vectorLayers),
-			Layers:     layerStats,
-		},
+	if err = errors.Join(rows.Err(), rows.Close()); err != nil {
+		return err
 	}
 
-	jsonBytes, err := json.MarshalIndent(jsonData, "", "    ")
+	stats, err := statsFromMetadata(metadata["json"])
 	if err != nil {
-		return err
+		return fmt.Errorf("invalid json metadata: %w", err)
 	}
+	stats.apply(delta)
 
-	stmt, err := m.db.Prepare("INSERT OR REPLACE INTO metadata (name, value) VALUES (?, ?)")
+	jsonBytes, err := metadataJSON(stats)
 	if err != nil {
 		return err
 	}
-	defer func(stmt *sql.Stmt) {
-		err = stmt.Close()
-		if err != nil {
-			log.Fatal(err)
-		}
-	}(stmt)
+	fields := map[string]string{"json": string(jsonBytes)}
 
-	_, err = stmt.Exec("json", string(jsonBytes))
-	return err
-}
-
-func (m *MBT) collectAttributeStats(layer string) []Attribute {
-	var attributes []Attribute
+	if bounds, center, ok := m.bounds.merge(metadata[BoundsKey], metadata[CenterKey]); ok {
+		if metadata[BoundsKey] == metadata[ComputedBoundsKey] {
+			fields[BoundsKey], fields[ComputedBoundsKey] = bounds, bounds
+		}
+		if metadata[CenterKey] == metadata[ComputedCenterKey] {
+			fields[CenterKey], fields[ComputedCenterKey] = center, center
+		}
+	}
 
-	for fieldName, fieldType := range m.fieldTypes[layer] {
-		attr := Attribute{
-			Attribute: fieldName,
-			Type:      fieldType,
-			Count:     1,
+	for name, value := range fields {
+		if _, err = tx.Exec("INSERT OR REPLACE INTO metadata (name, value) VALUES (?, ?)", name, value); err != nil {
+			return err
 		}
-		attributes = append(attributes, attr)
 	}
 
-	return attributes
+	return nil
 }
@@ -0,0 +1,149 @@
+package mbt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Descriptive metadata keys of tileset
+const (
+	NameKey        = "name"
+	DescriptionKey = "description"
+	AttributionKey = "attribution"
+	TypeKey        = "type"
+	VersionKey     = "version"
+	BoundsKey      = "bounds"
+	CenterKey      = "center"
+)
+
+// Tileset types of metadata key type
+const (
+	TypeOverlay   = "overlay"
+	TypeBaselayer = "baselayer"
+)
+
+var ErrInvalidMetadata = errors.New("invalid metadata value")
+
+// Metadata Descriptive metadata of tileset set by user, empty fields keep default values
+type Metadata struct {
+	Name        string
+	Description string
+	Attribution string
+	// Type overlay or baselayer
+	Type    string
+	Version string
+	// Bounds and Center replace values computed from written features
+	Bounds string
+	Center string
+}
+
+// Check check values of fields with the same rules as meta set
+func (d Metadata) Check() error {
+	for name, value := range d.fields() {
+		if err := CheckMetadata(name, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fields non-empty fields by metadata key
+func (d Metadata) fields() map[string]string {
+	fields := make(map[string]string)
+	for name, value := range map[string]string{
+		NameKey:        d.Name,
+		DescriptionKey: d.Description,
+		AttributionKey: d.Attribution,
+		TypeKey:        d.Type,
+		VersionKey:     d.Version,
+		BoundsKey:      d.Bounds,
+		CenterKey:      d.Center,
+	} {
+		if value != "" {
+			fields[name] = value
+		}
+	}
+
+	return fields
+}
+
+// CheckMetadata check value of metadata key defined by the mbtiles spec,
+// values of other keys are not checked
+func CheckMetadata(name, value string) error {
+	var err error
+
+	switch name {
+	case TypeKey:
+		if value != TypeOverlay && value != TypeBaselayer {
+			err = errors.New("must be overlay or baselayer")
+		}
+	case "format":
+		switch value {
+		case "pbf", "png", "jpg", "webp":
+		default:
+			err = errors.New("must be pbf, png, jpg or webp")
+		}
+	case VersionKey:
+		if _, parseErr := strconv.ParseFloat(value, 64); parseErr != nil {
+			err = errors.New("must be a number")
+		}
+	case "minzoom", "maxzoom":
+		if zoom, parseErr := strconv.Atoi(value); parseErr != nil || zoom < 0 || zoom > 30 {
+			err = errors.New("must be an integer zoom from 0 to 30")
+		}
+	case BoundsKey:
+		err = checkNumbers(value, 4)
+		if err == nil {
+			bounds, _ := parseNumbers(value)
+			if bounds[0] < -180 || bounds[2] > 180 || bounds[1] < -90 || bounds[3] > 90 ||
+				bounds[0] >= bounds[2] || bounds[1] >= bounds[3] {
+				err = errors.New("must be left,bottom,right,top in WGS84")
+			}
+		}
+	case CenterKey:
+		err = checkNumbers(value, 3)
+		if err == nil {
+			center, _ := parseNumbers(value)
+			if center[2] != float64(int(center[2])) {
+				err = errors.New("zoom of center must be an integer")
+			}
+		}
+	case "json", AttributeTypesKey:
+		if !json.Valid([]byte(value)) {
+			err = errors.New("must be json")
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %s %q %s", ErrInvalidMetadata, name, value, err)
+	}
+
+	return nil
+}
+
+func checkNumbers(value string, count int) error {
+	numbers, err := parseNumbers(value)
+	if err != nil || len(numbers) != count {
+		return fmt.Errorf("must be %d comma separated numbers", count)
+	}
+
+	return nil
+}
+
+func parseNumbers(value string) ([]float64, error) {
+	parts := strings.Split(value, ",")
+	numbers := make([]float64, 0, len(parts))
+	for _, part := range parts {
+		number, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, number)
+	}
+
+	return numbers, nil
+}
@@ -0,0 +1,39 @@
+package mbt
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckMetadata(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		wantErr bool
+	}{
+		{name: "overlay", key: TypeKey, value: "overlay"},
+		{name: "unknown type", key: TypeKey, value: "base", wantErr: true},
+		{name: "number version", key: VersionKey, value: "1.3"},
+		{name: "text version", key: VersionKey, value: "v1", wantErr: true},
+		{name: "zoom", key: "maxzoom", value: "14"},
+		{name: "zoom out of range", key: "minzoom", value: "31", wantErr: true},
+		{name: "bounds", key: "bounds", value: "1.4,42.4,1.7,42.7"},
+		{name: "inverted bounds", key: "bounds", value: "1.7,42.4,1.4,42.7", wantErr: true},
+		{name: "center", key: "center", value: "1.5,42.5,10"},
+		{name: "center without zoom", key: "center", value: "1.5,42.5", wantErr: true},
+		{name: "invalid json", key: "json", value: "{", wantErr: true},
+		{name: "custom key", key: "source", value: "anything"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckMetadata(tt.key, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckMetadata() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidMetadata) {
+				t.Errorf("CheckMetadata() error = %v, want ErrInvalidMetadata", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package mbt
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Metadata keys of name languages set for convert, updates use the same languages
+const (
+	NameLanguagesKey       = "name_languages"
+	NameTransliterationKey = "name_transliteration"
+)
+
+var ErrInvalidLanguage = errors.New("invalid language")
+
+var languageCode = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z0-9]+)*$`)
+
+// Language Language of name_<code> attribute, value is taken from name:<code>
+// tag and then from name:<fallback> tags in order
+type Language struct {
+	Code      string
+	Fallbacks []string
+}
+
+// ParseLanguage parse language with fallback languages like "be:ru"
+func ParseLanguage(value string) (Language, error) {
+	codes := strings.Split(strings.TrimSpace(value), ":")
+	for _, code := range codes {
+		if !languageCode.MatchString(code) {
+			return Language{}, fmt.Errorf("%w: %q, use codes like en or be:ru", ErrInvalidLanguage, value)
+		}
+	}
+
+	return Language{Code: codes[0], Fallbacks: codes[1:]}, nil
+}
+
+func (l Language) String() string {
+	return strings.Join(append([]string{l.Code}, l.Fallbacks...), ":")
+}
+
+// SetLanguages write name and name_<code> attributes of languages instead of
+// raw name tags, with transliterate names in other script are transliterated
+// to cyrillic or latin when the language has no own name
+func (m *MBT) SetLanguages(languages []Language, transliterate bool) {
+	m.languages = languages
+	m.transliterate = transliterate
+}
+
+// loadLanguages name languages of existing tileset
+func (m *MBT) loadLanguages() error {
+	var languages string
+	err := m.db.QueryRow("SELECT value FROM metadata WHERE name = ?", NameLanguagesKey).Scan(&languages)
+	switch {
+	case err == nil:
+		for _, value := range strings.Split(languages, ",") {
+			language, err := ParseLanguage(value)
+			if err != nil {
+				return err
+			}
+			m.languages = append(m.languages, language)
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		return err
+	}
+
+	var transliterate string
+	err = m.db.QueryRow("SELECT value FROM metadata WHERE name = ?", NameTransliterationKey).Scan(&transliterate)
+	switch {
+	case err == nil:
+		m.transliterate = transliterate == "true"
+	case !errors.Is(err, sql.ErrNoRows):
+		return err
+	}
+
+	return nil
+}
+
+// languageMetadata metadata of name languages if they are set
+func (m *MBT) languageMetadata(fields map[string]string) {
+	if len(m.languages) == 0 {
+		return
+	}
+
+	codes := make([]string, 0, len(m.languages))
+	for _, language := range m.languages {
+		codes = append(codes, language.String())
+	}
+	fields[NameLanguagesKey] = strings.Join(codes, ",")
+
+	if m.transliterate {
+		fields[NameTransliterationKey] = "true"
+	}
+}
+
+// isNameKey name tags replaced by normalized attributes when languages are set
+func isNameKey(key string) bool {
+	return key == "name" || key == "int_name" || strings.HasPrefix(key, "name:")
+}
+
+// setNames write name and name_<code> attributes by languages, name is the
+// local name or the first found name of languages
+func (m *MBT) setNames(properties map[string]interface{}, tags map[string]string) {
+	local := tags["name"]
+
+	for _, language := range m.languages {
+		name := m.languageName(language, tags)
+		if name == "" {
+			continue
+		}
+
+		properties["name_"+language.Code] = name
+		if local == "" {
+			local = name
+		}
+	}
+
+	if local != "" {
+		properties["name"] = local
+	}
+}
+
+// languageName name of language: own tag, tags of fallbacks, international
+// name for latin languages, transliterated local name and local name
+func (m *MBT) languageName(language Language, tags map[string]string) string {
+	for _, code := range append([]string{language.Code}, language.Fallbacks...) {
+		if name := tags["name:"+code]; name != "" {
+			return name
+		}
+	}
+
+	target := languageScript(language.Code)
+	if name := tags["int_name"]; name != "" && target == scriptLatin {
+		return name
+	}
+
+	local := tags["name"]
+	if m.transliterate && local != "" {
+		return transliterate(local, target)
+	}
+
+	return local
+}
@@ -0,0 +1,98 @@
+package mbt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLanguage(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    Language
+		wantErr bool
+	}{
+		{name: "code", value: "en", want: Language{Code: "en", Fallbacks: []string{}}},
+		{name: "fallbacks", value: "be:ru:en", want: Language{Code: "be", Fallbacks: []string{"ru", "en"}}},
+		{name: "region", value: "zh-Hans", want: Language{Code: "zh-Hans", Fallbacks: []string{}}},
+		{name: "empty fallback", value: "be:", wantErr: true},
+		{name: "upper case", value: "EN", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLanguage(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLanguage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseLanguage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMBT_setTags(t *testing.T) {
+	m := &MBT{
+		attributeTypes: DefaultAttributeTypes,
+		languages:      []Language{{Code: "ru"}, {Code: "en"}, {Code: "be", Fallbacks: []string{"ru"}}},
+		transliterate:  true,
+	}
+
+	tests := []struct {
+		name string
+		tags map[string]string
+		want map[string]interface{}
+	}{
+		{
+			name: "own names and fallback",
+			tags: map[string]string{"name": "Мінск", "name:ru": "Минск", "name:de": "Minsk", "place": "city"},
+			want: map[string]interface{}{"name": "Мінск", "name_ru": "Минск", "name_en": "Minsk", "name_be": "Минск", "place": "city"},
+		},
+		{
+			name: "international name",
+			tags: map[string]string{"name": "Москва", "int_name": "Moscow"},
+			want: map[string]interface{}{"name": "Москва", "name_ru": "Москва", "name_en": "Moscow", "name_be": "Москва"},
+		},
+		{
+			name: "no local name",
+			tags: map[string]string{"name:en": "Lake"},
+			want: map[string]interface{}{"name": "Lake", "name_en": "Lake"},
+		},
+		{
+			name: "without names",
+			tags: map[string]string{"highway": "primary"},
+			want: map[string]interface{}{"highway": "primary"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make(map[string]interface{})
+			m.setTags(got, tt.tags)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("setTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransliterate(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		target script
+		want   string
+	}{
+		{name: "to latin", text: "Щёлково-Южное", target: scriptLatin, want: "Shchyolkovo-Yuzhnoe"},
+		{name: "to cyrillic", text: "Andorra la Vella", target: scriptCyrillic, want: "Андорра ла Велла"},
+		{name: "diacritics", text: "Plaça Sant Joan", target: scriptCyrillic, want: "Плака Сант Джоан"},
+		{name: "same script", text: "Минск", target: scriptCyrillic, want: "Минск"},
+		{name: "other script", text: "東京", target: scriptLatin, want: "東京"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transliterate(tt.text, tt.target); got != tt.want {
+				t.Errorf("transliterate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,379 @@
+package mbt
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/clip"
+	"github.com/paulmach/orb/geojson"
+	"github.com/paulmach/orb/maptile"
+	"github.com/paulmach/orb/planar"
+	regionclip "github.com/your-map/mbtiles-tool/internal/clip"
+	"github.com/your-map/mbtiles-tool/internal/shapefile"
+)
+
+// OceanOptionsKey Metadata key of ocean options set for convert, written only when ocean is enabled
+const OceanOptionsKey = "ocean_options"
+
+// waterBuffer Buffer of clipping of water to tile in tile sizes, edges of
+// clipping stay outside of the tile
+const waterBuffer = 1.0 / 32
+
+// OceanOptions Source of polygons of the ocean layer
+type OceanOptions struct {
+	// Coastline assemble water polygons from natural=coastline ways
+	Coastline bool `json:"coastline,omitempty"`
+	// File precomputed water polygons of .shp or geojson file in lon/lat
+	File string `json:"file,omitempty"`
+}
+
+// Enabled ocean layer is written
+func (o OceanOptions) Enabled() bool {
+	return o.Coastline || o.File != ""
+}
+
+// SetOcean set source of ocean polygons, options are saved to metadata. Path
+// of file is saved absolute for updates from other directories
+func (m *MBT) SetOcean(options OceanOptions) error {
+	if options.File != "" {
+		file, err := filepath.Abs(options.File)
+		if err != nil {
+			return err
+		}
+		options.File = file
+	}
+	m.ocean = options
+
+	return nil
+}
+
+// loadOcean ocean options of existing tileset, the file of water polygons
+// must exist for updates
+func (m *MBT) loadOcean() error {
+	var value string
+	err := m.db.QueryRow("SELECT value FROM metadata WHERE name = ?", OceanOptionsKey).Scan(&value)
+	switch {
+	case err == nil:
+		if err = json.Unmarshal([]byte(value), &m.ocean); err != nil {
+			return err
+		}
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	default:
+		return err
+	}
+
+	if m.ocean.File != "" {
+		if _, err = os.Stat(m.ocean.File); err != nil {
+			return fmt.Errorf("water polygons of %s: %w", OceanOptionsKey, err)
+		}
+	}
+
+	return nil
+}
+
+// oceanMetadata metadata of ocean options if ocean is enabled
+func (m *MBT) oceanMetadata(fields map[string]string) error {
+	if !m.ocean.Enabled() {
+		return nil
+	}
+
+	value, err := json.Marshal(m.ocean)
+	if err != nil {
+		return err
+	}
+	fields[OceanOptionsKey] = string(value)
+
+	return nil
+}
+
+// refreshOcean build water polygons again for updates if they are not built
+// or coastlines were changed
+func (m *MBT) refreshOcean() error {
+	if !m.ocean.Enabled() || (m.oceanBuilt && !m.oceanStale) {
+		return nil
+	}
+
+	return m.buildOcean()
+}
+
+// buildOcean water polygons inside of bound of data from coastlines or file,
+// water of tiles is clipped from water of their parent tiles
+func (m *MBT) buildOcean() error {
+	m.water = nil
+	m.waterTiles = nil
+	m.oceanStale = false
+	m.oceanBuilt = true
+
+	bound, ok := m.dataBound()
+	if !ok || !m.ocean.Enabled() || bound.Min[0] == bound.Max[0] || bound.Min[1] == bound.Max[1] {
+		return nil
+	}
+
+	if m.ocean.File != "" {
+		water, err := ReadWaterPolygons(m.ocean.File, bound)
+		if err != nil {
+			return err
+		}
+		m.water = water
+		return nil
+	}
+
+	m.water = clip.MultiPolygon(bound, coastlineWater(m.coastlines(), bound))
+
+	return nil
+}
+
+// coastlines lines of natural=coastline ways ordered by id
+func (m *MBT) coastlines() []orb.LineString {
+	ways := make([]*WayData, 0)
+	for _, way := range m.waysCache {
+		if way.Tags["natural"] == "coastline" && len(way.Nodes) >= 2 {
+			ways = append(ways, way)
+		}
+	}
+	sortWays(ways)
+
+	lines := make([]orb.LineString, len(ways))
+	for i, way := range ways {
+		lines[i] = make(orb.LineString, len(way.Nodes))
+		for j, node := range way.Nodes {
+			lines[i][j] = orb.Point{node.Lon, node.Lat}
+		}
+	}
+
+	return lines
+}
+
+// ReadWaterPolygons read water polygons of .shp or geojson file clipped to bound
+func ReadWaterPolygons(file string, bound orb.Bound) (orb.MultiPolygon, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var water orb.MultiPolygon
+	if strings.EqualFold(filepath.Ext(file), ".shp") {
+		water, err = shapefile.ReadPolygons(f, &bound)
+	} else {
+		water, err = regionclip.ParseGeoJSON(f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("water polygons %s: %w", file, err)
+	}
+
+	return clip.MultiPolygon(bound, water), nil
+}
+
+// waterInTile water polygons of tile clipped with buffer, water of the parent
+// tile is clipped if the previous zoom was clipped before
+func (m *MBT) waterInTile(zoom, x, y int) orb.MultiPolygon {
+	if len(m.water) == 0 {
+		return nil
+	}
+
+	if m.waterTiles == nil || zoom != m.waterZoom {
+		m.parentWater = nil
+		if zoom == m.waterZoom+1 {
+			m.parentWater = m.waterTiles
+		}
+		m.waterTiles = make(map[maptile.Tile]orb.MultiPolygon)
+		m.waterZoom = zoom
+	}
+
+	tile := maptile.New(uint32(x), uint32(y), maptile.Zoom(zoom))
+	source := m.water
+	if parent, ok := m.parentWater[tile.Parent()]; ok {
+		source = parent
+	}
+
+	var water orb.MultiPolygon
+	if len(source) > 0 {
+		water = clip.MultiPolygon(tile.Bound(waterBuffer), source.Clone())
+	}
+	m.waterTiles[tile] = water
+
+	return water
+}
+
+// coastlineWater water polygons inside of bound from coastlines, land is on the
+// left side of coastline. Coastlines open in bound are closed along the border
+// of bound, islands of closed coastlines are holes of water
+func coastlineWater(coastlines []orb.LineString, bound orb.Bound) orb.MultiPolygon {
+	chains, _ := joinLineStrings(coastlines, false)
+
+	var exteriors, holes []orb.Ring
+	var open []orb.LineString
+	for _, chain := range chains {
+		// Water is on the left side of reversed coastline like in exterior rings
+		chain = reversed(chain)
+		if len(chain) >= 4 && chain[0] == chain[len(chain)-1] {
+			if ring := orb.Ring(chain); ring.Orientation() == orb.CCW {
+				exteriors = append(exteriors, ring)
+			} else {
+				holes = append(holes, ring)
+			}
+			continue
+		}
+
+		open = append(open, extendToBorder(chain, bound))
+	}
+
+	if len(open) > 0 {
+		exteriors = append(exteriors, closeAlongBorder(open, bound)...)
+	} else if len(holes) > 0 && containing(exteriors, holes[0]) < 0 {
+		// Islands without mainland are surrounded by water
+		exteriors = append(exteriors, bound.ToRing())
+	}
+
+	water := make(orb.MultiPolygon, 0, len(exteriors))
+	for _, exterior := range exteriors {
+		water = append(water, orb.Polygon{exterior})
+	}
+
+	for _, hole := range holes {
+		if i := containing(exteriors, hole); i >= 0 {
+			water[i] = append(water[i], hole)
+		}
+	}
+
+	return water
+}
+
+// containing index of the smallest exterior containing ring, -1 if there is no one
+func containing(exteriors []orb.Ring, ring orb.Ring) int {
+	found := -1
+	smallest := math.Inf(1)
+	for i, exterior := range exteriors {
+		if area := math.Abs(planar.Area(exterior)); area < smallest && planar.RingContains(exterior, ring[0]) {
+			found = i
+			smallest = area
+		}
+	}
+
+	return found
+}
+
+// extendToBorder extend ends of line inside of bound to the nearest point of border
+func extendToBorder(line orb.LineString, bound orb.Bound) orb.LineString {
+	if start := nearestBorderPoint(line[0], bound); start != line[0] {
+		line = append(orb.LineString{start}, line...)
+	}
+	if end := nearestBorderPoint(line[len(line)-1], bound); end != line[len(line)-1] {
+		line = append(line, end)
+	}
+
+	return line
+}
+
+func nearestBorderPoint(point orb.Point, bound orb.Bound) orb.Point {
+	candidates := []orb.Point{
+		{bound.Min[0], point[1]},
+		{bound.Max[0], point[1]},
+		{point[0], bound.Min[1]},
+		{point[0], bound.Max[1]},
+	}
+
+	nearest := candidates[0]
+	for _, candidate := range candidates[1:] {
+		if planar.DistanceSquared(point, candidate) < planar.DistanceSquared(point, nearest) {
+			nearest = candidate
+		}
+	}
+
+	return nearest
+}
+
+// borderPosition position of point of border counterclockwise from the bottom
+// left corner, each side has length 1
+func borderPosition(point orb.Point, bound orb.Bound) float64 {
+	width := bound.Max[0] - bound.Min[0]
+	height := bound.Max[1] - bound.Min[1]
+
+	sides := []struct {
+		distance float64
+		position float64
+	}{
+		{point[1] - bound.Min[1], (point[0] - bound.Min[0]) / width},
+		{bound.Max[0] - point[0], 1 + (point[1]-bound.Min[1])/height},
+		{bound.Max[1] - point[1], 2 + (bound.Max[0]-point[0])/width},
+		{point[0] - bound.Min[0], 3 + (bound.Max[1]-point[1])/height},
+	}
+
+	nearest := sides[0]
+	for _, side := range sides[1:] {
+		if math.Abs(side.distance) < math.Abs(nearest.distance) {
+			nearest = side
+		}
+	}
+
+	return math.Mod(nearest.position, 4)
+}
+
+// closeAlongBorder rings of lines with ends on border of bound, from the end
+// of line the ring follows the border counterclockwise to the nearest start
+// of line, which is the next part of the ring
+func closeAlongBorder(lines []orb.LineString, bound orb.Bound) []orb.Ring {
+	corners := []orb.Point{bound.Min, {bound.Max[0], bound.Min[1]}, bound.Max, {bound.Min[0], bound.Max[1]}}
+
+	used := make([]bool, len(lines))
+	rings := make([]orb.Ring, 0)
+	for first := range lines {
+		if used[first] {
+			continue
+		}
+		used[first] = true
+		ring := append(orb.Ring{}, lines[first]...)
+
+		for {
+			exit := borderPosition(ring[len(ring)-1], bound)
+
+			next, distance := -1, math.Inf(1)
+			for j, line := range lines {
+				if used[j] && j != first {
+					continue
+				}
+				if d := math.Mod(borderPosition(line[0], bound)-exit+4, 4); d < distance {
+					next, distance = j, d
+				}
+			}
+
+			for corner := math.Floor(exit) + 1; corner-exit < distance; corner++ {
+				ring = append(ring, corners[int(corner)%4])
+			}
+
+			if next == first {
+				break
+			}
+			used[next] = true
+			ring = append(ring, lines[next]...)
+		}
+
+		ring = append(ring, ring[0])
+		rings = append(rings, ring)
+	}
+
+	return rings
+}
+
+// waterFeatures features of water polygons of ocean layer, they have no id.
+// Polygons are copied because water of tile is kept for its child tiles
+func waterFeatures(water orb.MultiPolygon) []*geojson.Feature {
+	features := make([]*geojson.Feature, len(water))
+	for i, polygon := range water {
+		features[i] = geojson.NewFeature(polygon.Clone())
+		features[i].Properties = map[string]interface{}{}
+	}
+
+	return features
+}
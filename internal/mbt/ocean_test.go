@@ -0,0 +1,131 @@
+package mbt
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/planar"
+)
+
+func TestCoastlineWater(t *testing.T) {
+	bound := orb.Bound{Min: orb.Point{0, 0}, Max: orb.Point{1, 1}}
+	// Land is on the left side of coastline, islands are counterclockwise
+	island := orb.LineString{{0.2, 0.2}, {0.3, 0.2}, {0.3, 0.3}, {0.2, 0.3}, {0.2, 0.2}}
+
+	tests := []struct {
+		name       string
+		coastlines []orb.LineString
+		want       float64
+	}{
+		{name: "no coastline"},
+		{
+			name:       "split coastline with land in the north",
+			coastlines: []orb.LineString{{{0.5, 0.5}, {1, 0.5}}, {{0, 0.5}, {0.5, 0.5}}},
+			want:       0.5,
+		},
+		{
+			name:       "island in the sea",
+			coastlines: []orb.LineString{{{0, 0.5}, {1, 0.5}}, island},
+			want:       0.49,
+		},
+		{
+			name:       "island without mainland",
+			coastlines: []orb.LineString{island},
+			want:       0.99,
+		},
+		{
+			name:       "coastline through corner",
+			coastlines: []orb.LineString{{{0.5, 0}, {1, 0.5}}},
+			want:       0.125,
+		},
+		{
+			name:       "coastline ending inside",
+			coastlines: []orb.LineString{{{0.1, 0.5}, {0.9, 0.5}}},
+			want:       0.5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			water := coastlineWater(tt.coastlines, bound)
+			if got := waterArea(water); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("area of coastlineWater() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// waterArea area of exterior rings without holes
+func waterArea(water orb.MultiPolygon) float64 {
+	area := 0.0
+	for _, polygon := range water {
+		for i, ring := range polygon {
+			if i == 0 {
+				area += math.Abs(planar.Area(ring))
+			} else {
+				area -= math.Abs(planar.Area(ring))
+			}
+		}
+	}
+
+	return area
+}
+
+func TestMBT_waterInTile(t *testing.T) {
+	// Water in the south east quarter of the world, not in buffer of tile 1/0/0
+	water := orb.MultiPolygon{{{{10, -80}, {170, -80}, {170, -10}, {10, -10}, {10, -80}}}}
+	m := &MBT{water: water}
+
+	if got := m.waterInTile(0, 0, 0); len(got) != 1 {
+		t.Fatalf("waterInTile(0, 0, 0) = %v, want water", got)
+	}
+	if got := m.waterInTile(1, 0, 0); len(got) != 0 {
+		t.Errorf("waterInTile(1, 0, 0) = %v, want no water", got)
+	}
+	got := m.waterInTile(1, 1, 1)
+	if len(got) != 1 || !got.Bound().Contains(orb.Point{90, -45}) {
+		t.Errorf("waterInTile(1, 1, 1) = %v, want water of tile", got)
+	}
+	if len(m.water[0][0]) != 5 || m.water[0][0][1] != (orb.Point{170, -80}) {
+		t.Errorf("water = %v, clipping changed water", m.water)
+	}
+}
+
+func TestMBT_SetOcean(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.WriteFile("water.geojson", []byte(`{"type":"FeatureCollection","features":[]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(dir, "ocean.mbtiles")
+	m, err := NewMBT(file)
+	if err != nil {
+		t.Fatalf("NewMBT() error = %v", err)
+	}
+	if err = m.SetOcean(OceanOptions{File: "water.geojson"}); err != nil {
+		t.Fatalf("SetOcean() error = %v", err)
+	}
+	if want := filepath.Join(dir, "water.geojson"); m.ocean.File != want {
+		t.Errorf("ocean file = %q, want %q", m.ocean.File, want)
+	}
+
+	fields := make(map[string]string)
+	if err = m.oceanMetadata(fields); err != nil {
+		t.Fatal(err)
+	}
+	if err = m.setMetadata(OceanOptionsKey, fields[OceanOptionsKey]); err != nil {
+		t.Fatal(err)
+	}
+	_ = m.Close()
+
+	if err = os.Remove("water.geojson"); err != nil {
+		t.Fatal(err)
+	}
+	if m, err = NewMBT(file); err == nil {
+		_ = m.Close()
+		t.Errorf("NewMBT() without water polygons file error = nil, want error")
+	}
+}
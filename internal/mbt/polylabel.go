@@ -0,0 +1,111 @@
+package mbt
+
+import (
+	"container/heap"
+	"math"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/planar"
+)
+
+// polylabel pole of inaccessibility of polygon, the inner point most distant
+// from the outline, found with precision in units of polygon by the algorithm
+// of mapbox/polylabel
+func polylabel(polygon orb.Polygon, precision float64) orb.Point {
+	bound := polygon[0].Bound()
+	cellSize := math.Min(bound.Right()-bound.Left(), bound.Top()-bound.Bottom())
+	if cellSize == 0 {
+		return bound.Min
+	}
+
+	half := cellSize / 2
+	cells := &cellQueue{}
+	for x := bound.Left(); x < bound.Right(); x += cellSize {
+		for y := bound.Bottom(); y < bound.Top(); y += cellSize {
+			heap.Push(cells, newCell(orb.Point{x + half, y + half}, half, polygon))
+		}
+	}
+
+	// The first guesses are the centroid and the center of bound
+	centroid, _ := planar.CentroidArea(polygon)
+	best := newCell(centroid, 0, polygon)
+	if center := newCell(bound.Center(), 0, polygon); center.distance > best.distance {
+		best = center
+	}
+
+	for cells.Len() > 0 {
+		cell := heap.Pop(cells).(*cell)
+
+		if cell.distance > best.distance {
+			best = cell
+		}
+
+		// Cell can not contain better point
+		if cell.max-best.distance <= precision {
+			continue
+		}
+
+		half = cell.half / 2
+		for _, offset := range [4][2]float64{{-1, -1}, {1, -1}, {-1, 1}, {1, 1}} {
+			center := orb.Point{cell.center[0] + offset[0]*half, cell.center[1] + offset[1]*half}
+			heap.Push(cells, newCell(center, half, polygon))
+		}
+	}
+
+	return best.center
+}
+
+// cell Square of search with signed distance of center to polygon
+// and the largest possible distance of points in it
+type cell struct {
+	center   orb.Point
+	half     float64
+	distance float64
+	max      float64
+}
+
+func newCell(center orb.Point, half float64, polygon orb.Polygon) *cell {
+	distance := polygonDistance(center, polygon)
+
+	return &cell{
+		center:   center,
+		half:     half,
+		distance: distance,
+		max:      distance + half*math.Sqrt2,
+	}
+}
+
+// polygonDistance distance of point to outline of polygon, negative outside
+func polygonDistance(point orb.Point, polygon orb.Polygon) float64 {
+	minDistance := math.Inf(1)
+	for _, ring := range polygon {
+		for i := 0; i < len(ring)-1; i++ {
+			minDistance = math.Min(minDistance, planar.DistanceFromSegment(ring[i], ring[i+1], point))
+		}
+	}
+
+	if !planar.PolygonContains(polygon, point) {
+		return -minDistance
+	}
+
+	return minDistance
+}
+
+// cellQueue Max heap of cells by the largest possible distance
+type cellQueue []*cell
+
+func (q cellQueue) Len() int           { return len(q) }
+func (q cellQueue) Less(i, j int) bool { return q[i].max > q[j].max }
+func (q cellQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *cellQueue) Push(x any) {
+	*q = append(*q, x.(*cell))
+}
+
+func (q *cellQueue) Pop() any {
+	old := *q
+	last := old[len(old)-1]
+	*q = old[:len(old)-1]
+
+	return last
+}
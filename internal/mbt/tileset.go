@@ -0,0 +1,286 @@
+package mbt
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var (
+	ErrTileNotFound = errors.New("tile not found")
+	ErrNotMBTiles   = errors.New("file is not a mbtiles tileset")
+	ErrFileExists   = errors.New("file already exists")
+)
+
+// schema Tables of mbtiles spec https://github.com/mapbox/mbtiles-spec
+const schema = `
+	CREATE TABLE IF NOT EXISTS metadata (
+		name  text,
+		value text
+	);
+
+	CREATE TABLE IF NOT EXISTS tiles (
+		zoom_level  integer,
+		tile_column integer,
+		tile_row    integer,
+		tile_data   blob
+	);
+
+	-- Files without the index may have duplicate names, the last row is kept
+	DELETE FROM metadata WHERE rowid NOT IN (
+		SELECT MAX(rowid) FROM metadata GROUP BY name
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS metadata_index
+	ON metadata (name);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS tile_index
+	ON tiles (zoom_level, tile_column, tile_row);
+`
+
+// Compression types of the tile_data blobs
+const (
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+	CompressionZlib = "deflate"
+	// CompressionMixed tiles of tileset are compressed differently
+	CompressionMixed = "mixed"
+)
+
+// Tileset Read access to an existing mbtiles file
+type Tileset struct {
+	File string
+	db   *sql.DB
+}
+
+// Open open mbtiles file in read only mode
+func Open(file string) (*Tileset, error) {
+	return open(file, "ro")
+}
+
+// OpenWritable open existing mbtiles file for changing of metadata and tiles
+func OpenWritable(file string) (*Tileset, error) {
+	return open(file, "rw")
+}
+
+func open(file, mode string) (*Tileset, error) {
+	if _, err := os.Stat(file); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite3", dsn(file, mode))
+	if err != nil {
+		return nil, err
+	}
+
+	var tables int
+	err = db.QueryRow(`
+		SELECT COUNT(*) FROM sqlite_master
+		WHERE name IN ('metadata', 'tiles') AND type IN ('table', 'view')
+	`).Scan(&tables)
+	if err != nil || tables != 2 {
+		_ = db.Close()
+		return nil, fmt.Errorf("%w: %s", ErrNotMBTiles, file)
+	}
+
+	return &Tileset{File: file, db: db}, nil
+}
+
+// dsn sqlite uri of file opened in mode, the path is escaped for file names with ?, # or %
+func dsn(file, mode string) string {
+	uri := url.URL{Scheme: "file", Path: file, OmitHost: true, RawQuery: "mode=" + mode}
+	return uri.String()
+}
+
+// Create create new empty mbtiles file for writing
+func Create(file string) (*Tileset, error) {
+	if _, err := os.Stat(file); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrFileExists, file)
+	}
+
+	db, err := sql.Open("sqlite3", dsn(file, "rwc"))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = db.Exec(schema); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &Tileset{File: file, db: db}, nil
+}
+
+// Metadata return all rows of the metadata table
+func (t *Tileset) Metadata() (map[string]string, error) {
+	rows, err := t.db.Query("SELECT name, value FROM metadata")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	metadata := make(map[string]string)
+	for rows.Next() {
+		var name, value sql.NullString
+		if err = rows.Scan(&name, &value); err != nil {
+			return nil, err
+		}
+		metadata[name.String] = value.String
+	}
+
+	return metadata, rows.Err()
+}
+
+// Tile return tile data by xyz coordinates, the row is flipped to the tms scheme of mbtiles
+func (t *Tileset) Tile(z, x, y int) ([]byte, error) {
+	var data []byte
+
+	err := t.db.QueryRow(
+		"SELECT tile_data FROM tiles WHERE zoom_level = ? AND tile_column = ? AND tile_row = ?",
+		z, x, FlipY(z, y),
+	).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTileNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// SetMetadata insert or replace metadata value, existing files may have no
+// unique index of names, so old rows are deleted before insert
+func (t *Tileset) SetMetadata(name, value string) error {
+	tx, err := t.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec("DELETE FROM metadata WHERE name = ?", name); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if _, err = tx.Exec("INSERT INTO metadata (name, value) VALUES (?, ?)", name, value); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteMetadata delete metadata rows by names, count of deleted rows is returned
+func (t *Tileset) DeleteMetadata(names ...string) (int, error) {
+	deleted := 0
+	for _, name := range names {
+		result, err := t.db.Exec("DELETE FROM metadata WHERE name = ?", name)
+		if err != nil {
+			return deleted, err
+		}
+
+		count, err := result.RowsAffected()
+		if err != nil {
+			return deleted, err
+		}
+		deleted += int(count)
+	}
+
+	return deleted, nil
+}
+
+// NewBatch start transaction for writing many tiles
+func (t *Tileset) NewBatch() (*Batch, error) {
+	tx, err := t.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	stmt, err := tx.Prepare("INSERT OR REPLACE INTO tiles (zoom_level, tile_column, tile_row, tile_data) VALUES (?, ?, ?, ?)")
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	return &Batch{tx: tx, stmt: stmt}, nil
+}
+
+// EachTile call fn for every tile of tileset with xyz coordinates ordered by zoom
+func (t *Tileset) EachTile(fn func(z, x, y int, data []byte) error) error {
+	rows, err := t.db.Query(`
+		SELECT zoom_level, tile_column, tile_row, tile_data
+		FROM tiles
+		ORDER BY zoom_level, tile_column, tile_row
+	`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var z, x, y int
+		var data []byte
+		if err = rows.Scan(&z, &x, &y, &data); err != nil {
+			return err
+		}
+
+		if err = fn(z, x, FlipY(z, y), data); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (t *Tileset) Close() error {
+	return t.db.Close()
+}
+
+func ignoreNoRows(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+
+	return err
+}
+
+// FlipY convert row between xyz and tms schemes
+func FlipY(z, y int) int {
+	return (1 << z) - 1 - y
+}
+
+// DetectCompression return compression of tile data by magic bytes
+func DetectCompression(data []byte) string {
+	switch {
+	case len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b:
+		return CompressionGzip
+	case len(data) >= 2 && data[0] == 0x78 && (uint16(data[0])<<8|uint16(data[1]))%31 == 0:
+		return CompressionZlib
+	default:
+		return CompressionNone
+	}
+}
+
+// Batch Transaction of tile writes
+type Batch struct {
+	tx   *sql.Tx
+	stmt *sql.Stmt
+}
+
+// PutTile write tile by xyz coordinates
+func (b *Batch) PutTile(z, x, y int, data []byte) error {
+	_, err := b.stmt.Exec(z, x, FlipY(z, y), data)
+	return err
+}
+
+func (b *Batch) Commit() error {
+	return errors.Join(b.stmt.Close(), b.tx.Commit())
+}
+
+func (b *Batch) Rollback() error {
+	return errors.Join(b.stmt.Close(), b.tx.Rollback())
+}
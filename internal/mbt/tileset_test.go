@@ -0,0 +1,100 @@
+package mbt
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpen_specialCharacters(t *testing.T) {
+	for _, name := range []string{"a?b.mbtiles", "a#b.mbtiles", "a%20b.mbtiles"} {
+		t.Run(name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), name)
+			created, err := Create(file)
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			_ = created.Close()
+
+			tileset, err := Open(file)
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			defer tileset.Close()
+
+			if _, err = tileset.Metadata(); err != nil {
+				t.Errorf("Metadata() error = %v", err)
+			}
+		})
+	}
+}
+
+// legacyTileset mbtiles file without unique index of metadata names, names
+// of metadata rows are duplicated
+func legacyTileset(t *testing.T) string {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "legacy.mbtiles")
+	db, err := sql.Open("sqlite3", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`
+		CREATE TABLE metadata (name text, value text);
+		CREATE TABLE tiles (zoom_level integer, tile_column integer, tile_row integer, tile_data blob);
+		INSERT INTO metadata VALUES ('name', 'old'), ('name', 'new'), ('format', 'pbf');
+	`)
+	_ = db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
+
+// nameRows count of metadata rows of name and the last value
+func nameRows(t *testing.T, db *sql.DB) (int, string) {
+	t.Helper()
+
+	var count int
+	var value string
+	err := db.QueryRow(`
+		SELECT COUNT(*), (SELECT value FROM metadata WHERE name = 'name' ORDER BY rowid DESC LIMIT 1)
+		FROM metadata WHERE name = 'name'
+	`).Scan(&count, &value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return count, value
+}
+
+func TestNewMBT_duplicateMetadata(t *testing.T) {
+	m, err := NewMBT(legacyTileset(t))
+	if err != nil {
+		t.Fatalf("NewMBT() error = %v", err)
+	}
+	defer m.Close()
+
+	if count, value := nameRows(t, m.db); count != 1 || value != "new" {
+		t.Errorf("metadata name has %d rows with %q, want 1 row with %q", count, value, "new")
+	}
+}
+
+func TestTileset_SetMetadata(t *testing.T) {
+	tileset, err := OpenWritable(legacyTileset(t))
+	if err != nil {
+		t.Fatalf("OpenWritable() error = %v", err)
+	}
+	defer tileset.Close()
+
+	for _, value := range []string{"first", "second"} {
+		if err = tileset.SetMetadata("name", value); err != nil {
+			t.Fatalf("SetMetadata() error = %v", err)
+		}
+	}
+
+	if count, value := nameRows(t, tileset.db); count != 1 || value != "second" {
+		t.Errorf("metadata name has %d rows with %q, want 1 row with %q", count, value, "second")
+	}
+}
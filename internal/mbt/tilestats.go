@@ -0,0 +1,466 @@
+package mbt
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/encoding/mvt"
+	"github.com/paulmach/orb/geojson"
+	"github.com/paulmach/orb/maptile"
+)
+
+// maxAttributeValues Limit of sample values of attribute, the same as in mapbox-geostats
+const maxAttributeValues = 100
+
+// statsCollector Statistics of features written to tiles, feature written to
+// many tiles is counted once by its id
+type statsCollector struct {
+	layers map[string]*layerCollector
+}
+
+type layerCollector struct {
+	minZoom, maxZoom int
+
+	count      int
+	features   map[interface{}]bool
+	geometries map[string]int
+	attributes map[string]*attributeCollector
+}
+
+type attributeCollector struct {
+	count  int
+	types  map[string]bool
+	values []interface{}
+	seen   map[interface{}]bool
+
+	numbers  bool
+	min, max float64
+}
+
+func newStatsCollector() *statsCollector {
+	return &statsCollector{layers: make(map[string]*layerCollector)}
+}
+
+// tileFeature key of feature without id, it is counted in every tile
+type tileFeature struct {
+	tile  maptile.Tile
+	index int
+}
+
+// add collect features of encoded layer of tile
+func (s *statsCollector) add(layer *mvt.Layer, tile maptile.Tile) {
+	collector := s.layer(layer.Name, int(tile.Z))
+
+	for i, feature := range layer.Features {
+		key := featureKey(feature, tile, i)
+		if collector.features[key] {
+			continue
+		}
+		collector.features[key] = true
+		collector.addFeature(feature)
+	}
+}
+
+// layer collector of layer with zooms extended by zoom
+func (s *statsCollector) layer(name string, zoom int) *layerCollector {
+	collector, ok := s.layers[name]
+	if !ok {
+		collector = newLayerCollector(zoom, zoom)
+		s.layers[name] = collector
+	}
+	collector.minZoom = min(collector.minZoom, zoom)
+	collector.maxZoom = max(collector.maxZoom, zoom)
+
+	return collector
+}
+
+func newLayerCollector(minZoom, maxZoom int) *layerCollector {
+	return &layerCollector{
+		minZoom:    minZoom,
+		maxZoom:    maxZoom,
+		features:   make(map[interface{}]bool),
+		geometries: make(map[string]int),
+		attributes: make(map[string]*attributeCollector),
+	}
+}
+
+func (l *layerCollector) addFeature(feature *geojson.Feature) {
+	l.count++
+	l.geometries[geometryType(feature.Geometry)]++
+
+	for name, value := range feature.Properties {
+		attribute, ok := l.attributes[name]
+		if !ok {
+			attribute = &attributeCollector{types: make(map[string]bool), seen: make(map[interface{}]bool)}
+			l.attributes[name] = attribute
+		}
+		attribute.add(value)
+	}
+}
+
+// removeFeature remove counts of feature, types, sample values and ranges of
+// attributes are kept because other features may have them
+func (l *layerCollector) removeFeature(feature *geojson.Feature) {
+	l.count = max(0, l.count-1)
+
+	geometry := geometryType(feature.Geometry)
+	if l.geometries[geometry]--; l.geometries[geometry] <= 0 {
+		delete(l.geometries, geometry)
+	}
+
+	for name := range feature.Properties {
+		if attribute, ok := l.attributes[name]; ok {
+			if attribute.count--; attribute.count <= 0 {
+				delete(l.attributes, name)
+			}
+		}
+	}
+}
+
+// vectorLayers layers of vector_layers metadata with zooms and fields of written features
+func (s *statsCollector) vectorLayers() []VectorLayer {
+	layers := make([]VectorLayer, 0, len(s.layers))
+
+	for name, collector := range s.layers {
+		layer := VectorLayer{
+			ID:          name,
+			Description: layerDescriptions[name],
+			MinZoom:     collector.minZoom,
+			MaxZoom:     collector.maxZoom,
+			Fields:      make(map[string]string, len(collector.attributes)),
+		}
+
+		for attributeName, attribute := range collector.attributes {
+			layer.Fields[attributeName] = attribute.fieldType()
+		}
+
+		layers = append(layers, layer)
+	}
+
+	sort.Slice(layers, func(i, j int) bool {
+		return layers[i].ID < layers[j].ID
+	})
+
+	return layers
+}
+
+// tileStats statistics in mapbox-geostats format, layers and attributes are sorted by name
+func (s *statsCollector) tileStats() TileStats {
+	stats := TileStats{Layers: make([]LayerStat, 0, len(s.layers))}
+
+	for name, collector := range s.layers {
+		layer := LayerStat{
+			Layer:          name,
+			Count:          collector.count,
+			Geometry:       collector.geometry(),
+			AttributeCount: len(collector.attributes),
+			Attributes:     make([]Attribute, 0, len(collector.attributes)),
+		}
+
+		for attributeName, attribute := range collector.attributes {
+			layer.Attributes = append(layer.Attributes, attribute.attribute(attributeName))
+		}
+		sort.Slice(layer.Attributes, func(i, j int) bool {
+			return layer.Attributes[i].Attribute < layer.Attributes[j].Attribute
+		})
+
+		stats.Layers = append(stats.Layers, layer)
+	}
+
+	sort.Slice(stats.Layers, func(i, j int) bool {
+		return stats.Layers[i].Layer < stats.Layers[j].Layer
+	})
+	stats.LayerCount = len(stats.Layers)
+
+	return stats
+}
+
+// geometry the most frequent geometry type of layer
+func (l *layerCollector) geometry() string {
+	result, count := "", 0
+	for geometry, geometryCount := range l.geometries {
+		if geometryCount > count || (geometryCount == count && geometry < result) {
+			result, count = geometry, geometryCount
+		}
+	}
+
+	return result
+}
+
+func (a *attributeCollector) add(value interface{}) {
+	a.count++
+
+	// Numbers of any type are the same sample value
+	key := value
+	switch v := value.(type) {
+	case bool:
+		a.types["boolean"] = true
+	case string:
+		a.types["string"] = true
+	default:
+		number, ok := toFloat(v)
+		if !ok {
+			value = fmt.Sprint(v)
+			key = value
+			a.types["string"] = true
+			break
+		}
+		key = number
+
+		a.types["number"] = true
+		if !a.numbers || number < a.min {
+			a.min = number
+		}
+		if !a.numbers || number > a.max {
+			a.max = number
+		}
+		a.numbers = true
+	}
+
+	if len(a.values) < maxAttributeValues && !a.seen[key] {
+		a.seen[key] = true
+		a.values = append(a.values, value)
+	}
+}
+
+func (a *attributeCollector) attribute(name string) Attribute {
+	attribute := Attribute{
+		Attribute: name,
+		Count:     a.count,
+		Type:      "mixed",
+		Values:    a.values,
+	}
+
+	if len(a.types) == 1 {
+		for attributeType := range a.types {
+			attribute.Type = attributeType
+		}
+	}
+
+	if a.numbers {
+		attribute.Min, attribute.Max = a.min, a.max
+	}
+
+	return attribute
+}
+
+// fieldType type of field in vector_layers, mixed values are described as String
+func (a *attributeCollector) fieldType() string {
+	if len(a.types) == 1 {
+		switch {
+		case a.types["number"]:
+			return "Number"
+		case a.types["boolean"]:
+			return "Boolean"
+		}
+	}
+
+	return "String"
+}
+
+// featureKey id of feature, features without id like water polygons are
+// counted by tile and index in every tile
+func featureKey(feature *geojson.Feature, tile maptile.Tile, index int) interface{} {
+	if feature.ID != nil {
+		return feature.ID
+	}
+
+	return tileFeature{tile: tile, index: index}
+}
+
+// geometryType geometry type of geostats, multi geometries are counted as single
+func geometryType(geometry orb.Geometry) string {
+	switch geometry.(type) {
+	case orb.Point, orb.MultiPoint:
+		return "Point"
+	case orb.LineString, orb.MultiLineString:
+		return "LineString"
+	case orb.Polygon, orb.MultiPolygon, orb.Ring:
+		return "Polygon"
+	default:
+		return "Unknown"
+	}
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// statsFromMetadata collector of vector_layers and tilestats of json metadata,
+// features are only counted and cannot be recognized again
+func statsFromMetadata(raw string) (*statsCollector, error) {
+	content := struct {
+		VectorLayers []VectorLayer `json:"vector_layers"`
+		TileStats    TileStats     `json:"tilestats"`
+	}{}
+	if raw != "" {
+		if err := json.Unmarshal([]byte(raw), &content); err != nil {
+			return nil, err
+		}
+	}
+
+	zooms := make(map[string]VectorLayer, len(content.VectorLayers))
+	for _, layer := range content.VectorLayers {
+		zooms[layer.ID] = layer
+	}
+
+	s := newStatsCollector()
+	for _, stat := range content.TileStats.Layers {
+		collector := newLayerCollector(minZoom, maxZoom)
+		if layer, ok := zooms[stat.Layer]; ok {
+			collector.minZoom, collector.maxZoom = layer.MinZoom, layer.MaxZoom
+		}
+
+		collector.count = stat.Count
+		if stat.Geometry != "" {
+			collector.geometries[stat.Geometry] = stat.Count
+		}
+		for _, attribute := range stat.Attributes {
+			collector.attributes[attribute.Attribute] = attributeFromStat(attribute)
+		}
+
+		s.layers[stat.Layer] = collector
+	}
+
+	return s, nil
+}
+
+// attributeFromStat collector of attribute of tilestats, mixed types are
+// written as mixed again
+func attributeFromStat(attribute Attribute) *attributeCollector {
+	a := &attributeCollector{
+		count:  attribute.Count,
+		types:  make(map[string]bool),
+		values: attribute.Values,
+		seen:   make(map[interface{}]bool, len(attribute.Values)),
+	}
+
+	if attribute.Type == "mixed" {
+		a.types["string"], a.types["number"] = true, true
+	} else {
+		a.types[attribute.Type] = true
+	}
+
+	for _, value := range attribute.Values {
+		if number, ok := toFloat(value); ok {
+			a.seen[number] = true
+		} else {
+			a.seen[value] = true
+		}
+	}
+
+	minimum, okMin := toFloat(attribute.Min)
+	maximum, okMax := toFloat(attribute.Max)
+	if okMin && okMax {
+		a.min, a.max, a.numbers = minimum, maximum, true
+	}
+
+	return a
+}
+
+// statsDelta Features of updated tiles before and after update by layer,
+// features of many tiles are counted once by key
+type statsDelta map[string]*layerDelta
+
+type layerDelta struct {
+	minZoom, maxZoom int
+	found            bool
+
+	removed map[interface{}]*geojson.Feature
+	added   map[interface{}]*geojson.Feature
+	// order keys of added features in order of tiles for stable sample values
+	order []interface{}
+}
+
+func (d statsDelta) layer(name string) *layerDelta {
+	delta, ok := d[name]
+	if !ok {
+		delta = &layerDelta{
+			removed: make(map[interface{}]*geojson.Feature),
+			added:   make(map[interface{}]*geojson.Feature),
+		}
+		d[name] = delta
+	}
+
+	return delta
+}
+
+// remove feature of tile content before update
+func (d statsDelta) remove(name string, key interface{}, feature *geojson.Feature) {
+	d.layer(name).removed[key] = feature
+}
+
+// add feature of tile content after update on zoom
+func (d statsDelta) add(name string, zoom int, key interface{}, feature *geojson.Feature) {
+	delta := d.layer(name)
+	if _, ok := delta.added[key]; !ok {
+		delta.order = append(delta.order, key)
+	}
+	delta.added[key] = feature
+
+	if !delta.found {
+		delta.minZoom, delta.maxZoom, delta.found = zoom, zoom, true
+	}
+	delta.minZoom = min(delta.minZoom, zoom)
+	delta.maxZoom = max(delta.maxZoom, zoom)
+}
+
+// apply change counts by delta, features with equal geometry type and
+// attributes before and after update are not counted again. Layers without
+// features are removed
+func (s *statsCollector) apply(d statsDelta) {
+	for name, delta := range d {
+		collector, ok := s.layers[name]
+		if !ok && delta.found {
+			collector = newLayerCollector(delta.minZoom, delta.maxZoom)
+			s.layers[name] = collector
+		}
+		if collector == nil {
+			continue
+		}
+
+		for key, feature := range delta.removed {
+			added, ok := delta.added[key]
+			if ok && geometryType(added.Geometry) == geometryType(feature.Geometry) && reflect.DeepEqual(added.Properties, feature.Properties) {
+				delete(delta.added, key)
+				continue
+			}
+			collector.removeFeature(feature)
+		}
+
+		for _, key := range delta.order {
+			if feature, ok := delta.added[key]; ok {
+				collector.addFeature(feature)
+			}
+		}
+
+		if delta.found {
+			collector.minZoom = min(collector.minZoom, delta.minZoom)
+			collector.maxZoom = max(collector.maxZoom, delta.maxZoom)
+		}
+
+		if collector.count == 0 {
+			delete(s.layers, name)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package mbt
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/encoding/mvt"
+	"github.com/paulmach/orb/geojson"
+	"github.com/paulmach/orb/maptile"
+)
+
+func TestStatsCollector(t *testing.T) {
+	feature := func(id int64, geometry orb.Geometry, properties map[string]interface{}) *geojson.Feature {
+		f := geojson.NewFeature(geometry)
+		f.ID = id
+		f.Properties = properties
+		return f
+	}
+
+	collector := newStatsCollector()
+	for zoom := 12; zoom <= 13; zoom++ {
+		// The same features in the next tile are not counted again
+		collector.add(&mvt.Layer{Name: "points", Features: []*geojson.Feature{
+			feature(1, orb.Point{1, 1}, map[string]interface{}{"amenity": "cafe", "ele": 1200.5}),
+			feature(2, orb.Point{1, 2}, map[string]interface{}{"amenity": "bar", "ele": 900, "open": true}),
+			feature(3, orb.Point{1, 3}, map[string]interface{}{"amenity": "cafe", "open": "yes"}),
+		}}, maptile.New(0, 0, maptile.Zoom(zoom)))
+	}
+	collector.add(&mvt.Layer{Name: "lines", Features: []*geojson.Feature{
+		feature(1, orb.LineString{{0, 0}, {1, 1}}, map[string]interface{}{}),
+		feature(2, orb.MultiLineString{{{0, 0}, {1, 1}}}, map[string]interface{}{}),
+		feature(3, orb.Point{0, 0}, map[string]interface{}{}),
+	}}, maptile.New(0, 0, 14))
+
+	got := collector.tileStats()
+	want := TileStats{
+		LayerCount: 2,
+		Layers: []LayerStat{
+			{Layer: "lines", Count: 3, Geometry: "LineString", Attributes: []Attribute{}},
+			{Layer: "points", Count: 3, Geometry: "Point", AttributeCount: 3, Attributes: []Attribute{
+				{Attribute: "amenity", Count: 3, Type: "string", Values: []interface{}{"cafe", "bar"}},
+				{Attribute: "ele", Count: 2, Type: "number", Values: []interface{}{1200.5, 900}, Min: 900.0, Max: 1200.5},
+				{Attribute: "open", Count: 2, Type: "mixed", Values: []interface{}{true, "yes"}},
+			}},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tileStats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestStatsCollector_VectorLayers(t *testing.T) {
+	collector := newStatsCollector()
+	for zoom := 10; zoom <= 14; zoom++ {
+		feature := geojson.NewFeature(orb.Point{1, 1})
+		feature.ID = zoom
+		feature.Properties = map[string]interface{}{"name": "Cafe", "ele": 1200, "open": true, "mixed": "yes"}
+		if zoom == 14 {
+			feature.Properties["mixed"] = 1
+		}
+		collector.add(&mvt.Layer{Name: pointsLayer, Features: []*geojson.Feature{feature}}, maptile.New(0, 0, maptile.Zoom(zoom)))
+	}
+
+	got := collector.vectorLayers()
+	want := []VectorLayer{{
+		ID:          pointsLayer,
+		Description: layerDescriptions[pointsLayer],
+		MinZoom:     10,
+		MaxZoom:     14,
+		Fields:      map[string]string{"name": "String", "ele": "Number", "open": "Boolean", "mixed": "String"},
+	}}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("vectorLayers() = %+v, want %+v", got, want)
+	}
+}
+
+func TestStatsCollector_withoutIDs(t *testing.T) {
+	collector := newStatsCollector()
+	water := orb.MultiPolygon{
+		{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+		{{{2, 2}, {3, 2}, {3, 3}, {2, 2}}},
+	}
+
+	// Water polygons have no ids and are counted in every tile
+	collector.add(&mvt.Layer{Name: oceanLayer, Features: waterFeatures(water)}, maptile.New(0, 0, 1))
+	collector.add(&mvt.Layer{Name: oceanLayer, Features: waterFeatures(water[:1])}, maptile.New(1, 0, 1))
+
+	stats := collector.tileStats()
+	if len(stats.Layers) != 1 || stats.Layers[0].Count != 3 || stats.Layers[0].Geometry != "Polygon" {
+		t.Errorf("tileStats() = %+v, want 3 polygons of ocean", stats)
+	}
+}
+
+func TestStatsCollector_apply(t *testing.T) {
+	feature := func(geometry orb.Geometry, properties map[string]interface{}) *geojson.Feature {
+		f := geojson.NewFeature(geometry)
+		f.Properties = properties
+		return f
+	}
+	cafe := feature(orb.Point{1, 1}, map[string]interface{}{"amenity": "cafe", "ele": 1200.0})
+	bar := feature(orb.Point{1, 2}, map[string]interface{}{"amenity": "bar", "open": true})
+	shop := feature(orb.Point{1, 3}, map[string]interface{}{"shop": "bakery"})
+
+	written := newStatsCollector()
+	cafe.ID, bar.ID = 11, 21
+	written.add(&mvt.Layer{Name: pointsLayer, Features: []*geojson.Feature{cafe, bar}}, maptile.New(0, 0, 14))
+	raw, err := metadataJSON(written)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := statsFromMetadata(string(raw))
+	if err != nil {
+		t.Fatalf("statsFromMetadata() error = %v", err)
+	}
+	// Cafe is in the tile before and after update, bar is deleted and shop is created
+	delta := make(statsDelta)
+	delta.remove(pointsLayer, int64(11), cafe)
+	delta.remove(pointsLayer, int64(21), bar)
+	delta.add(pointsLayer, 12, int64(11), cafe)
+	delta.add(pointsLayer, 12, int64(31), shop)
+	stats.apply(delta)
+
+	got := stats.tileStats()
+	if len(got.Layers) != 1 || got.Layers[0].Count != 2 {
+		t.Fatalf("tileStats() = %+v, want 2 points", got)
+	}
+	counts := make(map[string]int)
+	for _, attribute := range got.Layers[0].Attributes {
+		counts[attribute.Attribute] = attribute.Count
+	}
+	if want := map[string]int{"amenity": 1, "ele": 1, "shop": 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("attribute counts = %v, want %v", counts, want)
+	}
+	if layers := stats.vectorLayers(); layers[0].MinZoom != 12 || layers[0].MaxZoom != 14 {
+		t.Errorf("vectorLayers() zooms = %d-%d, want 12-14", layers[0].MinZoom, layers[0].MaxZoom)
+	}
+}
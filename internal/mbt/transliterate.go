@@ -0,0 +1,191 @@
+package mbt
+
+import (
+	"strings"
+	"unicode"
+)
+
+type script int
+
+const (
+	scriptOther script = iota
+	scriptLatin
+	scriptCyrillic
+)
+
+// cyrillicLanguages Languages written in cyrillic, other known languages of
+// latinLanguages are written in latin
+var cyrillicLanguages = map[string]bool{
+	"ru": true, "uk": true, "be": true, "bg": true, "sr": true, "mk": true,
+	"kk": true, "ky": true, "tg": true, "mn": true, "ba": true, "tt": true,
+}
+
+var latinLanguages = map[string]bool{
+	"en": true, "de": true, "fr": true, "es": true, "it": true, "pt": true,
+	"ca": true, "nl": true, "pl": true, "cs": true, "sk": true, "sl": true,
+	"hr": true, "bs": true, "ro": true, "hu": true, "fi": true, "sv": true,
+	"no": true, "nb": true, "nn": true, "da": true, "et": true, "lv": true,
+	"lt": true, "tr": true, "az": true, "uz": true, "vi": true, "id": true,
+	"ms": true, "sq": true, "eu": true, "gl": true, "ga": true, "cy": true,
+	"is": true, "mt": true, "oc": true,
+}
+
+// cyrillicToLatin Scientific style transliteration of russian, ukrainian,
+// belarusian and serbian letters
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "",
+	'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "w",
+	'ђ': "dj", 'ј': "j", 'љ': "lj", 'њ': "nj", 'ћ': "c", 'џ': "dz",
+}
+
+// latinToCyrillic Russian reading of latin letters, digraphs are matched first
+var latinToCyrillic = []struct {
+	latin    string
+	cyrillic string
+}{
+	{"shch", "щ"}, {"sch", "ш"}, {"sh", "ш"}, {"ch", "ч"}, {"zh", "ж"}, {"kh", "х"},
+	{"ts", "ц"}, {"yu", "ю"}, {"ya", "я"}, {"yo", "ё"}, {"ph", "ф"}, {"th", "т"},
+	{"a", "а"}, {"b", "б"}, {"c", "к"}, {"d", "д"}, {"e", "е"}, {"f", "ф"},
+	{"g", "г"}, {"h", "х"}, {"i", "и"}, {"j", "дж"}, {"k", "к"}, {"l", "л"},
+	{"m", "м"}, {"n", "н"}, {"o", "о"}, {"p", "п"}, {"q", "к"}, {"r", "р"},
+	{"s", "с"}, {"t", "т"}, {"u", "у"}, {"v", "в"}, {"w", "в"}, {"x", "кс"},
+	{"y", "и"}, {"z", "з"},
+}
+
+// latinBase Letters with diacritics of latin languages by base letter
+var latinBase = map[rune]rune{
+	'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'å': 'a', 'ą': 'a',
+	'ç': 'c', 'ć': 'c', 'č': 'c', 'ď': 'd', 'đ': 'd',
+	'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e', 'ę': 'e', 'ě': 'e',
+	'ì': 'i', 'í': 'i', 'î': 'i', 'ï': 'i', 'ł': 'l', 'ľ': 'l',
+	'ñ': 'n', 'ń': 'n', 'ň': 'n', 'ò': 'o', 'ó': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o', 'ø': 'o',
+	'ŕ': 'r', 'ř': 'r', 'ś': 's', 'š': 's', 'ș': 's', 'ş': 's', 'ť': 't', 'ț': 't',
+	'ù': 'u', 'ú': 'u', 'û': 'u', 'ü': 'u', 'ů': 'u', 'ý': 'y', 'ÿ': 'y',
+	'ź': 'z', 'ż': 'z', 'ž': 'z',
+}
+
+// languageScript script of language code, regional suffixes are ignored
+func languageScript(code string) script {
+	base, _, _ := strings.Cut(code, "-")
+
+	switch {
+	case cyrillicLanguages[base]:
+		return scriptCyrillic
+	case latinLanguages[base]:
+		return scriptLatin
+	default:
+		return scriptOther
+	}
+}
+
+// textScript script of most letters of text
+func textScript(text string) script {
+	latin, cyrillic := 0, 0
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		}
+	}
+
+	switch {
+	case cyrillic > latin:
+		return scriptCyrillic
+	case latin > 0:
+		return scriptLatin
+	default:
+		return scriptOther
+	}
+}
+
+// transliterate text to script, text in the same or unknown script is not changed
+func transliterate(text string, target script) string {
+	source := textScript(text)
+	switch {
+	case source == scriptCyrillic && target == scriptLatin:
+		return fromCyrillic(text)
+	case source == scriptLatin && target == scriptCyrillic:
+		return toCyrillic(text)
+	default:
+		return text
+	}
+}
+
+func fromCyrillic(text string) string {
+	var result strings.Builder
+	for _, r := range text {
+		latin, ok := cyrillicToLatin[unicode.ToLower(r)]
+		if !ok {
+			result.WriteRune(r)
+			continue
+		}
+
+		if unicode.IsUpper(r) {
+			latin = capitalize(latin)
+		}
+		result.WriteString(latin)
+	}
+
+	return result.String()
+}
+
+func toCyrillic(text string) string {
+	runes := []rune(text)
+	var result strings.Builder
+
+	for i := 0; i < len(runes); {
+		matched := false
+		for _, pair := range latinToCyrillic {
+			size := len(pair.latin)
+			if i+size > len(runes) || foldLatin(runes[i:i+size]) != pair.latin {
+				continue
+			}
+
+			cyrillic := pair.cyrillic
+			if unicode.IsUpper(runes[i]) {
+				cyrillic = capitalize(cyrillic)
+			}
+			result.WriteString(cyrillic)
+			i += size
+			matched = true
+			break
+		}
+
+		if !matched {
+			result.WriteRune(runes[i])
+			i++
+		}
+	}
+
+	return result.String()
+}
+
+// foldLatin lower case letters without diacritics
+func foldLatin(runes []rune) string {
+	folded := make([]rune, len(runes))
+	for i, r := range runes {
+		r = unicode.ToLower(r)
+		if base, ok := latinBase[r]; ok {
+			r = base
+		}
+		folded[i] = r
+	}
+
+	return string(folded)
+}
+
+func capitalize(text string) string {
+	runes := []rune(text)
+	if len(runes) == 0 {
+		return text
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+
+	return string(runes)
+}
@@ -0,0 +1,531 @@
+package mbt
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+	"github.com/paulmach/orb/maptile"
+	"github.com/your-map/mbtiles-tool/internal/osm"
+)
+
+// Metadata keys of replication state, values are taken from the osm header
+// and updated after applying of change files
+const (
+	ReplicationTimestampKey = "replication_timestamp"
+	ReplicationSequenceKey  = "replication_sequence"
+	ReplicationBaseURLKey   = "replication_base_url"
+)
+
+// ChangeStats Count of applied elements of change file
+type ChangeStats struct {
+	Nodes     int `json:"nodes"`
+	Ways      int `json:"ways"`
+	Relations int `json:"relations"`
+}
+
+// UpdateStats Result of re-rendering of tiles touched by changes
+type UpdateStats struct {
+	TilesChecked   int `json:"tiles_checked"`
+	TilesWritten   int `json:"tiles_written"`
+	TilesDeleted   int `json:"tiles_deleted"`
+	TilesUnchanged int `json:"tiles_unchanged"`
+}
+
+type tileKey struct {
+	z, x, y int
+}
+
+// Apply apply osm change to cached data and remember bounds of old and new
+// geometry of changed elements, relations are counted only because they are not rendered
+func (m *MBT) Apply(change *osm.OsmChange) ChangeStats {
+	if m.nodeWays == nil {
+		m.reconstructWayGeometry()
+		m.indexNodeWays()
+		m.vertices = make(map[int64]*PointData)
+	}
+
+	stats := ChangeStats{}
+	touchedWays := make(map[int64]bool)
+
+	for _, element := range change.Changes {
+		switch {
+		case element.Node != nil:
+			stats.Nodes++
+			m.applyNode(element.Action, element.Node, touchedWays)
+		case element.Way != nil:
+			stats.Ways++
+			m.applyWay(element.Action, element.Way, touchedWays)
+		case element.Relation != nil:
+			stats.Relations++
+		}
+	}
+
+	for id := range touchedWays {
+		if way, ok := m.waysCache[id]; ok {
+			m.reconstructWay(way)
+			m.markWay(way)
+		}
+	}
+
+	// Changed ways may be coastlines, water is assembled again before rendering
+	if m.ocean.Coastline && (stats.Ways > 0 || len(touchedWays) > 0) {
+		m.oceanStale = true
+	}
+
+	return stats
+}
+
+func (m *MBT) applyNode(action osm.Action, node *osm.Node, touchedWays map[int64]bool) {
+	old, exists := m.nodesCache[node.ID]
+	if exists {
+		m.markPoint(old)
+	}
+
+	// Ways are marked with old position of node before it is moved
+	for _, wayID := range m.nodeWays[node.ID] {
+		if way, ok := m.waysCache[wayID]; ok {
+			m.markWay(way)
+		}
+		touchedWays[wayID] = true
+	}
+
+	if action == osm.Delete {
+		delete(m.nodesCache, node.ID)
+		delete(m.vertices, node.ID)
+		return
+	}
+
+	// With locations on ways only nodes with tags are cached, vertices are
+	// moved on the ways referencing them
+	if m.locationsOnWays {
+		m.moveVertex(node)
+		if len(node.Tags) == 0 {
+			delete(m.nodesCache, node.ID)
+			return
+		}
+	}
+
+	if exists {
+		// Ways keep pointers to node, so it is changed in place
+		old.Lat, old.Lon, old.Tags = node.Lat, node.Lon, node.Tags
+	} else {
+		old = &PointData{ID: node.ID, Lat: node.Lat, Lon: node.Lon, Tags: node.Tags}
+		m.nodesCache[node.ID] = old
+	}
+
+	m.markPoint(old)
+}
+
+// moveVertex set location of node on ways referencing it, the location is
+// kept for ways created or changed later
+func (m *MBT) moveVertex(node *osm.Node) {
+	vertex := &PointData{ID: node.ID, Lat: node.Lat, Lon: node.Lon}
+	m.vertices[node.ID] = vertex
+
+	for _, wayID := range m.nodeWays[node.ID] {
+		if way, ok := m.waysCache[wayID]; ok {
+			for i, ref := range way.Refs {
+				if ref == node.ID && i < len(way.Locations) {
+					way.Locations[i] = vertex
+				}
+			}
+		}
+	}
+}
+
+// wayLocations locations of nodes of changed way from changed vertices or
+// other ways with the same nodes, unknown locations are nil
+func (m *MBT) wayLocations(refs []int64) []*PointData {
+	locations := make([]*PointData, len(refs))
+	for i, ref := range refs {
+		if vertex, ok := m.vertices[ref]; ok {
+			locations[i] = vertex
+			continue
+		}
+
+		for _, wayID := range m.nodeWays[ref] {
+			if locations[i] = m.waysCache[wayID].location(ref); locations[i] != nil {
+				break
+			}
+		}
+	}
+
+	return locations
+}
+
+// location location of node on way, nil if way is nil or has no location of node
+func (w *WayData) location(ref int64) *PointData {
+	if w == nil {
+		return nil
+	}
+
+	for i, id := range w.Refs {
+		if id == ref && i < len(w.Locations) && w.Locations[i] != nil {
+			return w.Locations[i]
+		}
+	}
+
+	return nil
+}
+
+func (m *MBT) applyWay(action osm.Action, way *osm.Way, touchedWays map[int64]bool) {
+	// Locations are taken before the old way is removed from index of nodes
+	var locations []*PointData
+	if m.locationsOnWays && action != osm.Delete {
+		locations = m.wayLocations(way.Refs)
+	}
+
+	if old, exists := m.waysCache[way.ID]; exists {
+		m.markWay(old)
+		for _, ref := range old.Refs {
+			m.nodeWays[ref] = removeID(m.nodeWays[ref], way.ID)
+		}
+		delete(m.waysCache, way.ID)
+	}
+
+	if action == osm.Delete {
+		delete(touchedWays, way.ID)
+		return
+	}
+
+	m.waysCache[way.ID] = &WayData{ID: way.ID, Refs: way.Refs, Tags: way.Tags, Locations: locations}
+	for _, ref := range way.Refs {
+		m.nodeWays[ref] = append(m.nodeWays[ref], way.ID)
+	}
+	touchedWays[way.ID] = true
+}
+
+func (m *MBT) indexNodeWays() {
+	m.nodeWays = make(map[int64][]int64)
+	for _, way := range m.waysCache {
+		for _, ref := range way.Refs {
+			m.nodeWays[ref] = append(m.nodeWays[ref], way.ID)
+		}
+	}
+}
+
+func (m *MBT) markPoint(point *PointData) {
+	p := orb.Point{point.Lon, point.Lat}
+	m.dirty = append(m.dirty, orb.Bound{Min: p, Max: p})
+}
+
+func (m *MBT) markWay(way *WayData) {
+	if bound, ok := way.bound(); ok {
+		m.dirty = append(m.dirty, bound)
+	}
+}
+
+// UpdateTiles render again tiles touched by applied changes, only tiles
+// with other content are written and tiles without features are deleted.
+// Json, bounds and center of metadata are refreshed when tiles are changed
+func (m *MBT) UpdateTiles() (*UpdateStats, error) {
+	if err := m.refreshOcean(); err != nil {
+		return nil, err
+	}
+
+	tiles := make(map[tileKey]bool)
+	for zoom := minZoom; zoom <= maxZoom; zoom++ {
+		for _, bound := range m.dirty {
+			minX, minY, maxX, maxY := tileRange(bound, zoom)
+			for x := minX; x <= maxX; x++ {
+				for y := minY; y <= maxY; y++ {
+					tiles[tileKey{zoom, x, y}] = true
+				}
+			}
+		}
+	}
+
+	keys := make([]tileKey, 0, len(tiles))
+	for key := range tiles {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.z != b.z {
+			return a.z < b.z
+		}
+		if a.x != b.x {
+			return a.x < b.x
+		}
+		return a.y < b.y
+	})
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &UpdateStats{}
+	delta := make(statsDelta)
+	for _, key := range keys {
+		if err = m.updateTile(tx, key, stats, delta); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if stats.TilesWritten > 0 || stats.TilesDeleted > 0 {
+		if err = m.updateMetadata(tx, delta); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	m.dirty = nil
+
+	return stats, nil
+}
+
+// updateTile render tile again and write it if content is changed, features
+// of changed content are collected to delta
+func (m *MBT) updateTile(tx *sql.Tx, key tileKey, stats *UpdateStats, delta statsDelta) error {
+	stats.TilesChecked++
+
+	data, err := m.renderTile(key.z, key.x, key.y)
+	if err != nil {
+		return err
+	}
+
+	row := FlipY(key.z, key.y)
+
+	var existing []byte
+	err = tx.QueryRow(
+		"SELECT tile_data FROM tiles WHERE zoom_level = ? AND tile_column = ? AND tile_row = ?",
+		key.z, key.x, row,
+	).Scan(&existing)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if bytes.Equal(existing, data) {
+		stats.TilesUnchanged++
+		return nil
+	}
+
+	if err = m.collectDelta(delta, key, existing, data); err != nil {
+		return err
+	}
+
+	switch {
+	case len(data) == 0:
+		stats.TilesDeleted++
+		_, err = tx.Exec("DELETE FROM tiles WHERE zoom_level = ? AND tile_column = ? AND tile_row = ?", key.z, key.x, row)
+	default:
+		stats.TilesWritten++
+		_, err = tx.Exec(
+			"INSERT OR REPLACE INTO tiles (zoom_level, tile_column, tile_row, tile_data) VALUES (?, ?, ?, ?)",
+			key.z, key.x, row, data,
+		)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update tile %d/%d/%d: %w", key.z, key.x, key.y, err)
+	}
+
+	return nil
+}
+
+// collectDelta collect features of tile content before and after update.
+// Features recognized only by attributes are compared by geometry too, the
+// same features before and after update are not collected. Other features
+// with equal attributes are numbered in tile, so they are counted as many
+// times as they are found in one tile
+func (m *MBT) collectDelta(delta statsDelta, key tileKey, existing, data []byte) error {
+	tile := maptile.New(uint32(key.x), uint32(key.y), maptile.Zoom(key.z))
+
+	before, err := m.decodeKeyedFeatures(existing, tile)
+	if err != nil {
+		return err
+	}
+	after, err := m.decodeKeyedFeatures(data, tile)
+	if err != nil {
+		return err
+	}
+
+	for name, features := range before {
+		written := make(map[string][]*keyedFeature)
+		for _, feature := range after[name] {
+			if feature.unnamed() {
+				written[feature.content()] = append(written[feature.content()], feature)
+			}
+		}
+
+		for _, feature := range features {
+			content := feature.content()
+			if same := written[content]; feature.unnamed() && len(same) > 0 {
+				same[0].unchanged = true
+				feature.unchanged = true
+				written[content] = same[1:]
+			}
+		}
+	}
+
+	for name, features := range before {
+		for _, feature := range numberUnnamed(features) {
+			delta.remove(name, feature.key, feature.feature)
+		}
+	}
+	for name, features := range after {
+		for _, feature := range numberUnnamed(features) {
+			delta.add(name, key.z, feature.key, feature.feature)
+		}
+	}
+
+	return nil
+}
+
+// numberUnnamed changed features of tile, features recognized only by
+// attributes get the number of feature with equal attributes in key
+func numberUnnamed(features []*keyedFeature) []*keyedFeature {
+	changed := make([]*keyedFeature, 0, len(features))
+	numbers := make(map[interface{}]int)
+	for _, feature := range features {
+		if feature.unchanged {
+			continue
+		}
+
+		if feature.unnamed() {
+			number := numbers[feature.key]
+			numbers[feature.key]++
+			feature.key = fmt.Sprint(feature.key, "#", number)
+		}
+		changed = append(changed, feature)
+	}
+
+	return changed
+}
+
+// keyedFeature Feature decoded from tile with key of tilestats
+type keyedFeature struct {
+	key       interface{}
+	feature   *geojson.Feature
+	unchanged bool
+}
+
+// unnamed feature is recognized only by geometry type and attributes
+func (f *keyedFeature) unnamed() bool {
+	_, ok := f.key.(string)
+	return ok
+}
+
+// content geometry and attributes of feature
+func (f *keyedFeature) content() string {
+	return fmt.Sprint(f.feature.Geometry, f.feature.Properties)
+}
+
+// decodeKeyedFeatures features of tile data by layer, empty data has no features
+func (m *MBT) decodeKeyedFeatures(data []byte, tile maptile.Tile) (map[string][]*keyedFeature, error) {
+	features := make(map[string][]*keyedFeature)
+	if len(data) == 0 {
+		return features, nil
+	}
+
+	layers, err := DecodeTile(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode tile %d/%d/%d: %w", tile.Z, tile.X, tile.Y, err)
+	}
+
+	for _, layer := range layers {
+		for i, feature := range layer.Features {
+			features[layer.Name] = append(features[layer.Name], &keyedFeature{
+				key:     m.decodedFeatureKey(layer.Name, feature, tile, i),
+				feature: feature,
+			})
+		}
+	}
+
+	return features, nil
+}
+
+// ForgetChanges forget bounds of applied changes, tiles of them are not rendered
+func (m *MBT) ForgetChanges() {
+	m.dirty = nil
+}
+
+// SetReplicationState record sequence and timestamp of the last applied change,
+// negative sequence means unknown sequence and only timestamp is written
+func (m *MBT) SetReplicationState(sequence int64, timestamp time.Time) error {
+	if sequence >= 0 {
+		if err := m.setMetadata(ReplicationSequenceKey, strconv.FormatInt(sequence, 10)); err != nil {
+			return err
+		}
+	}
+
+	if !timestamp.IsZero() {
+		return m.setMetadata(ReplicationTimestampKey, timestamp.UTC().Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+func (m *MBT) setMetadata(name, value string) error {
+	_, err := m.db.Exec("INSERT OR REPLACE INTO metadata (name, value) VALUES (?, ?)", name, value)
+	return err
+}
+
+// dataBound bound of cached nodes and geometry of ways, false if there is no
+// data. Vertices of ways are not cached for files with locations on ways
+func (m *MBT) dataBound() (orb.Bound, bool) {
+	var bound orb.Bound
+	found := false
+	extend := func(b orb.Bound) {
+		if !found {
+			bound = b
+			found = true
+			return
+		}
+		bound = bound.Union(b)
+	}
+
+	for _, point := range m.nodesCache {
+		p := orb.Point{point.Lon, point.Lat}
+		extend(orb.Bound{Min: p, Max: p})
+	}
+
+	for _, way := range m.waysCache {
+		if len(way.Nodes) > 0 {
+			extend(way.box)
+		}
+	}
+
+	return bound, found
+}
+
+// tileRange xyz tiles covering bound on zoom, tiles are expanded by one
+// because points on the tile edge belong to both neighbour tiles
+func tileRange(bound orb.Bound, zoom int) (int, int, int, int) {
+	z := maptile.Zoom(zoom)
+	topLeft := maptile.At(orb.Point{bound.Min.X(), bound.Max.Y()}, z)
+	bottomRight := maptile.At(orb.Point{bound.Max.X(), bound.Min.Y()}, z)
+
+	last := (1 << zoom) - 1
+	clamp := func(v int) int {
+		return max(0, min(last, v))
+	}
+
+	return clamp(int(topLeft.X) - 1), clamp(int(topLeft.Y) - 1), clamp(int(bottomRight.X) + 1), clamp(int(bottomRight.Y) + 1)
+}
+
+func formatReplicationTimestamp(seconds int64) string {
+	return time.Unix(seconds, 0).UTC().Format(time.RFC3339)
+}
+
+func removeID(ids []int64, id int64) []int64 {
+	result := ids[:0]
+	for _, v := range ids {
+		if v != id {
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,217 @@
+package mbt
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/maptile"
+	"github.com/your-map/mbtiles-tool/internal/osm"
+)
+
+func TestMBT_Apply_locationsOnWays(t *testing.T) {
+	m := locationsOnWaysMBT(t)
+	residential := map[string]string{"highway": "residential"}
+
+	m.Apply(&osm.OsmChange{Changes: []osm.Change{
+		{Action: osm.Modify, Node: &osm.Node{ID: 2, Lat: 42.502, Lon: 1.502}},
+		{Action: osm.Create, Node: &osm.Node{ID: 3, Lat: 42.503, Lon: 1.503}},
+		{Action: osm.Create, Way: &osm.Way{ID: 2, Refs: []int64{2, 3}, Tags: residential}},
+		{Action: osm.Modify, Node: &osm.Node{ID: 1, Lat: 42.5, Lon: 1.5, Tags: map[string]string{"barrier": "gate"}}},
+	}})
+
+	if _, ok := m.nodesCache[2]; ok {
+		t.Errorf("vertex 2 without tags is cached")
+	}
+	if _, ok := m.nodesCache[1]; !ok {
+		t.Errorf("node 1 with tags is not cached")
+	}
+
+	want := map[int64]orb.LineString{
+		1: {{1.5, 42.5}, {1.502, 42.502}},
+		2: {{1.502, 42.502}, {1.503, 42.503}},
+	}
+	for id, line := range want {
+		way := m.waysCache[id]
+		if len(way.Nodes) != len(line) {
+			t.Fatalf("way %d has %d nodes, want %d", id, len(way.Nodes), len(line))
+		}
+		for i, node := range way.Nodes {
+			if (orb.Point{node.Lon, node.Lat}) != line[i] {
+				t.Errorf("way %d node %d = %v, want %v", id, i, orb.Point{node.Lon, node.Lat}, line[i])
+			}
+		}
+	}
+}
+
+func TestMBT_UpdateTiles(t *testing.T) {
+	tests := []struct {
+		name        string
+		change      osm.Change
+		wantWritten int
+		wantDeleted int
+		// wantTiles tiles of way on maxZoom after update
+		wantTiles []orb.Point
+	}{
+		{
+			name:      "node without change",
+			change:    osm.Change{Action: osm.Modify, Node: &osm.Node{ID: 2, Lat: 42.501, Lon: 1.501}},
+			wantTiles: []orb.Point{{1.5, 42.5}},
+		},
+		{
+			name:        "moved vertex",
+			change:      osm.Change{Action: osm.Modify, Node: &osm.Node{ID: 2, Lat: 42.6, Lon: 1.6}},
+			wantWritten: 21,
+			wantTiles:   []orb.Point{{1.5, 42.5}, {1.6, 42.6}},
+		},
+		{
+			name:        "deleted way",
+			change:      osm.Change{Action: osm.Delete, Way: &osm.Way{ID: 1}},
+			wantDeleted: maxZoom - minZoom + 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := locationsOnWaysMBT(t)
+			if err := m.GenerateTiles(); err != nil {
+				t.Fatalf("GenerateTiles() error = %v", err)
+			}
+
+			m.Apply(&osm.OsmChange{Changes: []osm.Change{tt.change}})
+			got, err := m.UpdateTiles()
+			if err != nil {
+				t.Fatalf("UpdateTiles() error = %v", err)
+			}
+			if got.TilesWritten != tt.wantWritten || got.TilesDeleted != tt.wantDeleted {
+				t.Errorf("UpdateTiles() = %+v, want %d written and %d deleted", got, tt.wantWritten, tt.wantDeleted)
+			}
+
+			var tiles int
+			if err = m.db.QueryRow("SELECT COUNT(*) FROM tiles WHERE zoom_level = ?", maxZoom).Scan(&tiles); err != nil {
+				t.Fatal(err)
+			}
+			if tiles != len(tt.wantTiles) {
+				t.Errorf("%d tiles on zoom %d, want %d", tiles, maxZoom, len(tt.wantTiles))
+			}
+			for _, point := range tt.wantTiles {
+				tile := maptile.At(point, maxZoom)
+				var exists bool
+				err = m.db.QueryRow(
+					"SELECT COUNT(*) > 0 FROM tiles WHERE zoom_level = ? AND tile_column = ? AND tile_row = ?",
+					maxZoom, tile.X, FlipY(maxZoom, int(tile.Y)),
+				).Scan(&exists)
+				if err != nil || !exists {
+					t.Errorf("tile of %v does not exist, error = %v", point, err)
+				}
+			}
+		})
+	}
+}
+
+func TestMBT_UpdateTiles_metadata(t *testing.T) {
+	cafe := osm.Change{Action: osm.Create, Node: &osm.Node{ID: 3, Lat: 42.6, Lon: 1.6, Tags: map[string]string{"amenity": "cafe"}}}
+
+	tests := []struct {
+		name   string
+		change osm.Change
+		// bounds set by user after convert
+		bounds     string
+		wantCounts map[string]int
+	}{
+		{
+			name:       "moved vertex",
+			change:     osm.Change{Action: osm.Modify, Node: &osm.Node{ID: 2, Lat: 42.6, Lon: 1.6}},
+			wantCounts: map[string]int{linesLayer: 1},
+		},
+		{
+			name:       "created node",
+			change:     cafe,
+			wantCounts: map[string]int{linesLayer: 1, pointsLayer: 1},
+		},
+		{
+			name:       "bounds of user",
+			change:     cafe,
+			bounds:     "1.000000,42.000000,1.550000,42.550000",
+			wantCounts: map[string]int{linesLayer: 1, pointsLayer: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := locationsOnWaysMBT(t)
+			if err := m.GenerateTiles(); err != nil {
+				t.Fatalf("GenerateTiles() error = %v", err)
+			}
+			if err := m.FinalizeMetadata(); err != nil {
+				t.Fatalf("FinalizeMetadata() error = %v", err)
+			}
+			if tt.bounds != "" {
+				if err := m.setMetadata(BoundsKey, tt.bounds); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			m.Apply(&osm.OsmChange{Changes: []osm.Change{tt.change}})
+			if _, err := m.UpdateTiles(); err != nil {
+				t.Fatalf("UpdateTiles() error = %v", err)
+			}
+
+			metadata, err := (&Tileset{db: m.db}).Metadata()
+			if err != nil {
+				t.Fatal(err)
+			}
+			stats, err := statsFromMetadata(metadata["json"])
+			if err != nil {
+				t.Fatal(err)
+			}
+			counts := make(map[string]int)
+			for _, layer := range stats.tileStats().Layers {
+				counts[layer.Layer] = layer.Count
+			}
+			if !reflect.DeepEqual(counts, tt.wantCounts) {
+				t.Errorf("tilestats counts = %v, want %v", counts, tt.wantCounts)
+			}
+
+			if tt.bounds != "" {
+				if metadata[BoundsKey] != tt.bounds {
+					t.Errorf("bounds = %q, want bounds of user %q", metadata[BoundsKey], tt.bounds)
+				}
+				return
+			}
+
+			// Features of tiles are rounded to tile extent
+			bounds, _ := parseNumbers(metadata[BoundsKey])
+			if len(bounds) != 4 || bounds[0] > 1.5001 || bounds[1] > 42.5001 || bounds[2] < 1.5999 || bounds[3] < 42.5999 {
+				t.Errorf("bounds = %q, want bounds of way and new data", metadata[BoundsKey])
+			}
+			if metadata[ComputedBoundsKey] != metadata[BoundsKey] {
+				t.Errorf("computed bounds = %q, want %q", metadata[ComputedBoundsKey], metadata[BoundsKey])
+			}
+		})
+	}
+}
+
+func TestTileRange(t *testing.T) {
+	point := orb.Point{1.5, 42.5}
+	tile := maptile.At(point, maxZoom)
+
+	tests := []struct {
+		name  string
+		bound orb.Bound
+		zoom  int
+		want  [4]int
+	}{
+		{name: "world on zoom 0", bound: orb.Bound{Min: point, Max: point}, zoom: 0, want: [4]int{0, 0, 0, 0}},
+		{name: "neighbours of tile", bound: orb.Bound{Min: point, Max: point}, zoom: maxZoom,
+			want: [4]int{int(tile.X) - 1, int(tile.Y) - 1, int(tile.X) + 1, int(tile.Y) + 1}},
+		{name: "clamped to world", bound: orb.Bound{Min: orb.Point{-180, -85}, Max: orb.Point{180, 85}}, zoom: 2,
+			want: [4]int{0, 0, 3, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minX, minY, maxX, maxY := tileRange(tt.bound, tt.zoom)
+			if got := [4]int{minX, minY, maxX, maxY}; got != tt.want {
+				t.Errorf("tileRange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
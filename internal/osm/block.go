@@ -0,0 +1,290 @@
+package osm
+
+import (
+	"math"
+	"sort"
+
+	osmp "github.com/your-map/mbtiles-tool/internal/osm/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// granularity Default granularity of coordinates in nanodegrees
+	granularity = 100
+	// dateGranularity Default granularity of timestamps in milliseconds
+	dateGranularity = 1000
+	// maxBlockEntities Recommended limit of entities in one primitive block
+	maxBlockEntities = 8000
+)
+
+// blockBuilder Collect elements of one type and encode them as primitive block
+type blockBuilder struct {
+	strings   map[string]int
+	table     [][]byte
+	nodes     []*Node
+	ways      []*Way
+	relations []*Relation
+}
+
+func newBlockBuilder() *blockBuilder {
+	return &blockBuilder{
+		strings: map[string]int{"": 0},
+		table:   [][]byte{{}},
+	}
+}
+
+func (b *blockBuilder) len() int {
+	return len(b.nodes) + len(b.ways) + len(b.relations)
+}
+
+func (b *blockBuilder) addNode(node *Node) {
+	b.nodes = append(b.nodes, node)
+}
+
+func (b *blockBuilder) addWay(way *Way) {
+	b.ways = append(b.ways, way)
+}
+
+func (b *blockBuilder) addRelation(relation *Relation) {
+	b.relations = append(b.relations, relation)
+}
+
+// build encode collected elements, every type gets own primitive group
+func (b *blockBuilder) build() *osmp.PrimitiveBlock {
+	groups := make([]*osmp.PrimitiveGroup, 0, 3)
+
+	if len(b.nodes) > 0 {
+		groups = append(groups, &osmp.PrimitiveGroup{Dense: b.denseNodes()})
+	}
+
+	if len(b.ways) > 0 {
+		ways := make([]*osmp.Way, 0, len(b.ways))
+		for _, way := range b.ways {
+			ways = append(ways, b.way(way))
+		}
+		groups = append(groups, &osmp.PrimitiveGroup{Ways: ways})
+	}
+
+	if len(b.relations) > 0 {
+		relations := make([]*osmp.Relation, 0, len(b.relations))
+		for _, relation := range b.relations {
+			relations = append(relations, b.relation(relation))
+		}
+		groups = append(groups, &osmp.PrimitiveGroup{Relations: relations})
+	}
+
+	return &osmp.PrimitiveBlock{
+		Stringtable:     &osmp.StringTable{S: b.table},
+		Primitivegroup:  groups,
+		Granularity:     proto.Int32(granularity),
+		DateGranularity: proto.Int32(dateGranularity),
+	}
+}
+
+func (b *blockBuilder) denseNodes() *osmp.DenseNodes {
+	dense := &osmp.DenseNodes{
+		Id:  make([]int64, 0, len(b.nodes)),
+		Lat: make([]int64, 0, len(b.nodes)),
+		Lon: make([]int64, 0, len(b.nodes)),
+	}
+
+	withInfo := false
+	for _, node := range b.nodes {
+		if node.Info != nil {
+			withInfo = true
+			break
+		}
+	}
+
+	var id, lat, lon int64
+	var info denseInfoBuilder
+	for _, node := range b.nodes {
+		nodeLat, nodeLon := encodeCoordinate(node.Lat), encodeCoordinate(node.Lon)
+		dense.Id = append(dense.Id, node.ID-id)
+		dense.Lat = append(dense.Lat, nodeLat-lat)
+		dense.Lon = append(dense.Lon, nodeLon-lon)
+		id, lat, lon = node.ID, nodeLat, nodeLon
+
+		for _, key := range sortedKeys(node.Tags) {
+			dense.KeysVals = append(dense.KeysVals, int32(b.index(key)), int32(b.index(node.Tags[key])))
+		}
+		dense.KeysVals = append(dense.KeysVals, 0)
+
+		if withInfo {
+			info.add(b, node.Info)
+		}
+	}
+
+	// Nodes without tags do not need keys_vals at all
+	if len(dense.KeysVals) == len(b.nodes) {
+		dense.KeysVals = nil
+	}
+
+	if withInfo {
+		dense.Denseinfo = info.dense
+		// Visible is written only for history files with deleted nodes
+		if !info.hidden {
+			dense.Denseinfo.Visible = nil
+		}
+	}
+
+	return dense
+}
+
+func (b *blockBuilder) way(way *Way) *osmp.Way {
+	keys, vals := b.tags(way.Tags)
+
+	refs := make([]int64, 0, len(way.Refs))
+	var ref int64
+	for _, value := range way.Refs {
+		refs = append(refs, value-ref)
+		ref = value
+	}
+
+	result := &osmp.Way{
+		Id:   proto.Int64(way.ID),
+		Keys: keys,
+		Vals: vals,
+		Info: b.info(way.Info),
+		Refs: refs,
+	}
+
+	if len(way.Lats) > 0 && len(way.Lats) == len(way.Refs) && len(way.Lons) == len(way.Refs) {
+		var lat, lon int64
+		for i := range way.Lats {
+			wayLat, wayLon := encodeCoordinate(way.Lats[i]), encodeCoordinate(way.Lons[i])
+			result.Lat = append(result.Lat, wayLat-lat)
+			result.Lon = append(result.Lon, wayLon-lon)
+			lat, lon = wayLat, wayLon
+		}
+	}
+
+	return result
+}
+
+func (b *blockBuilder) relation(relation *Relation) *osmp.Relation {
+	keys, vals := b.tags(relation.Tags)
+
+	result := &osmp.Relation{
+		Id:       proto.Int64(relation.ID),
+		Keys:     keys,
+		Vals:     vals,
+		Info:     b.info(relation.Info),
+		RolesSid: make([]int32, 0, len(relation.Members)),
+		Memids:   make([]int64, 0, len(relation.Members)),
+		Types:    make([]osmp.Relation_MemberType, 0, len(relation.Members)),
+	}
+
+	var memberID int64
+	for _, member := range relation.Members {
+		result.RolesSid = append(result.RolesSid, int32(b.index(member.Role)))
+		result.Memids = append(result.Memids, member.Ref-memberID)
+		result.Types = append(result.Types, memberType(member.Type))
+		memberID = member.Ref
+	}
+
+	return result
+}
+
+func (b *blockBuilder) info(info *Info) *osmp.Info {
+	if info == nil {
+		return nil
+	}
+
+	result := &osmp.Info{
+		Version:   proto.Int32(info.Version),
+		Changeset: proto.Int64(info.Changeset),
+		Uid:       proto.Int32(info.UID),
+		UserSid:   proto.Uint32(uint32(b.index(info.User))),
+	}
+	if !info.Timestamp.IsZero() {
+		result.Timestamp = proto.Int64(info.Timestamp.UnixMilli() / dateGranularity)
+	}
+	if !info.Visible {
+		result.Visible = proto.Bool(false)
+	}
+
+	return result
+}
+
+func (b *blockBuilder) tags(tags map[string]string) ([]uint32, []uint32) {
+	keys := make([]uint32, 0, len(tags))
+	vals := make([]uint32, 0, len(tags))
+	for _, key := range sortedKeys(tags) {
+		keys = append(keys, uint32(b.index(key)))
+		vals = append(vals, uint32(b.index(tags[key])))
+	}
+
+	return keys, vals
+}
+
+// index position of string in string table, index 0 is reserved for the empty string
+func (b *blockBuilder) index(value string) int {
+	if index, ok := b.strings[value]; ok {
+		return index
+	}
+
+	index := len(b.table)
+	b.strings[value] = index
+	b.table = append(b.table, []byte(value))
+
+	return index
+}
+
+// denseInfoBuilder Delta coded info of dense nodes
+type denseInfoBuilder struct {
+	dense                             *osmp.DenseInfo
+	timestamp, changeset, uid, userID int64
+	hidden                            bool
+}
+
+func (d *denseInfoBuilder) add(b *blockBuilder, info *Info) {
+	if d.dense == nil {
+		d.dense = &osmp.DenseInfo{}
+	}
+	if info == nil {
+		info = &Info{Visible: true}
+	}
+
+	var timestamp int64
+	if !info.Timestamp.IsZero() {
+		timestamp = info.Timestamp.UnixMilli() / dateGranularity
+	}
+	userID := int64(b.index(info.User))
+
+	d.dense.Version = append(d.dense.Version, info.Version)
+	d.dense.Timestamp = append(d.dense.Timestamp, timestamp-d.timestamp)
+	d.dense.Changeset = append(d.dense.Changeset, info.Changeset-d.changeset)
+	d.dense.Uid = append(d.dense.Uid, int32(int64(info.UID)-d.uid))
+	d.dense.UserSid = append(d.dense.UserSid, int32(userID-d.userID))
+	d.dense.Visible = append(d.dense.Visible, info.Visible)
+	d.hidden = d.hidden || !info.Visible
+
+	d.timestamp, d.changeset, d.uid, d.userID = timestamp, info.Changeset, int64(info.UID), userID
+}
+
+func encodeCoordinate(value float64) int64 {
+	return int64(math.Round(value * 1e9 / granularity))
+}
+
+func memberType(memberType MemberType) osmp.Relation_MemberType {
+	switch memberType {
+	case WayMember:
+		return osmp.Relation_WAY
+	case RelationMember:
+		return osmp.Relation_RELATION
+	default:
+		return osmp.Relation_NODE
+	}
+}
+
+func sortedKeys(tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,209 @@
+package osm
+
+import (
+	"time"
+
+	osmp "github.com/your-map/mbtiles-tool/internal/osm/proto"
+)
+
+// Elements Decoded elements of primitive block
+type Elements struct {
+	Nodes     []*Node
+	Ways      []*Way
+	Relations []*Relation
+}
+
+// Decode decode elements of primitive block with delta coded ids, coordinates and refs
+func Decode(block *osmp.PrimitiveBlock) *Elements {
+	decoder := &blockDecoder{block: block, table: block.GetStringtable().GetS()}
+	elements := &Elements{}
+
+	for _, group := range block.GetPrimitivegroup() {
+		for _, node := range group.GetNodes() {
+			lat, lon := decoder.coordinates(node.GetLat(), node.GetLon())
+			elements.Nodes = append(elements.Nodes, &Node{
+				ID:   node.GetId(),
+				Lat:  lat,
+				Lon:  lon,
+				Tags: decoder.tags(node.GetKeys(), node.GetVals()),
+				Info: decoder.info(node.GetInfo()),
+			})
+		}
+
+		if dense := group.GetDense(); dense != nil {
+			elements.Nodes = append(elements.Nodes, decoder.denseNodes(dense)...)
+		}
+
+		for _, way := range group.GetWays() {
+			refs := make([]int64, len(way.GetRefs()))
+			var ref int64
+			for i, delta := range way.GetRefs() {
+				ref += delta
+				refs[i] = ref
+			}
+
+			lats, lons := decoder.locations(way)
+
+			elements.Ways = append(elements.Ways, &Way{
+				ID:   way.GetId(),
+				Refs: refs,
+				Lats: lats,
+				Lons: lons,
+				Tags: decoder.tags(way.GetKeys(), way.GetVals()),
+				Info: decoder.info(way.GetInfo()),
+			})
+		}
+
+		for _, relation := range group.GetRelations() {
+			members := make([]Member, len(relation.GetMemids()))
+			var ref int64
+			for i, delta := range relation.GetMemids() {
+				ref += delta
+				members[i] = Member{Ref: ref, Type: decodeMemberType(relation.GetTypes(), i)}
+				if i < len(relation.GetRolesSid()) {
+					members[i].Role = decoder.string(relation.GetRolesSid()[i])
+				}
+			}
+
+			elements.Relations = append(elements.Relations, &Relation{
+				ID:      relation.GetId(),
+				Members: members,
+				Tags:    decoder.tags(relation.GetKeys(), relation.GetVals()),
+				Info:    decoder.info(relation.GetInfo()),
+			})
+		}
+	}
+
+	return elements
+}
+
+type blockDecoder struct {
+	block *osmp.PrimitiveBlock
+	table [][]byte
+}
+
+func (d *blockDecoder) denseNodes(dense *osmp.DenseNodes) []*Node {
+	nodes := make([]*Node, 0, len(dense.GetId()))
+	denseInfo := dense.GetDenseinfo()
+	keysVals := dense.GetKeysVals()
+
+	var id, lat, lon, timestamp, changeset int64
+	var uid, userSid int32
+	kvIndex := 0
+
+	for i := range dense.GetId() {
+		id += dense.GetId()[i]
+		lat += dense.GetLat()[i]
+		lon += dense.GetLon()[i]
+
+		nodeLat, nodeLon := d.coordinates(lat, lon)
+		node := &Node{ID: id, Lat: nodeLat, Lon: nodeLon, Tags: make(map[string]string)}
+
+		for kvIndex+1 < len(keysVals) && keysVals[kvIndex] != 0 {
+			node.Tags[d.string(keysVals[kvIndex])] = d.string(keysVals[kvIndex+1])
+			kvIndex += 2
+		}
+		kvIndex++
+
+		if denseInfo != nil && i < len(denseInfo.GetVersion()) {
+			timestamp += denseInfo.GetTimestamp()[i]
+			changeset += denseInfo.GetChangeset()[i]
+			uid += denseInfo.GetUid()[i]
+			userSid += denseInfo.GetUserSid()[i]
+
+			node.Info = &Info{
+				Version:   denseInfo.GetVersion()[i],
+				Timestamp: d.timestamp(timestamp),
+				Changeset: changeset,
+				UID:       uid,
+				User:      d.string(userSid),
+				Visible:   i >= len(denseInfo.GetVisible()) || denseInfo.GetVisible()[i],
+			}
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	return nodes
+}
+
+// locations delta coded locations of way nodes, nil when the way has no locations
+func (d *blockDecoder) locations(way *osmp.Way) ([]float64, []float64) {
+	if len(way.GetLat()) == 0 || len(way.GetLat()) != len(way.GetRefs()) || len(way.GetLon()) != len(way.GetRefs()) {
+		return nil, nil
+	}
+
+	lats := make([]float64, len(way.GetLat()))
+	lons := make([]float64, len(way.GetLon()))
+
+	var lat, lon int64
+	for i := range way.GetLat() {
+		lat += way.GetLat()[i]
+		lon += way.GetLon()[i]
+		lats[i], lons[i] = d.coordinates(lat, lon)
+	}
+
+	return lats, lons
+}
+
+func (d *blockDecoder) info(info *osmp.Info) *Info {
+	if info == nil {
+		return nil
+	}
+
+	return &Info{
+		Version:   info.GetVersion(),
+		Timestamp: d.timestamp(info.GetTimestamp()),
+		Changeset: info.GetChangeset(),
+		UID:       info.GetUid(),
+		User:      d.string(int32(info.GetUserSid())),
+		Visible:   info.Visible == nil || info.GetVisible(),
+	}
+}
+
+func (d *blockDecoder) coordinates(lat, lon int64) (float64, float64) {
+	granularity := float64(d.block.GetGranularity())
+
+	return 1e-9 * (float64(d.block.GetLatOffset()) + granularity*float64(lat)),
+		1e-9 * (float64(d.block.GetLonOffset()) + granularity*float64(lon))
+}
+
+func (d *blockDecoder) timestamp(value int64) time.Time {
+	if value == 0 {
+		return time.Time{}
+	}
+
+	return time.UnixMilli(value * int64(d.block.GetDateGranularity())).UTC()
+}
+
+func (d *blockDecoder) tags(keys, vals []uint32) map[string]string {
+	tags := make(map[string]string, len(keys))
+	for i := 0; i < len(keys) && i < len(vals); i++ {
+		tags[d.string(int32(keys[i]))] = d.string(int32(vals[i]))
+	}
+
+	return tags
+}
+
+func (d *blockDecoder) string(index int32) string {
+	if index < 0 || int(index) >= len(d.table) {
+		return ""
+	}
+
+	return string(d.table[index])
+}
+
+func decodeMemberType(types []osmp.Relation_MemberType, i int) MemberType {
+	if i >= len(types) {
+		return NodeMember
+	}
+
+	switch types[i] {
+	case osmp.Relation_WAY:
+		return WayMember
+	case osmp.Relation_RELATION:
+		return RelationMember
+	default:
+		return NodeMember
+	}
+}
@@ -0,0 +1,52 @@
+package osm
+
+import "time"
+
+type MemberType string
+
+var (
+	NodeMember     MemberType = "node"
+	WayMember      MemberType = "way"
+	RelationMember MemberType = "relation"
+)
+
+// Info Optional metadata of element
+type Info struct {
+	Version   int32
+	Timestamp time.Time
+	Changeset int64
+	UID       int32
+	User      string
+	Visible   bool
+}
+
+type Node struct {
+	ID   int64
+	Lat  float64
+	Lon  float64
+	Tags map[string]string
+	Info *Info
+}
+
+type Way struct {
+	ID   int64
+	Refs []int64
+	// Lats and Lons locations of nodes, only for files with LocationsOnWays feature
+	Lats []float64
+	Lons []float64
+	Tags map[string]string
+	Info *Info
+}
+
+type Member struct {
+	Type MemberType
+	Ref  int64
+	Role string
+}
+
+type Relation struct {
+	ID      int64
+	Members []Member
+	Tags    map[string]string
+	Info    *Info
+}
@@ -0,0 +1,195 @@
+package osm
+
+import (
+	"io"
+	"math"
+
+	osmp "github.com/your-map/mbtiles-tool/internal/osm/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// WritingProgram Name of program in header of written extracts
+const WritingProgram = "mbtiles-tool"
+
+// ExtractResult Count of written elements of extract
+type ExtractResult struct {
+	Nodes     int `json:"nodes"`
+	Ways      int `json:"ways"`
+	Relations int `json:"relations"`
+}
+
+// Pass read the source again and decode every primitive block, only blocks
+// with elements of types may be read, nil types means all blocks
+type Pass func(types []MemberType, onBlock func(elements *Elements), onHeader func(header *osmp.HeaderBlock)) error
+
+// Extract Elements kept from source read in several passes, ways are written
+// with all nodes and relations with all members, so geometry of written
+// ways and relations is complete
+type Extract struct {
+	Pass        Pass
+	Compression Compression
+
+	nodes     map[int64]bool
+	ways      map[int64]bool
+	relations map[int64]bool
+}
+
+func NewExtract(pass Pass, compression Compression) *Extract {
+	return &Extract{
+		Pass:        pass,
+		Compression: compression,
+		nodes:       make(map[int64]bool),
+		ways:        make(map[int64]bool),
+		relations:   make(map[int64]bool),
+	}
+}
+
+// KeepNode keep node by id
+func (e *Extract) KeepNode(id int64) {
+	e.nodes[id] = true
+}
+
+// KeepWay keep way with all its nodes
+func (e *Extract) KeepWay(way *Way) {
+	e.ways[way.ID] = true
+	for _, ref := range way.Refs {
+		e.nodes[ref] = true
+	}
+}
+
+// KeepRelation keep relation by id, members are kept by CompleteRelations
+func (e *Extract) KeepRelation(id int64) {
+	e.relations[id] = true
+}
+
+// Kept element of member is kept
+func (e *Extract) Kept(member Member) bool {
+	switch member.Type {
+	case NodeMember:
+		return e.nodes[member.Ref]
+	case WayMember:
+		return e.ways[member.Ref]
+	default:
+		return e.relations[member.Ref]
+	}
+}
+
+// CompleteRelations keep members of kept relations by members of all relations,
+// nested relations are resolved until nothing is added
+func (e *Extract) CompleteRelations(members map[int64][]Member) {
+	queue := make([]int64, 0, len(e.relations))
+	for id := range e.relations {
+		queue = append(queue, id)
+	}
+
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		for _, member := range members[id] {
+			switch member.Type {
+			case NodeMember:
+				e.nodes[member.Ref] = true
+			case WayMember:
+				e.ways[member.Ref] = true
+			case RelationMember:
+				if _, exists := members[member.Ref]; exists && !e.relations[member.Ref] {
+					e.relations[member.Ref] = true
+					queue = append(queue, member.Ref)
+				}
+			}
+		}
+	}
+}
+
+// CollectWayNodes keep nodes of ways kept only as members of relations
+func (e *Extract) CollectWayNodes() error {
+	return e.Pass([]MemberType{WayMember}, func(elements *Elements) {
+		for _, way := range elements.Ways {
+			if e.ways[way.ID] {
+				e.KeepWay(way)
+			}
+		}
+	}, nil)
+}
+
+// BBox bbox of header from locations of kept nodes, nil if no kept node is found
+func (e *Extract) BBox() (*osmp.HeaderBBox, error) {
+	minLon, minLat := math.Inf(1), math.Inf(1)
+	maxLon, maxLat := math.Inf(-1), math.Inf(-1)
+
+	err := e.Pass([]MemberType{NodeMember}, func(elements *Elements) {
+		for _, node := range elements.Nodes {
+			if e.nodes[node.ID] {
+				minLon, maxLon = min(minLon, node.Lon), max(maxLon, node.Lon)
+				minLat, maxLat = min(minLat, node.Lat), max(maxLat, node.Lat)
+			}
+		}
+	}, nil)
+	if err != nil || minLon > maxLon {
+		return nil, err
+	}
+
+	// Bbox of header is in nanodegrees
+	return &osmp.HeaderBBox{
+		Left:   proto.Int64(int64(math.Round(minLon * 1e9))),
+		Right:  proto.Int64(int64(math.Round(maxLon * 1e9))),
+		Top:    proto.Int64(int64(math.Round(maxLat * 1e9))),
+		Bottom: proto.Int64(int64(math.Round(minLat * 1e9))),
+	}, nil
+}
+
+// Write write kept elements and nodes matched by keepNode, which may be nil.
+// Header of source gets the writing program and is changed by header if it is not nil
+func (e *Extract) Write(w io.Writer, keepNode func(node *Node) bool, header func(header *osmp.HeaderBlock)) (*ExtractResult, error) {
+	writer := NewWriter(w, e.Compression)
+	result := &ExtractResult{}
+
+	var writeErr error
+	err := e.Pass(nil, func(elements *Elements) {
+		for _, node := range elements.Nodes {
+			if writeErr == nil && (e.nodes[node.ID] || (keepNode != nil && keepNode(node))) {
+				writeErr = writer.WriteNode(node)
+				result.Nodes++
+			}
+		}
+
+		for _, way := range elements.Ways {
+			if writeErr == nil && e.ways[way.ID] {
+				writeErr = writer.WriteWay(way)
+				result.Ways++
+			}
+		}
+
+		for _, relation := range elements.Relations {
+			if writeErr == nil && e.relations[relation.ID] {
+				writeErr = writer.WriteRelation(relation)
+				result.Relations++
+			}
+		}
+	}, func(source *osmp.HeaderBlock) {
+		if writeErr != nil {
+			return
+		}
+
+		written := proto.Clone(source).(*osmp.HeaderBlock)
+		written.Writingprogram = proto.String(WritingProgram)
+		if header != nil {
+			header(written)
+		}
+		writeErr = writer.WriteHeader(written)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if writeErr != nil {
+		return nil, writeErr
+	}
+
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
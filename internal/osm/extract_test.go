@@ -0,0 +1,44 @@
+package osm
+
+import (
+	"testing"
+
+	osmp "github.com/your-map/mbtiles-tool/internal/osm/proto"
+)
+
+func TestExtract_BBox(t *testing.T) {
+	elements := testElements()
+	pass := func(_ []MemberType, onBlock func(elements *Elements), _ func(header *osmp.HeaderBlock)) error {
+		onBlock(elements)
+		return nil
+	}
+
+	tests := []struct {
+		name  string
+		keep  func(e *Extract)
+		want  bool
+		left  int64
+		right int64
+	}{
+		{name: "nothing kept", keep: func(e *Extract) {}},
+		{name: "node", keep: func(e *Extract) { e.KeepNode(1) }, want: true, left: 1521800000, right: 1521800000},
+		{name: "way", keep: func(e *Extract) { e.KeepWay(elements.Ways[0]) }, want: true, left: -179999999900, right: 151209300000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewExtract(pass, CompressionNone)
+			tt.keep(e)
+
+			got, err := e.BBox()
+			if err != nil {
+				t.Fatalf("BBox() error = %v", err)
+			}
+			if (got != nil) != tt.want {
+				t.Fatalf("BBox() = %v, want bbox %v", got, tt.want)
+			}
+			if got != nil && (got.GetLeft() != tt.left || got.GetRight() != tt.right) {
+				t.Errorf("BBox() left, right = %d, %d, want %d, %d", got.GetLeft(), got.GetRight(), tt.left, tt.right)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package osm
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	osmp "github.com/your-map/mbtiles-tool/internal/osm/proto"
+)
+
+// Features of header block of pbf file
+const (
+	FeatureOsmSchema       = "OsmSchema-V0.6"
+	FeatureDenseNodes      = "DenseNodes"
+	FeatureLocationsOnWays = "LocationsOnWays"
+	FeatureHistorical      = "HistoricalInformation"
+)
+
+var ErrUnsupportedFeature = errors.New("unsupported required feature")
+
+// supportedFeatures Required features the reader can decode
+var supportedFeatures = []string{FeatureOsmSchema, FeatureDenseNodes}
+
+// CheckRequiredFeatures check that all required features of header are supported
+func CheckRequiredFeatures(header *osmp.HeaderBlock) error {
+	for _, feature := range header.GetRequiredFeatures() {
+		if feature == FeatureHistorical {
+			return fmt.Errorf("%w: %s, history files are not supported", ErrUnsupportedFeature, feature)
+		}
+		if !slices.Contains(supportedFeatures, feature) {
+			return fmt.Errorf("%w: %s", ErrUnsupportedFeature, feature)
+		}
+	}
+
+	return nil
+}
+
+// HasOptionalFeature check optional feature of header
+func HasOptionalFeature(header *osmp.HeaderBlock, feature string) bool {
+	return slices.Contains(header.GetOptionalFeatures(), feature)
+}
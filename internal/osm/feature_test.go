@@ -0,0 +1,88 @@
+package osm
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	osmp "github.com/your-map/mbtiles-tool/internal/osm/proto"
+)
+
+func TestCheckRequiredFeatures(t *testing.T) {
+	tests := []struct {
+		name     string
+		features []string
+		wantErr  bool
+	}{
+		{name: "supported", features: []string{FeatureOsmSchema, FeatureDenseNodes}},
+		{name: "without features"},
+		{name: "history", features: []string{FeatureOsmSchema, FeatureHistorical}, wantErr: true},
+		{name: "unknown", features: []string{FeatureOsmSchema, "Sort.Type_then_ID"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckRequiredFeatures(&osmp.HeaderBlock{RequiredFeatures: tt.features})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckRequiredFeatures() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOSM_ReadUnsupportedFeature(t *testing.T) {
+	buf := new(bytes.Buffer)
+	writer := NewWriter(buf, CompressionZlib)
+	if err := writer.WriteHeader(&osmp.HeaderBlock{RequiredFeatures: []string{FeatureOsmSchema, FeatureHistorical}}); err != nil {
+		t.Fatalf("WriteHeader() error = %v", err)
+	}
+	if err := writer.WriteNode(&Node{ID: 1, Lat: 42.5, Lon: 1.5}); err != nil {
+		t.Fatalf("WriteNode() error = %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	reader := NewOSM(buf)
+	dataChan, err := reader.Read()
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	count := 0
+	for range dataChan {
+		count++
+	}
+
+	if count != 0 {
+		t.Errorf("Read() sent %d blocks of unsupported file", count)
+	}
+	if !errors.Is(reader.Err(), ErrUnsupportedFeature) {
+		t.Errorf("Err() = %v, want %v", reader.Err(), ErrUnsupportedFeature)
+	}
+}
+
+func TestOSM_ReadTruncated(t *testing.T) {
+	buf := new(bytes.Buffer)
+	writer := NewWriter(buf, CompressionZlib)
+	if err := writer.WriteHeader(&osmp.HeaderBlock{RequiredFeatures: []string{FeatureOsmSchema}}); err != nil {
+		t.Fatalf("WriteHeader() error = %v", err)
+	}
+	if err := writer.WriteNode(&Node{ID: 1, Lat: 42.5, Lon: 1.5}); err != nil {
+		t.Fatalf("WriteNode() error = %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	reader := NewOSM(bytes.NewReader(buf.Bytes()[:buf.Len()-10]))
+	dataChan, err := reader.Read()
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	for range dataChan {
+	}
+
+	if reader.Err() == nil {
+		t.Error("Err() = nil for truncated file")
+	}
+}
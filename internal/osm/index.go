@@ -0,0 +1,448 @@
+package osm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	osmp "github.com/your-map/mbtiles-tool/internal/osm/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// IndexFileExt Extension of blob index cached beside pbf file
+const IndexFileExt = ".idx"
+
+// indexVersion Version of cached index, cache of other version is built again
+const indexVersion = 1
+
+// BlobInfo Position and content of one blob of pbf file
+type BlobInfo struct {
+	// Offset of the blob header size from start of file
+	Offset int64 `json:"offset"`
+	// Size of blob header size, blob header and blob
+	Size int64      `json:"size"`
+	Type HeaderType `json:"type"`
+	// Indexdata of blob header, not used by known writers
+	Indexdata []byte `json:"indexdata,omitempty"`
+
+	Nodes     int `json:"nodes,omitempty"`
+	Ways      int `json:"ways,omitempty"`
+	Relations int `json:"relations,omitempty"`
+	// MinID and MaxID range of ids of all elements of blob
+	MinID int64 `json:"min_id,omitempty"`
+	MaxID int64 `json:"max_id,omitempty"`
+	// Bbox of nodes of blob: minlon, minlat, maxlon, maxlat
+	Bbox []float64 `json:"bbox,omitempty"`
+}
+
+// Has check that blob contains elements of type
+func (b BlobInfo) Has(elementType MemberType) bool {
+	switch elementType {
+	case NodeMember:
+		return b.Nodes > 0
+	case WayMember:
+		return b.Ways > 0
+	default:
+		return b.Relations > 0
+	}
+}
+
+// Index Blobs of pbf file, size and modification time of file check the cached index
+type Index struct {
+	Version int        `json:"version"`
+	Size    int64      `json:"size"`
+	ModTime time.Time  `json:"mod_time"`
+	Blobs   []BlobInfo `json:"blobs"`
+}
+
+// BuildIndex scan pbf file once and decode every blob for ids and bbox
+func BuildIndex(r io.Reader) (*Index, error) {
+	o := NewOSM(r)
+	index := &Index{Version: indexVersion}
+	buffer := new(bytes.Buffer)
+
+	var offset int64
+	for {
+		headerSize, err := o.headerSize(buffer)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		header, err := o.header(buffer, headerSize)
+		if err != nil {
+			return nil, err
+		}
+
+		blob, err := o.blob(buffer, header)
+		if err != nil {
+			return nil, err
+		}
+
+		info := BlobInfo{
+			Offset:    offset,
+			Size:      sizeReadData + headerSize + int64(header.GetDatasize()),
+			Type:      HeaderType(header.GetType()),
+			Indexdata: header.GetIndexdata(),
+		}
+		offset += info.Size
+
+		if info.Type == BlobData {
+			data, err := blobData(blob)
+			if err != nil {
+				return nil, err
+			}
+
+			block := &osmp.PrimitiveBlock{}
+			if err = proto.Unmarshal(data, block); err != nil {
+				return nil, err
+			}
+			info.summarize(block)
+		}
+
+		index.Blobs = append(index.Blobs, info)
+	}
+
+	return index, nil
+}
+
+// ScanBlobs positions of blobs from their headers, data of blobs is skipped,
+// so blobs have no counts, ids and bbox like blobs of index
+func ScanBlobs(r io.ReadSeeker) ([]BlobInfo, error) {
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	o := NewOSM(r)
+	buffer := new(bytes.Buffer)
+	blobs := make([]BlobInfo, 0)
+
+	for offset := int64(0); offset < size; {
+		info, err := o.blobInfo(buffer, offset)
+		if err == nil && offset+info.Size > size {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, fmt.Errorf("blob at %d: %w", offset, err)
+		}
+		blobs = append(blobs, info)
+
+		if offset, err = r.Seek(offset+info.Size, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+
+	return blobs, nil
+}
+
+// blobInfo position and type of blob from its header at offset
+func (o *OSM) blobInfo(buffer *bytes.Buffer, offset int64) (BlobInfo, error) {
+	headerSize, err := o.headerSize(buffer)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return BlobInfo{}, err
+	}
+
+	header, err := o.header(buffer, headerSize)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return BlobInfo{}, err
+	}
+
+	return BlobInfo{
+		Offset:    offset,
+		Size:      sizeReadData + headerSize + int64(header.GetDatasize()),
+		Type:      HeaderType(header.GetType()),
+		Indexdata: header.GetIndexdata(),
+	}, nil
+}
+
+// OpenIndex index of pbf file, with cache the index is read from and written to file beside it
+func OpenIndex(file string, cache bool) (*Index, error) {
+	stat, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+
+	indexFile := file + IndexFileExt
+	if cache {
+		if index, err := readIndex(indexFile); err == nil &&
+			index.Version == indexVersion && index.Size == stat.Size() && index.ModTime.Equal(stat.ModTime()) {
+			return index, nil
+		}
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	index, err := BuildIndex(f)
+	if err != nil {
+		return nil, err
+	}
+	index.Size = stat.Size()
+	index.ModTime = stat.ModTime()
+
+	if cache {
+		if err = index.Save(indexFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return index, nil
+}
+
+// Save write index as json
+func (i *Index) Save(file string) error {
+	data, err := json.Marshal(i)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, data, 0o644)
+}
+
+// Select header blobs and data blobs with elements of any of types
+func (i *Index) Select(types ...MemberType) []BlobInfo {
+	blobs := make([]BlobInfo, 0, len(i.Blobs))
+	for _, blob := range i.Blobs {
+		if blob.Type != BlobData {
+			blobs = append(blobs, blob)
+			continue
+		}
+
+		for _, elementType := range types {
+			if blob.Has(elementType) {
+				blobs = append(blobs, blob)
+				break
+			}
+		}
+	}
+
+	return blobs
+}
+
+func readIndex(file string) (*Index, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	index := &Index{}
+	if err = json.Unmarshal(data, index); err != nil {
+		return nil, err
+	}
+
+	return index, nil
+}
+
+func (b *BlobInfo) summarize(block *osmp.PrimitiveBlock) {
+	decoder := &blockDecoder{block: block}
+	b.MinID, b.MaxID = math.MaxInt64, math.MinInt64
+	bbox := []float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+
+	addID := func(id int64) {
+		b.MinID = min(b.MinID, id)
+		b.MaxID = max(b.MaxID, id)
+	}
+	addNode := func(id, lat, lon int64) {
+		addID(id)
+		nodeLat, nodeLon := decoder.coordinates(lat, lon)
+		bbox[0], bbox[1] = min(bbox[0], nodeLon), min(bbox[1], nodeLat)
+		bbox[2], bbox[3] = max(bbox[2], nodeLon), max(bbox[3], nodeLat)
+		b.Nodes++
+	}
+
+	for _, group := range block.GetPrimitivegroup() {
+		for _, node := range group.GetNodes() {
+			addNode(node.GetId(), node.GetLat(), node.GetLon())
+		}
+
+		if dense := group.GetDense(); dense != nil {
+			var id, lat, lon int64
+			for i := range dense.GetId() {
+				id += dense.GetId()[i]
+				lat += dense.GetLat()[i]
+				lon += dense.GetLon()[i]
+				addNode(id, lat, lon)
+			}
+		}
+
+		for _, way := range group.GetWays() {
+			addID(way.GetId())
+			b.Ways++
+		}
+
+		for _, relation := range group.GetRelations() {
+			addID(relation.GetId())
+			b.Relations++
+		}
+	}
+
+	if b.MinID > b.MaxID {
+		b.MinID, b.MaxID = 0, 0
+	}
+	if b.Nodes > 0 {
+		b.Bbox = bbox
+	}
+}
+
+// IndexedReader Read selected blobs of pbf file in parallel, blocks are sent in order of blobs.
+// Close stops reading when the channel is not read to the end
+type IndexedReader struct {
+	File    io.ReaderAt
+	Blobs   []BlobInfo
+	Workers int
+
+	err  error
+	done chan struct{}
+	once sync.Once
+}
+
+func NewIndexedReader(r io.ReaderAt, blobs []BlobInfo) *IndexedReader {
+	return &IndexedReader{File: r, Blobs: blobs, Workers: runtime.NumCPU()}
+}
+
+func (r *IndexedReader) Read() (<-chan *Data, error) {
+	if r.Workers < 1 {
+		return nil, errors.New("indexed reader needs at least one worker")
+	}
+
+	type result struct {
+		data *Data
+		err  error
+	}
+
+	if r.done == nil {
+		r.done = make(chan struct{})
+	}
+
+	dataChan := make(chan *Data)
+	results := make([]chan result, len(r.Blobs))
+	for i := range results {
+		results[i] = make(chan result, 1)
+	}
+
+	// window limits count of decoded blobs waiting for sending
+	window := make(chan struct{}, r.Workers*2)
+
+	go func() {
+		for i := range r.Blobs {
+			select {
+			case window <- struct{}{}:
+			case <-r.done:
+				return
+			}
+			go func(i int) {
+				data, err := r.readBlob(r.Blobs[i])
+				results[i] <- result{data: data, err: err}
+			}(i)
+		}
+	}()
+
+	go func() {
+		defer close(dataChan)
+
+		for i := range results {
+			var res result
+			select {
+			case res = <-results[i]:
+			case <-r.done:
+				return
+			}
+			<-window
+
+			// Blobs after failed one are not sent like in sequential reading
+			if res.err != nil && r.err == nil {
+				r.err = fmt.Errorf("blob at %d: %w", r.Blobs[i].Offset, res.err)
+			}
+			if r.err == nil {
+				select {
+				case dataChan <- res.data:
+				case <-r.done:
+					return
+				}
+			}
+		}
+	}()
+
+	return dataChan, nil
+}
+
+// Close stop reading of blobs, the channel is closed without remaining blocks
+func (r *IndexedReader) Close() error {
+	r.once.Do(func() {
+		if r.done != nil {
+			close(r.done)
+		}
+	})
+
+	return nil
+}
+
+func (r *IndexedReader) Err() error {
+	return r.err
+}
+
+func (r *IndexedReader) readBlob(info BlobInfo) (*Data, error) {
+	raw := make([]byte, info.Size)
+	if _, err := r.File.ReadAt(raw, info.Offset); err != nil {
+		return nil, err
+	}
+
+	headerSize := int64(binary.BigEndian.Uint32(raw[:sizeReadData]))
+	if sizeReadData+headerSize > info.Size {
+		return nil, fmt.Errorf("blob header at %d is out of blob", info.Offset)
+	}
+
+	header := new(osmp.BlobHeader)
+	if err := proto.Unmarshal(raw[sizeReadData:sizeReadData+headerSize], header); err != nil {
+		return nil, err
+	}
+
+	blob := new(osmp.Blob)
+	if err := proto.Unmarshal(raw[sizeReadData+headerSize:], blob); err != nil {
+		return nil, err
+	}
+
+	data, err := blobData(blob)
+	if err != nil {
+		return nil, err
+	}
+
+	osmData, err := unmarshalData(data, header)
+	if err != nil {
+		return nil, err
+	}
+
+	if osmData.Header != nil {
+		if err = CheckRequiredFeatures(osmData.Header); err != nil {
+			return nil, err
+		}
+	}
+
+	return osmData, nil
+}
@@ -0,0 +1,138 @@
+package osm
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	osmp "github.com/your-map/mbtiles-tool/internal/osm/proto"
+)
+
+func TestIndex(t *testing.T) {
+	elements := testElements()
+
+	buf := new(bytes.Buffer)
+	writer := NewWriter(buf, CompressionZlib)
+	if err := writer.WriteHeader(&osmp.HeaderBlock{RequiredFeatures: []string{"OsmSchema-V0.6", "DenseNodes"}}); err != nil {
+		t.Fatalf("WriteHeader() error = %v", err)
+	}
+	for _, node := range elements.Nodes {
+		if err := writer.WriteNode(node); err != nil {
+			t.Fatalf("WriteNode() error = %v", err)
+		}
+	}
+	if err := writer.WriteWay(elements.Ways[0]); err != nil {
+		t.Fatalf("WriteWay() error = %v", err)
+	}
+	if err := writer.WriteRelation(elements.Relations[0]); err != nil {
+		t.Fatalf("WriteRelation() error = %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	index, err := BuildIndex(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("BuildIndex() error = %v", err)
+	}
+
+	if len(index.Blobs) != 4 {
+		t.Fatalf("BuildIndex() blobs = %d, want 4", len(index.Blobs))
+	}
+
+	nodes := index.Blobs[1]
+	if nodes.Nodes != 3 || nodes.MinID != 1 || nodes.MaxID != 8 {
+		t.Errorf("BuildIndex() node blob = %+v", nodes)
+	}
+	if nodes.Bbox[0] > -179.9 || nodes.Bbox[1] > -33.8 || nodes.Bbox[2] < 151.2 || nodes.Bbox[3] < 42.5 {
+		t.Errorf("BuildIndex() node bbox = %v", nodes.Bbox)
+	}
+
+	last := index.Blobs[len(index.Blobs)-1]
+	if last.Offset+last.Size != int64(buf.Len()) {
+		t.Errorf("BuildIndex() end of last blob = %d, want %d", last.Offset+last.Size, buf.Len())
+	}
+
+	scanned, err := ScanBlobs(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("ScanBlobs() error = %v", err)
+	}
+	for i, blob := range scanned {
+		if blob.Offset != index.Blobs[i].Offset || blob.Size != index.Blobs[i].Size || blob.Type != index.Blobs[i].Type {
+			t.Errorf("ScanBlobs() blob %d = %+v, want %+v", i, blob, index.Blobs[i])
+		}
+	}
+	if len(scanned) != len(index.Blobs) {
+		t.Errorf("ScanBlobs() blobs = %d, want %d", len(scanned), len(index.Blobs))
+	}
+	if _, err = ScanBlobs(bytes.NewReader(buf.Bytes()[:buf.Len()-1])); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("ScanBlobs() of truncated file error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+
+	blobs := index.Select(WayMember)
+	if len(blobs) != 2 || blobs[0].Type != Header || !blobs[1].Has(WayMember) {
+		t.Fatalf("Select() = %+v", blobs)
+	}
+
+	dataChan, err := NewIndexedReader(bytes.NewReader(buf.Bytes()), blobs).Read()
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	var got []*Data
+	for data := range dataChan {
+		got = append(got, data)
+	}
+	if len(got) != 2 || got[0].Header == nil {
+		t.Fatalf("Read() data = %d, want header and way block", len(got))
+	}
+	if ways := Decode(got[1].Block).Ways; len(ways) != 1 || ways[0].ID != 10 {
+		t.Errorf("Read() ways = %v", ways)
+	}
+}
+
+func TestIndexedReader_Close(t *testing.T) {
+	buf := new(bytes.Buffer)
+	writer := NewWriter(buf, CompressionNone)
+	if err := writer.WriteHeader(&osmp.HeaderBlock{RequiredFeatures: []string{"OsmSchema-V0.6", "DenseNodes"}}); err != nil {
+		t.Fatalf("WriteHeader() error = %v", err)
+	}
+	for id := int64(1); id <= 20; id++ {
+		if err := writer.WriteNode(&Node{ID: id, Lat: 42.5, Lon: 1.5}); err != nil {
+			t.Fatalf("WriteNode() error = %v", err)
+		}
+		// Every node in its own blob
+		if err := writer.Flush(); err != nil {
+			t.Fatalf("Flush() error = %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	blobs, err := ScanBlobs(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("ScanBlobs() error = %v", err)
+	}
+
+	reader := NewIndexedReader(bytes.NewReader(buf.Bytes()), blobs)
+	reader.Workers = 1
+	dataChan, err := reader.Read()
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	<-dataChan
+
+	// Blocks not read after close are dropped and the channel is closed
+	if err = reader.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	var rest int
+	for range dataChan {
+		rest++
+	}
+	if rest >= len(blobs)-1 {
+		t.Errorf("Read() sent %d blocks after close, want reading stopped", rest)
+	}
+}
@@ -0,0 +1,129 @@
+package osm
+
+import (
+	"bufio"
+	"compress/bzip2"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+type Action string
+
+var (
+	Create Action = "create"
+	Modify Action = "modify"
+	Delete Action = "delete"
+)
+
+// Change One element of osm change file with its action
+type Change struct {
+	Action   Action
+	Node     *Node
+	Way      *Way
+	Relation *Relation
+}
+
+// OsmChange Changes of osc file in document order
+// https://wiki.openstreetmap.org/wiki/OsmChange
+type OsmChange struct {
+	Changes []Change
+	// Timestamp newest timestamp of changed elements
+	Timestamp time.Time
+}
+
+// ReadChange parse osm change xml, gzip and bzip2 compression is detected by content
+func ReadChange(r io.Reader) (*OsmChange, error) {
+	reader, err := decompress(r)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := xml.NewDecoder(reader)
+	osmChange := &OsmChange{Changes: make([]Change, 0)}
+	var action Action
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid osm change: %w", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		change := Change{Action: action}
+		switch start.Name.Local {
+		case "osmChange":
+			continue
+		case string(Create), string(Modify), string(Delete):
+			action = Action(start.Name.Local)
+			continue
+		case "node":
+			element := &xmlNode{}
+			if err = decoder.DecodeElement(element, &start); err != nil {
+				return nil, err
+			}
+			change.Node = element.node()
+			osmChange.addTimestamp(change.Node.Info)
+		case "way":
+			element := &xmlWay{}
+			if err = decoder.DecodeElement(element, &start); err != nil {
+				return nil, err
+			}
+			change.Way = element.way()
+			osmChange.addTimestamp(change.Way.Info)
+		case "relation":
+			element := &xmlRelation{}
+			if err = decoder.DecodeElement(element, &start); err != nil {
+				return nil, err
+			}
+			change.Relation = element.relation()
+			osmChange.addTimestamp(change.Relation.Info)
+		default:
+			if err = decoder.Skip(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if action == "" {
+			return nil, fmt.Errorf("element %s outside of create, modify or delete", start.Name.Local)
+		}
+		osmChange.Changes = append(osmChange.Changes, change)
+	}
+
+	return osmChange, nil
+}
+
+func (c *OsmChange) addTimestamp(info *Info) {
+	if info != nil && info.Timestamp.After(c.Timestamp) {
+		c.Timestamp = info.Timestamp
+	}
+}
+
+// decompress wrap reader with gzip or bzip2 reader by magic bytes
+func decompress(r io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+
+	magic, err := buffered.Peek(3)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	switch {
+	case len(magic) >= 2 && magic[0] == 0x1f && magic[1] == 0x8b:
+		return gzip.NewReader(buffered)
+	case len(magic) == 3 && string(magic) == "BZh":
+		return bzip2.NewReader(buffered), nil
+	default:
+		return buffered, nil
+	}
+}
@@ -0,0 +1,77 @@
+package osm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testChange = `<?xml version="1.0" encoding="UTF-8"?>
+<osmChange version="0.6">
+  <create>
+    <node id="1" version="1" timestamp="2025-10-19T10:00:00Z" lat="42.5" lon="1.5">
+      <tag k="amenity" v="cafe"/>
+    </node>
+  </create>
+  <modify>
+    <way id="2" version="3" timestamp="2025-10-19T11:00:00Z">
+      <nd ref="1"/>
+      <nd ref="3"/>
+      <tag k="highway" v="residential"/>
+    </way>
+  </modify>
+  <delete>
+    <relation id="4" version="2" timestamp="2025-10-19T09:00:00Z" visible="false"/>
+  </delete>
+</osmChange>`
+
+func TestReadChange(t *testing.T) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, _ = writer.Write([]byte(testChange))
+	_ = writer.Close()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "plain", data: []byte(testChange)},
+		{name: "gzip", data: compressed.Bytes()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadChange(bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatalf("ReadChange() error = %v", err)
+			}
+
+			if len(got.Changes) != 3 {
+				t.Fatalf("ReadChange() changes = %d, want 3", len(got.Changes))
+			}
+
+			node, way, relation := got.Changes[0], got.Changes[1], got.Changes[2]
+			if node.Action != Create || node.Node == nil || node.Node.Tags["amenity"] != "cafe" || node.Node.Lat != 42.5 {
+				t.Errorf("ReadChange() node = %+v", node)
+			}
+			if way.Action != Modify || way.Way == nil || len(way.Way.Refs) != 2 || way.Way.Refs[1] != 3 {
+				t.Errorf("ReadChange() way = %+v", way)
+			}
+			if relation.Action != Delete || relation.Relation == nil || relation.Relation.Info.Visible {
+				t.Errorf("ReadChange() relation = %+v", relation)
+			}
+
+			if want := time.Date(2025, 10, 19, 11, 0, 0, 0, time.UTC); !got.Timestamp.Equal(want) {
+				t.Errorf("ReadChange() timestamp = %v, want %v", got.Timestamp, want)
+			}
+		})
+	}
+}
+
+func TestReadChangeOutsideAction(t *testing.T) {
+	_, err := ReadChange(strings.NewReader(`<osmChange><node id="1" lat="1" lon="1"/></osmChange>`))
+	if err == nil {
+		t.Error("ReadChange() expected error for element outside of action")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/klauspost/compress/zstd"
 	osmp "github.com/your-map/mbtiles-tool/internal/osm/proto"
 
 	"google.golang.org/protobuf/proto"
@@ -17,8 +18,20 @@ const (
 	sizeReadData = 4
 )
 
+// Reader Stream of header and primitive blocks of osm file
+type Reader interface {
+	Read() (<-chan *Data, error)
+	// Err error which stopped the stream, it is known after the channel is closed
+	Err() error
+}
+
+// zstdDecoder Shared decoder of zstd blobs, DecodeAll is safe for concurrent use
+var zstdDecoder, _ = zstd.NewReader(nil)
+
 type OSM struct {
 	File io.Reader
+
+	err error
 }
 
 type Data struct {
@@ -30,7 +43,6 @@ func NewOSM(r io.Reader) *OSM {
 	return &OSM{File: r}
 }
 
-// todo add channel for errors
 func (o *OSM) Read() (<-chan *Data, error) {
 	dataChan := make(chan *Data)
 
@@ -41,36 +53,20 @@ func (o *OSM) Read() (<-chan *Data, error) {
 		for {
 			headerSize, err := o.headerSize(buf)
 			if err != nil {
-				if err == io.EOF {
-					break
-				}
-				break
-			}
-
-			header, err := o.header(buf, headerSize)
-			if err != nil {
-				if err == io.EOF {
-					break
+				// End of file is expected only between blobs
+				if err != io.EOF {
+					o.err = err
 				}
-				break
+				return
 			}
 
-			blob, err := o.blob(buf, header)
+			osmData, err := o.next(buf, headerSize)
 			if err != nil {
 				if err == io.EOF {
-					break
+					err = io.ErrUnexpectedEOF
 				}
-				break
-			}
-
-			data, err := o.data(buf, blob)
-			if err != nil {
-				break
-			}
-
-			osmData, err := o.unmarshalData(data, header)
-			if err != nil {
-				break
+				o.err = err
+				return
 			}
 
 			dataChan <- osmData
@@ -80,7 +76,42 @@ func (o *OSM) Read() (<-chan *Data, error) {
 	return dataChan, nil
 }
 
-func (o *OSM) unmarshalData(data []byte, header *osmp.BlobHeader) (*Data, error) {
+func (o *OSM) Err() error {
+	return o.err
+}
+
+// next read blob after its header size, required features of header are checked
+func (o *OSM) next(buf *bytes.Buffer, headerSize int64) (*Data, error) {
+	header, err := o.header(buf, headerSize)
+	if err != nil {
+		return nil, err
+	}
+
+	blob, err := o.blob(buf, header)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := blobData(blob)
+	if err != nil {
+		return nil, err
+	}
+
+	osmData, err := unmarshalData(data, header)
+	if err != nil {
+		return nil, err
+	}
+
+	if osmData.Header != nil {
+		if err = CheckRequiredFeatures(osmData.Header); err != nil {
+			return nil, err
+		}
+	}
+
+	return osmData, nil
+}
+
+func unmarshalData(data []byte, header *osmp.BlobHeader) (*Data, error) {
 	osmData := new(Data)
 
 	switch HeaderType(header.GetType()) {
@@ -105,7 +136,8 @@ func (o *OSM) unmarshalData(data []byte, header *osmp.BlobHeader) (*Data, error)
 	return osmData, nil
 }
 
-func (o *OSM) data(buf *bytes.Buffer, blob *osmp.Blob) ([]byte, error) {
+// blobData uncompressed data of blob
+func blobData(blob *osmp.Blob) ([]byte, error) {
 	data := make([]byte, 0)
 
 	switch blob.Data.(type) {
@@ -116,8 +148,6 @@ func (o *OSM) data(buf *bytes.Buffer, blob *osmp.Blob) ([]byte, error) {
 		if err != nil {
 			return nil, err
 		}
-		buf.Reset()
-
 		newBuf := bytes.NewBuffer(make([]byte, 0, blob.GetRawSize()+bytes.MinRead))
 		if _, err = newBuf.ReadFrom(r); err != nil {
 			return nil, err
@@ -128,6 +158,19 @@ func (o *OSM) data(buf *bytes.Buffer, blob *osmp.Blob) ([]byte, error) {
 		}
 
 		data = newBuf.Bytes()
+	case *osmp.Blob_ZstdData:
+		decoded, err := zstdDecoder.DecodeAll(blob.GetZstdData(), make([]byte, 0, blob.GetRawSize()))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(decoded) != int(blob.GetRawSize()) {
+			return nil, fmt.Errorf("raw blob data size %d but expected %d", len(decoded), blob.GetRawSize())
+		}
+
+		data = decoded
+	default:
+		return nil, fmt.Errorf("unsupported blob compression %T", blob.Data)
 	}
 
 	return data, nil
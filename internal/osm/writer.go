@@ -0,0 +1,207 @@
+package osm
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	osmp "github.com/your-map/mbtiles-tool/internal/osm/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// maxBlobHeaderSize Limit of blob header size of pbf format
+const maxBlobHeaderSize = 64 * 1024
+
+var ErrHeaderWritten = errors.New("header is already written")
+
+type Compression string
+
+var (
+	CompressionNone Compression = "none"
+	CompressionZlib Compression = "zlib"
+	CompressionZstd Compression = "zstd"
+)
+
+// Writer Write osm pbf file, elements are collected to primitive blocks of one type
+type Writer struct {
+	File        io.Writer
+	Compression Compression
+
+	headerWritten bool
+	builder       *blockBuilder
+	zstd          *zstd.Encoder
+}
+
+func NewWriter(w io.Writer, compression Compression) *Writer {
+	return &Writer{
+		File:        w,
+		Compression: compression,
+		builder:     newBlockBuilder(),
+	}
+}
+
+// WriteHeader write header block, it must be written before all elements
+func (w *Writer) WriteHeader(header *osmp.HeaderBlock) error {
+	if w.headerWritten {
+		return ErrHeaderWritten
+	}
+
+	if err := w.writeBlob(Header, header); err != nil {
+		return err
+	}
+	w.headerWritten = true
+
+	return nil
+}
+
+// WriteBlock write encoded primitive block, collected elements are flushed before it
+func (w *Writer) WriteBlock(block *osmp.PrimitiveBlock) error {
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	return w.writeBlob(BlobData, block)
+}
+
+func (w *Writer) WriteNode(node *Node) error {
+	if err := w.flushFor(len(w.builder.ways)+len(w.builder.relations) > 0); err != nil {
+		return err
+	}
+	w.builder.addNode(node)
+
+	return nil
+}
+
+func (w *Writer) WriteWay(way *Way) error {
+	if err := w.flushFor(len(w.builder.nodes)+len(w.builder.relations) > 0); err != nil {
+		return err
+	}
+	w.builder.addWay(way)
+
+	return nil
+}
+
+func (w *Writer) WriteRelation(relation *Relation) error {
+	if err := w.flushFor(len(w.builder.nodes)+len(w.builder.ways) > 0); err != nil {
+		return err
+	}
+	w.builder.addRelation(relation)
+
+	return nil
+}
+
+// Flush write collected elements as primitive block
+func (w *Writer) Flush() error {
+	if w.builder.len() == 0 {
+		return nil
+	}
+
+	block := w.builder.build()
+	w.builder = newBlockBuilder()
+
+	return w.writeBlob(BlobData, block)
+}
+
+// Close flush collected elements, the underlying file is not closed
+func (w *Writer) Close() error {
+	err := w.Flush()
+
+	if w.zstd != nil {
+		if closeErr := w.zstd.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
+
+// flushFor flush block when it is full or other type of elements is collected
+func (w *Writer) flushFor(otherType bool) error {
+	if otherType || w.builder.len() >= maxBlockEntities {
+		return w.Flush()
+	}
+
+	return nil
+}
+
+func (w *Writer) writeBlob(blobType HeaderType, message proto.Message) error {
+	if blobType == BlobData && !w.headerWritten {
+		return errors.New("header must be written before data")
+	}
+
+	raw, err := proto.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	blob, err := w.blob(raw)
+	if err != nil {
+		return err
+	}
+
+	blobData, err := proto.Marshal(blob)
+	if err != nil {
+		return err
+	}
+	if len(blobData) > maxBlobSize {
+		return fmt.Errorf("blob size too large: %d", len(blobData))
+	}
+
+	header, err := proto.Marshal(&osmp.BlobHeader{
+		Type:     proto.String(string(blobType)),
+		Datasize: proto.Int32(int32(len(blobData))),
+	})
+	if err != nil {
+		return err
+	}
+	if len(header) > maxBlobHeaderSize {
+		return fmt.Errorf("blob header size too large: %d", len(header))
+	}
+
+	size := make([]byte, sizeReadData)
+	binary.BigEndian.PutUint32(size, uint32(len(header)))
+
+	for _, data := range [][]byte{size, header, blobData} {
+		if _, err = w.File.Write(data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *Writer) blob(raw []byte) (*osmp.Blob, error) {
+	blob := &osmp.Blob{RawSize: proto.Int32(int32(len(raw)))}
+
+	switch w.Compression {
+	case CompressionNone:
+		blob.RawSize = nil
+		blob.Data = &osmp.Blob_Raw{Raw: raw}
+	case CompressionZstd:
+		if w.zstd == nil {
+			encoder, err := zstd.NewWriter(nil)
+			if err != nil {
+				return nil, err
+			}
+			w.zstd = encoder
+		}
+		blob.Data = &osmp.Blob_ZstdData{ZstdData: w.zstd.EncodeAll(raw, nil)}
+	default:
+		buf := new(bytes.Buffer)
+		writer := zlib.NewWriter(buf)
+		if _, err := writer.Write(raw); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		blob.Data = &osmp.Blob_ZlibData{ZlibData: buf.Bytes()}
+	}
+
+	return blob, nil
+}
@@ -0,0 +1,164 @@
+package osm
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	osmp "github.com/your-map/mbtiles-tool/internal/osm/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func testElements() *Elements {
+	timestamp := time.Date(2025, 10, 19, 10, 0, 0, 0, time.UTC)
+
+	return &Elements{
+		Nodes: []*Node{
+			{ID: 1, Lat: 42.5063, Lon: 1.5218, Tags: map[string]string{"amenity": "cafe", "name": "Café"},
+				Info: &Info{Version: 2, Timestamp: timestamp, Changeset: 9, UID: 5, User: "a", Visible: true}},
+			{ID: 7, Lat: -33.8688, Lon: 151.2093, Tags: map[string]string{},
+				Info: &Info{Version: 1, Timestamp: timestamp.Add(time.Hour), Changeset: 3, UID: 6, User: "b", Visible: true}},
+			{ID: 8, Lat: 0, Lon: -179.9999999, Tags: map[string]string{},
+				Info: &Info{Version: 1, Timestamp: timestamp, Changeset: 3, UID: 6, User: "b", Visible: true}},
+		},
+		Ways: []*Way{
+			{ID: 10, Refs: []int64{7, 1, 8, 7}, Tags: map[string]string{"highway": "residential"},
+				Info: &Info{Version: 4, Timestamp: timestamp, Changeset: 11, UID: 5, User: "a", Visible: true}},
+		},
+		Relations: []*Relation{
+			{ID: 20, Members: []Member{{Type: WayMember, Ref: 10, Role: "outer"}, {Type: NodeMember, Ref: 1, Role: ""}, {Type: RelationMember, Ref: 3, Role: "subarea"}},
+				Tags: map[string]string{"type": "multipolygon"}},
+		},
+	}
+}
+
+func TestWriter_RoundTrip(t *testing.T) {
+	for _, compression := range []Compression{CompressionNone, CompressionZlib, CompressionZstd} {
+		t.Run(string(compression), func(t *testing.T) {
+			want := testElements()
+			header := &osmp.HeaderBlock{
+				RequiredFeatures: []string{"OsmSchema-V0.6", "DenseNodes"},
+				Writingprogram:   proto.String("test"),
+			}
+
+			buf := new(bytes.Buffer)
+			writer := NewWriter(buf, compression)
+			if err := writer.WriteHeader(header); err != nil {
+				t.Fatalf("WriteHeader() error = %v", err)
+			}
+			for _, node := range want.Nodes {
+				if err := writer.WriteNode(node); err != nil {
+					t.Fatalf("WriteNode() error = %v", err)
+				}
+			}
+			for _, way := range want.Ways {
+				if err := writer.WriteWay(way); err != nil {
+					t.Fatalf("WriteWay() error = %v", err)
+				}
+			}
+			for _, relation := range want.Relations {
+				if err := writer.WriteRelation(relation); err != nil {
+					t.Fatalf("WriteRelation() error = %v", err)
+				}
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			dataChan, err := NewOSM(buf).Read()
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+
+			got := &Elements{}
+			blocks := 0
+			for data := range dataChan {
+				if data.Header != nil && !proto.Equal(data.Header, header) {
+					t.Errorf("Read() header = %v, want %v", data.Header, header)
+				}
+				if data.Block != nil {
+					blocks++
+					elements := Decode(data.Block)
+					got.Nodes = append(got.Nodes, elements.Nodes...)
+					got.Ways = append(got.Ways, elements.Ways...)
+					got.Relations = append(got.Relations, elements.Relations...)
+				}
+			}
+
+			if blocks != 3 {
+				t.Errorf("Read() blocks = %d, want one block for every type", blocks)
+			}
+
+			if len(got.Nodes) != len(want.Nodes) {
+				t.Fatalf("Read() nodes = %d, want %d", len(got.Nodes), len(want.Nodes))
+			}
+			for i, node := range got.Nodes {
+				if math.Abs(node.Lat-want.Nodes[i].Lat) > 1e-9 || math.Abs(node.Lon-want.Nodes[i].Lon) > 1e-9 {
+					t.Errorf("Read() node %d coordinates = %v %v, want %v %v", node.ID, node.Lat, node.Lon, want.Nodes[i].Lat, want.Nodes[i].Lon)
+				}
+				node.Lat, node.Lon = want.Nodes[i].Lat, want.Nodes[i].Lon
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Read() elements differ\ngot  %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestWriter_HeaderFirst(t *testing.T) {
+	writer := NewWriter(new(bytes.Buffer), CompressionZlib)
+	if err := writer.WriteBlock(&osmp.PrimitiveBlock{}); err == nil {
+		t.Error("WriteBlock() before header expected error")
+	}
+
+	if err := writer.WriteHeader(&osmp.HeaderBlock{}); err != nil {
+		t.Fatalf("WriteHeader() error = %v", err)
+	}
+	if err := writer.WriteHeader(&osmp.HeaderBlock{}); err != ErrHeaderWritten {
+		t.Errorf("WriteHeader() twice error = %v, want %v", err, ErrHeaderWritten)
+	}
+}
+
+func TestWriter_LocationsOnWays(t *testing.T) {
+	way := &Way{ID: 10, Refs: []int64{7, 1, 8}, Lats: []float64{-33.8688, 42.5063, 0}, Lons: []float64{151.2093, 1.5218, -179.9999999}}
+
+	buf := new(bytes.Buffer)
+	writer := NewWriter(buf, CompressionNone)
+	if err := writer.WriteHeader(&osmp.HeaderBlock{OptionalFeatures: []string{FeatureLocationsOnWays}}); err != nil {
+		t.Fatalf("WriteHeader() error = %v", err)
+	}
+	if err := writer.WriteWay(way); err != nil {
+		t.Fatalf("WriteWay() error = %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	dataChan, err := NewOSM(buf).Read()
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	var got []*Way
+	for data := range dataChan {
+		if data.Header != nil && !HasOptionalFeature(data.Header, FeatureLocationsOnWays) {
+			t.Errorf("Read() header without %s", FeatureLocationsOnWays)
+		}
+		if data.Block != nil {
+			got = append(got, Decode(data.Block).Ways...)
+		}
+	}
+
+	if len(got) != 1 || len(got[0].Lats) != len(way.Refs) || len(got[0].Lons) != len(way.Refs) {
+		t.Fatalf("Read() ways = %v", got)
+	}
+	for i := range way.Refs {
+		if math.Abs(got[0].Lats[i]-way.Lats[i]) > 1e-7 || math.Abs(got[0].Lons[i]-way.Lons[i]) > 1e-7 {
+			t.Errorf("Read() location %d = %v,%v, want %v,%v", i, got[0].Lats[i], got[0].Lons[i], way.Lats[i], way.Lons[i])
+		}
+	}
+}
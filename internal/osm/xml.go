@@ -0,0 +1,261 @@
+package osm
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+	"time"
+
+	osmp "github.com/your-map/mbtiles-tool/internal/osm/proto"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Elements of the osm xml format https://wiki.openstreetmap.org/wiki/OSM_XML
+type xmlTag struct {
+	Key   string `xml:"k,attr"`
+	Value string `xml:"v,attr"`
+}
+
+type xmlInfo struct {
+	Version   int32  `xml:"version,attr"`
+	Timestamp string `xml:"timestamp,attr"`
+	Changeset int64  `xml:"changeset,attr"`
+	UID       int32  `xml:"uid,attr"`
+	User      string `xml:"user,attr"`
+	Visible   string `xml:"visible,attr"`
+}
+
+type xmlNode struct {
+	xmlInfo
+	ID   int64    `xml:"id,attr"`
+	Lat  float64  `xml:"lat,attr"`
+	Lon  float64  `xml:"lon,attr"`
+	Tags []xmlTag `xml:"tag"`
+}
+
+type xmlNd struct {
+	Ref int64 `xml:"ref,attr"`
+}
+
+type xmlWay struct {
+	xmlInfo
+	ID   int64    `xml:"id,attr"`
+	Nds  []xmlNd  `xml:"nd"`
+	Tags []xmlTag `xml:"tag"`
+}
+
+type xmlMember struct {
+	Type string `xml:"type,attr"`
+	Ref  int64  `xml:"ref,attr"`
+	Role string `xml:"role,attr"`
+}
+
+type xmlRelation struct {
+	xmlInfo
+	ID      int64       `xml:"id,attr"`
+	Members []xmlMember `xml:"member"`
+	Tags    []xmlTag    `xml:"tag"`
+}
+
+func (n *xmlNode) node() *Node {
+	return &Node{
+		ID:   n.ID,
+		Lat:  n.Lat,
+		Lon:  n.Lon,
+		Tags: xmlTags(n.Tags),
+		Info: n.info(),
+	}
+}
+
+func (w *xmlWay) way() *Way {
+	refs := make([]int64, 0, len(w.Nds))
+	for _, nd := range w.Nds {
+		refs = append(refs, nd.Ref)
+	}
+
+	return &Way{
+		ID:   w.ID,
+		Refs: refs,
+		Tags: xmlTags(w.Tags),
+		Info: w.info(),
+	}
+}
+
+func (r *xmlRelation) relation() *Relation {
+	members := make([]Member, 0, len(r.Members))
+	for _, member := range r.Members {
+		members = append(members, Member{
+			Type: MemberType(member.Type),
+			Ref:  member.Ref,
+			Role: member.Role,
+		})
+	}
+
+	return &Relation{
+		ID:      r.ID,
+		Members: members,
+		Tags:    xmlTags(r.Tags),
+		Info:    r.info(),
+	}
+}
+
+func (i *xmlInfo) info() *Info {
+	if i.Version == 0 && i.Timestamp == "" && i.Changeset == 0 && i.User == "" {
+		return nil
+	}
+
+	timestamp, _ := time.Parse(time.RFC3339, i.Timestamp)
+
+	return &Info{
+		Version:   i.Version,
+		Timestamp: timestamp,
+		Changeset: i.Changeset,
+		UID:       i.UID,
+		User:      i.User,
+		Visible:   i.Visible != "false",
+	}
+}
+
+func xmlTags(tags []xmlTag) map[string]string {
+	result := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		result[tag.Key] = tag.Value
+	}
+
+	return result
+}
+
+type xmlBounds struct {
+	MinLat float64 `xml:"minlat,attr"`
+	MinLon float64 `xml:"minlon,attr"`
+	MaxLat float64 `xml:"maxlat,attr"`
+	MaxLon float64 `xml:"maxlon,attr"`
+}
+
+// XML Streaming reader of osm xml file, elements are grouped to primitive blocks
+// like in pbf file, so the same pipeline converts both formats
+type XML struct {
+	File io.Reader
+
+	err error
+}
+
+func NewXML(r io.Reader) *XML {
+	return &XML{File: r}
+}
+
+func (x *XML) Read() (<-chan *Data, error) {
+	reader, err := decompress(x.File)
+	if err != nil {
+		return nil, err
+	}
+
+	dataChan := make(chan *Data)
+
+	go func() {
+		defer close(dataChan)
+
+		decoder := xml.NewDecoder(reader)
+		header := &osmp.HeaderBlock{RequiredFeatures: []string{FeatureOsmSchema, FeatureDenseNodes}}
+		headerSent := false
+		builder := newBlockBuilder()
+
+		sendHeader := func() {
+			if !headerSent {
+				dataChan <- &Data{Header: header}
+				headerSent = true
+			}
+		}
+
+		// Block is flushed when it is full or the type of elements is changed
+		flush := func(kind string) {
+			sendHeader()
+			full := builder.len() >= maxBlockEntities
+			changed := (kind != "node" && len(builder.nodes) > 0) ||
+				(kind != "way" && len(builder.ways) > 0) ||
+				(kind != "relation" && len(builder.relations) > 0)
+			if builder.len() > 0 && (full || changed) {
+				dataChan <- &Data{Block: builder.build()}
+				builder = newBlockBuilder()
+			}
+		}
+
+		for {
+			token, err := decoder.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				x.err = err
+				return
+			}
+
+			start, ok := token.(xml.StartElement)
+			if !ok {
+				continue
+			}
+
+			switch start.Name.Local {
+			case "osm":
+				for _, attr := range start.Attr {
+					if attr.Name.Local == "generator" {
+						header.Writingprogram = proto.String(attr.Value)
+					}
+				}
+			case "bounds":
+				bounds := &xmlBounds{}
+				if err = decoder.DecodeElement(bounds, &start); err != nil {
+					x.err = err
+					return
+				}
+				header.Bbox = &osmp.HeaderBBox{
+					Left:   proto.Int64(int64(math.Round(bounds.MinLon * 1e9))),
+					Right:  proto.Int64(int64(math.Round(bounds.MaxLon * 1e9))),
+					Top:    proto.Int64(int64(math.Round(bounds.MaxLat * 1e9))),
+					Bottom: proto.Int64(int64(math.Round(bounds.MinLat * 1e9))),
+				}
+			case "node":
+				element := &xmlNode{}
+				if err = decoder.DecodeElement(element, &start); err != nil {
+					x.err = err
+					return
+				}
+				flush("node")
+				builder.addNode(element.node())
+			case "way":
+				element := &xmlWay{}
+				if err = decoder.DecodeElement(element, &start); err != nil {
+					x.err = err
+					return
+				}
+				flush("way")
+				builder.addWay(element.way())
+			case "relation":
+				element := &xmlRelation{}
+				if err = decoder.DecodeElement(element, &start); err != nil {
+					x.err = err
+					return
+				}
+				flush("relation")
+				builder.addRelation(element.relation())
+			default:
+				if err = decoder.Skip(); err != nil {
+					x.err = err
+					return
+				}
+			}
+		}
+
+		sendHeader()
+		if builder.len() > 0 {
+			dataChan <- &Data{Block: builder.build()}
+		}
+	}()
+
+	return dataChan, nil
+}
+
+func (x *XML) Err() error {
+	return x.err
+}
@@ -0,0 +1,74 @@
+package osm
+
+import (
+	"strings"
+	"testing"
+)
+
+const testXML = `<?xml version="1.0" encoding="UTF-8"?>
+<osm version="0.6" generator="JOSM">
+  <bounds minlat="42.5" minlon="1.5" maxlat="42.52" maxlon="1.53"/>
+  <node id="1" version="2" timestamp="2025-10-19T10:00:00Z" uid="5" user="a" changeset="9" lat="42.5063" lon="1.5218">
+    <tag k="amenity" v="cafe"/>
+  </node>
+  <node id="5" lat="42.507" lon="1.522"/>
+  <way id="10">
+    <nd ref="5"/>
+    <nd ref="1"/>
+    <tag k="highway" v="residential"/>
+  </way>
+  <relation id="20">
+    <member type="way" ref="10" role="outer"/>
+    <member type="node" ref="1" role="label"/>
+  </relation>
+</osm>`
+
+func TestXML_Read(t *testing.T) {
+	dataChan, err := NewXML(strings.NewReader(testXML)).Read()
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	var data []*Data
+	for item := range dataChan {
+		data = append(data, item)
+	}
+
+	// Header and one block for every type of elements
+	if len(data) != 4 || data[0].Header == nil {
+		t.Fatalf("Read() got %d items, want header and 3 blocks", len(data))
+	}
+
+	header := data[0].Header
+	if header.GetWritingprogram() != "JOSM" || header.GetBbox().GetLeft() != 1500000000 || header.GetBbox().GetTop() != 42520000000 {
+		t.Errorf("Read() header = %v", header)
+	}
+
+	nodes := data[1].Block
+	dense := nodes.GetPrimitivegroup()[0].GetDense()
+	if len(dense.GetId()) != 2 || dense.GetId()[1] != 4 {
+		t.Fatalf("Read() dense ids = %v, want delta coded [1 4]", dense.GetId())
+	}
+	lat := float64(dense.GetLat()[0]*int64(nodes.GetGranularity())) / 1e9
+	if lat != 42.5063 {
+		t.Errorf("Read() lat = %v, want 42.5063", lat)
+	}
+	table := nodes.GetStringtable().GetS()
+	keysVals := dense.GetKeysVals()
+	if string(table[keysVals[0]]) != "amenity" || string(table[keysVals[1]]) != "cafe" || keysVals[2] != 0 {
+		t.Errorf("Read() keys_vals = %v", keysVals)
+	}
+	if dense.GetDenseinfo().GetVersion()[0] != 2 || dense.GetDenseinfo().GetVisible() != nil {
+		t.Errorf("Read() dense info = %v", dense.GetDenseinfo())
+	}
+
+	way := data[2].Block.GetPrimitivegroup()[0].GetWays()[0]
+	if way.GetId() != 10 || len(way.GetRefs()) != 2 || way.GetRefs()[0] != 5 || way.GetRefs()[1] != -4 {
+		t.Errorf("Read() way = %v, want delta coded refs [5 -4]", way)
+	}
+
+	relation := data[3].Block.GetPrimitivegroup()[0].GetRelations()[0]
+	if len(relation.GetMemids()) != 2 || relation.GetMemids()[1] != -9 || relation.GetTypes()[0].String() != "WAY" {
+		t.Errorf("Read() relation = %v", relation)
+	}
+}
@@ -0,0 +1,100 @@
+package replication
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/your-map/mbtiles-tool/internal/osm"
+)
+
+var ErrNotFound = errors.New("replication file not found")
+
+// Client Read replication state and diffs from http server or local directory with the same layout
+type Client struct {
+	Base string
+	http *http.Client
+}
+
+// NewClient base is url of replication directory or path of local mirror
+func NewClient(base string) *Client {
+	return &Client{
+		Base: strings.TrimSuffix(base, "/"),
+		http: &http.Client{Timeout: time.Minute},
+	}
+}
+
+// State latest state of replication
+func (c *Client) State() (*State, error) {
+	return c.state("state.txt")
+}
+
+// SequenceState state of one sequence
+func (c *Client) SequenceState(sequence int64) (*State, error) {
+	return c.state(SequencePath(sequence) + ".state.txt")
+}
+
+// Change diff of sequence
+func (c *Client) Change(sequence int64) (*osm.OsmChange, error) {
+	reader, err := c.open(SequencePath(sequence) + ".osc.gz")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	change, err := osm.ReadChange(reader)
+	if err != nil {
+		return nil, fmt.Errorf("sequence %d: %w", sequence, err)
+	}
+
+	return change, nil
+}
+
+func (c *Client) state(name string) (*State, error) {
+	reader, err := c.open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	return ParseState(reader)
+}
+
+func (c *Client) open(name string) (io.ReadCloser, error) {
+	if !c.remote() {
+		file, err := os.Open(filepath.Join(strings.TrimPrefix(c.Base, "file://"), filepath.FromSlash(name)))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return file, err
+	}
+
+	response, err := c.http.Get(c.Base + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+
+	switch response.StatusCode {
+	case http.StatusOK:
+		return response.Body, nil
+	case http.StatusNotFound:
+		_ = response.Body.Close()
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	default:
+		_ = response.Body.Close()
+		return nil, fmt.Errorf("cannot get %s: %s", name, response.Status)
+	}
+}
+
+func (c *Client) remote() bool {
+	return strings.HasPrefix(c.Base, "http://") || strings.HasPrefix(c.Base, "https://")
+}
@@ -0,0 +1,68 @@
+package replication
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidState = errors.New("invalid replication state")
+
+// State Sequence and timestamp of state.txt file
+// https://wiki.openstreetmap.org/wiki/Planet.osm/diffs
+type State struct {
+	Sequence  int64
+	Timestamp time.Time
+}
+
+// ParseState parse state file in java properties format, colons of timestamp are escaped
+func ParseState(r io.Reader) (*State, error) {
+	state := &State{Sequence: -1}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.ReplaceAll(value, `\`, "")
+
+		switch key {
+		case "sequenceNumber":
+			sequence, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: sequence %q", ErrInvalidState, value)
+			}
+			state.Sequence = sequence
+		case "timestamp":
+			timestamp, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: timestamp %q", ErrInvalidState, value)
+			}
+			state.Timestamp = timestamp
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if state.Sequence < 0 {
+		return nil, fmt.Errorf("%w: no sequence number", ErrInvalidState)
+	}
+
+	return state, nil
+}
+
+// SequencePath path of files of sequence without extension, 4577 is 000/004/577
+func SequencePath(sequence int64) string {
+	return fmt.Sprintf("%03d/%03d/%03d", sequence/1000000, sequence/1000%1000, sequence%1000)
+}
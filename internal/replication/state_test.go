@@ -0,0 +1,108 @@
+package replication
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseState(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    *State
+		wantErr bool
+	}{
+		{
+			name: "escaped timestamp",
+			data: "#Sat Oct 18 20:21:02 UTC 2025\nsequenceNumber=4576\ntimestamp=2025-10-18T20\\:20\\:55Z\n",
+			want: &State{Sequence: 4576, Timestamp: time.Date(2025, 10, 18, 20, 20, 55, 0, time.UTC)},
+		},
+		{
+			name: "without timestamp",
+			data: "sequenceNumber=12",
+			want: &State{Sequence: 12},
+		},
+		{
+			name:    "without sequence",
+			data:    "timestamp=2025-10-18T20\\:20\\:55Z",
+			wantErr: true,
+		},
+		{
+			name:    "invalid sequence",
+			data:    "sequenceNumber=abc",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseState(strings.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseState() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Sequence != tt.want.Sequence || !got.Timestamp.Equal(tt.want.Timestamp) {
+				t.Errorf("ParseState() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSequencePath(t *testing.T) {
+	tests := []struct {
+		sequence int64
+		want     string
+	}{
+		{sequence: 0, want: "000/000/000"},
+		{sequence: 4577, want: "000/004/577"},
+		{sequence: 6123456, want: "006/123/456"},
+	}
+	for _, tt := range tests {
+		if got := SequencePath(tt.sequence); got != tt.want {
+			t.Errorf("SequencePath(%d) = %s, want %s", tt.sequence, got, tt.want)
+		}
+	}
+}
+
+func TestClientLocal(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "000", "004"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"state.txt":             "sequenceNumber=4577\n",
+		"000/004/577.osc.gz":    `<osmChange><create><node id="1" lat="1" lon="2"/></create></osmChange>`,
+		"000/004/577.state.txt": "sequenceNumber=4577\ntimestamp=2025-10-19T10\\:00\\:00Z\n",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	client := NewClient(dir + "/")
+
+	state, err := client.State()
+	if err != nil || state.Sequence != 4577 {
+		t.Fatalf("State() = %+v, %v", state, err)
+	}
+
+	sequenceState, err := client.SequenceState(4577)
+	if err != nil || sequenceState.Timestamp.IsZero() {
+		t.Fatalf("SequenceState() = %+v, %v", sequenceState, err)
+	}
+
+	change, err := client.Change(4577)
+	if err != nil || len(change.Changes) != 1 || change.Changes[0].Node.Lon != 2 {
+		t.Fatalf("Change() = %+v, %v", change, err)
+	}
+
+	if _, err = client.Change(4578); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Change() of missing sequence error = %v, want %v", err, ErrNotFound)
+	}
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"embed"
+	"html/template"
+	"net/http"
+)
+
+//go:embed preview
+var previewFS embed.FS
+
+var (
+	previewTemplate = template.Must(template.ParseFS(previewFS, "preview/index.html"))
+	listTemplate    = template.Must(template.ParseFS(previewFS, "preview/list.html"))
+)
+
+var previewAssets = map[string]string{
+	"viewer.js":  "text/javascript; charset=utf-8",
+	"viewer.css": "text/css; charset=utf-8",
+}
+
+func (s *Server) previewRoutes() {
+	for asset, contentType := range previewAssets {
+		s.mux.HandleFunc("GET /_preview/"+asset, func(w http.ResponseWriter, r *http.Request) {
+			data, err := previewFS.ReadFile("preview/" + asset)
+			if err != nil {
+				http.NotFound(w, r)
+				return
+			}
+
+			w.Header().Set("Content-Type", contentType)
+			_, _ = w.Write(data)
+		})
+	}
+
+	s.mux.HandleFunc("GET /{tileset}/{$}", s.handlePreview)
+	if len(s.Tilesets) == 1 {
+		s.mux.HandleFunc("GET /{$}", s.handlePreview)
+	} else {
+		s.mux.HandleFunc("GET /{$}", s.handleList)
+	}
+}
+
+func (s *Server) handlePreview(w http.ResponseWriter, r *http.Request) {
+	name, _, ok := s.tileset(r)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.writeHTML(w, previewTemplate, struct{ TileJSON string }{TileJSON: "/" + name + "/" + tileJSONFilename})
+}
+
+func (s *Server) handleList(w http.ResponseWriter, _ *http.Request) {
+	s.writeHTML(w, listTemplate, s.Names())
+}
+
+func (s *Server) writeHTML(w http.ResponseWriter, tmpl *template.Template, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := tmpl.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
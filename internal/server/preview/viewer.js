@@ -0,0 +1,611 @@
+// Offline inspector of vector tiles: decodes mvt and draws every layer on a canvas.
+var Viewer = (function () {
+    "use strict";
+
+    var TILE_SIZE = 256;
+    var MAX_LAT = 85.0511287798;
+    var HIT_RADIUS = 5;
+
+    // Protobuf reader for the mvt spec https://github.com/mapbox/vector-tile-spec
+    function Reader(buffer) {
+        this.buf = new Uint8Array(buffer);
+        this.view = new DataView(this.buf.buffer, this.buf.byteOffset, this.buf.byteLength);
+        this.pos = 0;
+    }
+
+    Reader.prototype.varint = function () {
+        var result = 0, multiplier = 1, b;
+        do {
+            b = this.buf[this.pos++];
+            result += (b & 0x7f) * multiplier;
+            multiplier *= 128;
+        } while (b & 0x80);
+        return result;
+    };
+
+    Reader.prototype.svarint = function () {
+        var n = this.varint();
+        return n % 2 === 1 ? (n + 1) / -2 : n / 2;
+    };
+
+    Reader.prototype.bytes = function () {
+        var end = this.varint() + this.pos;
+        var bytes = this.buf.subarray(this.pos, end);
+        this.pos = end;
+        return bytes;
+    };
+
+    Reader.prototype.string = function () {
+        return new TextDecoder().decode(this.bytes());
+    };
+
+    Reader.prototype.packed = function () {
+        var end = this.varint() + this.pos, values = [];
+        while (this.pos < end) {
+            values.push(this.varint());
+        }
+        return values;
+    };
+
+    Reader.prototype.skip = function (wireType) {
+        switch (wireType) {
+            case 0:
+                this.varint();
+                break;
+            case 1:
+                this.pos += 8;
+                break;
+            case 2:
+                var length = this.varint();
+                this.pos += length;
+                break;
+            case 5:
+                this.pos += 4;
+                break;
+            default:
+                throw new Error("unknown wire type " + wireType);
+        }
+    };
+
+    Reader.prototype.message = function (end, field) {
+        while (this.pos < end) {
+            var key = this.varint();
+            if (!field(key >> 3, key & 7)) {
+                this.skip(key & 7);
+            }
+        }
+    };
+
+    function decodeValue(r) {
+        var end = r.varint() + r.pos, value = null;
+        r.message(end, function (field) {
+            switch (field) {
+                case 1:
+                    value = r.string();
+                    return true;
+                case 2:
+                    value = r.view.getFloat32(r.pos, true);
+                    r.pos += 4;
+                    return true;
+                case 3:
+                    value = r.view.getFloat64(r.pos, true);
+                    r.pos += 8;
+                    return true;
+                case 4:
+                case 5:
+                    value = r.varint();
+                    return true;
+                case 6:
+                    value = r.svarint();
+                    return true;
+                case 7:
+                    value = r.varint() === 1;
+                    return true;
+            }
+            return false;
+        });
+        return value;
+    }
+
+    function decodeGeometry(commands) {
+        var parts = [], part = null, x = 0, y = 0, i = 0;
+        while (i < commands.length) {
+            var command = commands[i] & 0x7, count = commands[i] >> 3;
+            i++;
+            if (command === 7) {
+                if (part && part.length) {
+                    part.push([part[0][0], part[0][1]]);
+                }
+                continue;
+            }
+            for (var n = 0; n < count; n++) {
+                x += (commands[i] >> 1) ^ (-(commands[i] & 1));
+                y += (commands[i + 1] >> 1) ^ (-(commands[i + 1] & 1));
+                i += 2;
+                if (command === 1) {
+                    part = [];
+                    parts.push(part);
+                }
+                part.push([x, y]);
+            }
+        }
+        return parts;
+    }
+
+    function decodeFeature(r, keys, values) {
+        var end = r.varint() + r.pos;
+        var feature = {id: null, type: 0, tags: [], geometry: []};
+        r.message(end, function (field) {
+            switch (field) {
+                case 1:
+                    feature.id = r.varint();
+                    return true;
+                case 2:
+                    feature.tags = r.packed();
+                    return true;
+                case 3:
+                    feature.type = r.varint();
+                    return true;
+                case 4:
+                    feature.geometry = r.packed();
+                    return true;
+            }
+            return false;
+        });
+
+        feature.properties = {};
+        for (var i = 0; i + 1 < feature.tags.length; i += 2) {
+            feature.properties[keys[feature.tags[i]]] = values[feature.tags[i + 1]];
+        }
+        feature.parts = decodeGeometry(feature.geometry);
+        return feature;
+    }
+
+    function decodeLayer(r) {
+        var end = r.varint() + r.pos;
+        var layer = {name: "", extent: 4096, features: []};
+        var keys = [], values = [], rawFeatures = [];
+        r.message(end, function (field) {
+            switch (field) {
+                case 1:
+                    layer.name = r.string();
+                    return true;
+                case 2:
+                    rawFeatures.push(r.pos);
+                    var length = r.varint();
+                    r.pos += length;
+                    return true;
+                case 3:
+                    keys.push(r.string());
+                    return true;
+                case 4:
+                    values.push(decodeValue(r));
+                    return true;
+                case 5:
+                    layer.extent = r.varint();
+                    return true;
+            }
+            return false;
+        });
+
+        // Features are decoded after keys and values of the layer are known
+        rawFeatures.forEach(function (pos) {
+            r.pos = pos;
+            layer.features.push(decodeFeature(r, keys, values));
+        });
+        r.pos = end;
+        return layer;
+    }
+
+    function decodeTile(buffer) {
+        var r = new Reader(buffer), layers = [];
+        r.message(r.buf.length, function (field) {
+            if (field === 3) {
+                layers.push(decodeLayer(r));
+                return true;
+            }
+            return false;
+        });
+        return layers;
+    }
+
+    // Web mercator helpers in normalized world coordinates [0, 1]
+    function project(lon, lat) {
+        lat = Math.max(-MAX_LAT, Math.min(MAX_LAT, lat));
+        var sin = Math.sin(lat * Math.PI / 180);
+        return [
+            (lon + 180) / 360,
+            0.5 - Math.log((1 + sin) / (1 - sin)) / (4 * Math.PI)
+        ];
+    }
+
+    function unproject(x, y) {
+        var n = Math.PI - 2 * Math.PI * y;
+        return [x * 360 - 180, 180 / Math.PI * Math.atan(0.5 * (Math.exp(n) - Math.exp(-n)))];
+    }
+
+    function layerColor(index) {
+        return "hsl(" + Math.round((index * 137.508) % 360) + ", 70%, 45%)";
+    }
+
+    function escapeHTML(value) {
+        return String(value).replace(/[&<>"']/g, function (c) {
+            return "&#" + c.charCodeAt(0) + ";";
+        });
+    }
+
+    function pointInRing(ring, x, y) {
+        var inside = false;
+        for (var i = 0, j = ring.length - 1; i < ring.length; j = i++) {
+            var a = ring[i], b = ring[j];
+            if ((a[1] > y) !== (b[1] > y) && x < (b[0] - a[0]) * (y - a[1]) / (b[1] - a[1]) + a[0]) {
+                inside = !inside;
+            }
+        }
+        return inside;
+    }
+
+    function segmentDistance(p, a, b) {
+        var dx = b[0] - a[0], dy = b[1] - a[1];
+        var t = dx === 0 && dy === 0 ? 0 : ((p[0] - a[0]) * dx + (p[1] - a[1]) * dy) / (dx * dx + dy * dy);
+        t = Math.max(0, Math.min(1, t));
+        return Math.hypot(p[0] - a[0] - t * dx, p[1] - a[1] - t * dy);
+    }
+
+    function Map(canvas, tilejson) {
+        this.canvas = canvas;
+        this.ctx = canvas.getContext("2d");
+        this.tilejson = tilejson;
+        this.tiles = {};
+        this.layers = {};
+        this.zoom = tilejson.minzoom;
+        this.center = [0.5, 0.5];
+        this.mouse = null;
+
+        var self = this;
+        (tilejson.vector_layers || []).forEach(function (layer) {
+            self.addLayer(layer.id);
+        });
+
+        if (tilejson.center) {
+            this.center = project(tilejson.center[0], tilejson.center[1]);
+            this.zoom = tilejson.center[2];
+        } else if (tilejson.bounds) {
+            var b = tilejson.bounds;
+            this.center = project((b[0] + b[2]) / 2, (b[1] + b[3]) / 2);
+        }
+
+        this.bindEvents();
+        this.resize();
+    }
+
+    Map.prototype.addLayer = function (name) {
+        if (this.layers[name]) {
+            return;
+        }
+
+        var layer = {name: name, color: layerColor(Object.keys(this.layers).length), visible: true};
+        this.layers[name] = layer;
+
+        var self = this;
+        var item = document.createElement("li");
+        var checkbox = document.createElement("input");
+        checkbox.type = "checkbox";
+        checkbox.checked = true;
+        checkbox.addEventListener("change", function () {
+            layer.visible = checkbox.checked;
+            self.render();
+        });
+        var swatch = document.createElement("span");
+        swatch.className = "swatch";
+        swatch.style.background = layer.color;
+        var label = document.createElement("span");
+        label.textContent = name;
+        item.append(checkbox, swatch, label);
+        document.getElementById("layers").append(item);
+    };
+
+    Map.prototype.tileZoom = function () {
+        return Math.max(this.tilejson.minzoom, Math.min(this.tilejson.maxzoom, Math.round(this.zoom)));
+    };
+
+    Map.prototype.worldSize = function () {
+        return TILE_SIZE * Math.pow(2, this.zoom);
+    };
+
+    Map.prototype.screenToWorld = function (sx, sy) {
+        var size = this.worldSize();
+        return [
+            this.center[0] + (sx - this.canvas.clientWidth / 2) / size,
+            this.center[1] + (sy - this.canvas.clientHeight / 2) / size
+        ];
+    };
+
+    Map.prototype.visibleTiles = function () {
+        var z = this.tileZoom(), n = Math.pow(2, z);
+        var topLeft = this.screenToWorld(0, 0);
+        var bottomRight = this.screenToWorld(this.canvas.clientWidth, this.canvas.clientHeight);
+        var tiles = [];
+
+        for (var x = Math.max(0, Math.floor(topLeft[0] * n)); x <= Math.min(n - 1, Math.floor(bottomRight[0] * n)); x++) {
+            for (var y = Math.max(0, Math.floor(topLeft[1] * n)); y <= Math.min(n - 1, Math.floor(bottomRight[1] * n)); y++) {
+                tiles.push({z: z, x: x, y: y});
+            }
+        }
+        return tiles;
+    };
+
+    Map.prototype.loadTile = function (t) {
+        var key = t.z + "/" + t.x + "/" + t.y;
+        if (this.tiles[key]) {
+            return this.tiles[key];
+        }
+
+        var self = this;
+        var tile = {z: t.z, x: t.x, y: t.y, layers: null};
+        this.tiles[key] = tile;
+
+        var url = this.tilejson.tiles[0].replace("{z}", t.z).replace("{x}", t.x).replace("{y}", t.y);
+        fetch(url).then(function (response) {
+            if (response.status === 204) {
+                return new ArrayBuffer(0);
+            }
+            if (!response.ok) {
+                throw new Error(response.status + " " + response.statusText);
+            }
+            return response.arrayBuffer();
+        }).then(function (buffer) {
+            tile.layers = decodeTile(buffer);
+            tile.layers.forEach(function (layer) {
+                self.addLayer(layer.name);
+            });
+            self.render();
+        }).catch(function (err) {
+            tile.layers = [];
+            console.error("tile " + key + ": " + err.message);
+        });
+
+        return tile;
+    };
+
+    Map.prototype.tileTransform = function (tile, extent) {
+        var size = this.worldSize(), n = Math.pow(2, tile.z);
+        var scale = size / n / extent;
+        return {
+            x: (tile.x / n - this.center[0]) * size + this.canvas.clientWidth / 2,
+            y: (tile.y / n - this.center[1]) * size + this.canvas.clientHeight / 2,
+            scale: scale
+        };
+    };
+
+    Map.prototype.render = function () {
+        var ctx = this.ctx, self = this;
+        ctx.setTransform(window.devicePixelRatio, 0, 0, window.devicePixelRatio, 0, 0);
+        ctx.clearRect(0, 0, this.canvas.clientWidth, this.canvas.clientHeight);
+
+        var tiles = this.visibleTiles().map(function (t) {
+            return self.loadTile(t);
+        });
+
+        // Polygons are drawn first so lines and points stay visible above them
+        [3, 2, 1].forEach(function (type) {
+            tiles.forEach(function (tile) {
+                (tile.layers || []).forEach(function (layer) {
+                    var style = self.layers[layer.name];
+                    if (!style || !style.visible) {
+                        return;
+                    }
+                    var transform = self.tileTransform(tile, layer.extent);
+                    layer.features.forEach(function (feature) {
+                        if (feature.type === type) {
+                            self.drawFeature(feature, transform, style.color);
+                        }
+                    });
+                });
+            });
+        });
+
+        tiles.forEach(function (tile) {
+            var transform = self.tileTransform(tile, 1);
+            var size = self.worldSize() / Math.pow(2, tile.z);
+            ctx.strokeStyle = "rgba(0, 0, 0, 0.15)";
+            ctx.lineWidth = 1;
+            ctx.strokeRect(transform.x, transform.y, size, size);
+        });
+
+        this.updateStatus();
+    };
+
+    Map.prototype.drawFeature = function (feature, transform, color) {
+        var ctx = this.ctx;
+        ctx.beginPath();
+        feature.parts.forEach(function (part) {
+            part.forEach(function (point, i) {
+                var x = transform.x + point[0] * transform.scale;
+                var y = transform.y + point[1] * transform.scale;
+                if (feature.type === 1) {
+                    ctx.moveTo(x + 3, y);
+                    ctx.arc(x, y, 3, 0, 2 * Math.PI);
+                } else if (i === 0) {
+                    ctx.moveTo(x, y);
+                } else {
+                    ctx.lineTo(x, y);
+                }
+            });
+        });
+
+        ctx.fillStyle = color;
+        ctx.strokeStyle = color;
+        if (feature.type === 1) {
+            ctx.fill();
+        } else if (feature.type === 2) {
+            ctx.lineWidth = 1.5;
+            ctx.stroke();
+        } else if (feature.type === 3) {
+            ctx.globalAlpha = 0.25;
+            ctx.fill("evenodd");
+            ctx.globalAlpha = 1;
+            ctx.lineWidth = 1;
+            ctx.stroke();
+        }
+    };
+
+    Map.prototype.featuresAt = function (sx, sy) {
+        var found = [], self = this;
+        this.visibleTiles().forEach(function (t) {
+            var tile = self.tiles[t.z + "/" + t.x + "/" + t.y];
+            (tile && tile.layers || []).forEach(function (layer) {
+                var style = self.layers[layer.name];
+                if (!style || !style.visible) {
+                    return;
+                }
+                var transform = self.tileTransform(tile, layer.extent);
+                var p = [(sx - transform.x) / transform.scale, (sy - transform.y) / transform.scale];
+                var radius = HIT_RADIUS / transform.scale;
+                if (p[0] < -radius || p[1] < -radius || p[0] > layer.extent + radius || p[1] > layer.extent + radius) {
+                    return;
+                }
+                layer.features.forEach(function (feature) {
+                    if (self.hit(feature, p, radius)) {
+                        found.push({layer: layer.name, color: style.color, tile: tile, feature: feature});
+                    }
+                });
+            });
+        });
+        return found;
+    };
+
+    Map.prototype.hit = function (feature, p, radius) {
+        if (feature.type === 3) {
+            var inside = false;
+            feature.parts.forEach(function (ring) {
+                if (pointInRing(ring, p[0], p[1])) {
+                    inside = !inside;
+                }
+            });
+            if (inside) {
+                return true;
+            }
+        }
+        return feature.parts.some(function (part) {
+            if (part.length === 1) {
+                return Math.hypot(p[0] - part[0][0], p[1] - part[0][1]) <= radius;
+            }
+            for (var i = 1; i < part.length; i++) {
+                if (segmentDistance(p, part[i - 1], part[i]) <= radius) {
+                    return true;
+                }
+            }
+            return false;
+        });
+    };
+
+    Map.prototype.showFeatures = function (found) {
+        var panel = document.getElementById("feature");
+        if (found.length === 0) {
+            panel.className = "muted";
+            panel.textContent = "No features here";
+            return;
+        }
+
+        var types = ["Unknown", "Point", "LineString", "Polygon"];
+        panel.className = "";
+        panel.innerHTML = found.map(function (item) {
+            var rows = [["layer", item.layer], ["id", item.feature.id], ["geometry", types[item.feature.type]],
+                ["tile", item.tile.z + "/" + item.tile.x + "/" + item.tile.y]];
+            Object.keys(item.feature.properties).sort().forEach(function (key) {
+                rows.push([key, item.feature.properties[key]]);
+            });
+            return '<div class="feature"><span class="swatch" style="background:' + item.color + '"></span> ' +
+                "<strong>" + escapeHTML(item.layer) + "</strong><table>" + rows.map(function (row) {
+                    return "<tr><td>" + escapeHTML(row[0]) + "</td><td>" + escapeHTML(row[1]) + "</td></tr>";
+                }).join("") + "</table></div>";
+        }).join("");
+    };
+
+    Map.prototype.updateStatus = function () {
+        document.getElementById("status-zoom").textContent =
+            "zoom " + this.zoom.toFixed(2) + " (tiles z" + this.tileZoom() + ")";
+
+        if (!this.mouse) {
+            return;
+        }
+
+        var world = this.screenToWorld(this.mouse[0], this.mouse[1]);
+        var z = this.tileZoom(), n = Math.pow(2, z);
+        var lonLat = unproject(world[0], world[1]);
+        document.getElementById("status-tile").textContent =
+            "tile " + z + "/" + Math.floor(world[0] * n) + "/" + Math.floor(world[1] * n);
+        document.getElementById("status-position").textContent =
+            lonLat[0].toFixed(6) + ", " + lonLat[1].toFixed(6);
+    };
+
+    Map.prototype.resize = function () {
+        var ratio = window.devicePixelRatio;
+        this.canvas.width = this.canvas.clientWidth * ratio;
+        this.canvas.height = this.canvas.clientHeight * ratio;
+        this.render();
+    };
+
+    Map.prototype.bindEvents = function () {
+        var self = this, drag = null;
+
+        window.addEventListener("resize", function () {
+            self.resize();
+        });
+
+        this.canvas.addEventListener("mousedown", function (e) {
+            drag = {x: e.clientX, y: e.clientY, moved: false};
+            self.canvas.classList.add("dragging");
+        });
+
+        window.addEventListener("mouseup", function (e) {
+            if (drag && !drag.moved) {
+                self.showFeatures(self.featuresAt(e.clientX, e.clientY));
+            }
+            drag = null;
+            self.canvas.classList.remove("dragging");
+        });
+
+        this.canvas.addEventListener("mousemove", function (e) {
+            self.mouse = [e.clientX, e.clientY];
+            if (drag) {
+                var dx = e.clientX - drag.x, dy = e.clientY - drag.y;
+                if (Math.abs(dx) + Math.abs(dy) > 2) {
+                    drag.moved = true;
+                }
+                self.center[0] -= dx / self.worldSize();
+                self.center[1] -= dy / self.worldSize();
+                drag.x = e.clientX;
+                drag.y = e.clientY;
+                self.render();
+            } else {
+                self.updateStatus();
+            }
+        });
+
+        this.canvas.addEventListener("wheel", function (e) {
+            e.preventDefault();
+            var before = self.screenToWorld(e.clientX, e.clientY);
+            self.zoom = Math.max(0, Math.min(self.tilejson.maxzoom + 4, self.zoom - e.deltaY / 300));
+            var after = self.screenToWorld(e.clientX, e.clientY);
+            self.center[0] += before[0] - after[0];
+            self.center[1] += before[1] - after[1];
+            self.render();
+        }, {passive: false});
+    };
+
+    function start(canvas, tilejsonURL) {
+        fetch(tilejsonURL).then(function (response) {
+            return response.json();
+        }).then(function (tilejson) {
+            document.title = tilejson.name + " - mbt preview";
+            document.getElementById("title").textContent = tilejson.name;
+            new Map(canvas, tilejson);
+        }).catch(function (err) {
+            document.getElementById("feature").textContent = "Cannot load tilejson: " + err.message;
+        });
+    }
+
+    return {start: start, decodeTile: decodeTile};
+})();
@@ -0,0 +1,292 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/your-map/mbtiles-tool/internal/mbt"
+)
+
+const (
+	mbtilesExt       = ".mbtiles"
+	contentTypeTile  = "application/vnd.mapbox-vector-tile"
+	contentTypeJSON  = "application/json"
+	tileJSONFilename = "tiles.json"
+)
+
+var errNoTilesets = errors.New("no mbtiles files found")
+
+// Server Http server of xyz vector tiles from mbtiles files
+type Server struct {
+	Tilesets map[string]*mbt.Tileset
+	MaxAge   int
+
+	mux *http.ServeMux
+}
+
+// NewServer create server for mbtiles file or for all mbtiles files in directory
+func NewServer(path string, maxAge int) (*Server, error) {
+	tilesets, err := loadTilesets(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		Tilesets: tilesets,
+		MaxAge:   maxAge,
+		mux:      http.NewServeMux(),
+	}
+	s.routes()
+
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Names return sorted names of served tilesets
+func (s *Server) Names() []string {
+	names := make([]string, 0, len(s.Tilesets))
+	for name := range s.Tilesets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (s *Server) Close() error {
+	var errs []error
+	for _, tileset := range s.Tilesets {
+		errs = append(errs, tileset.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *Server) routes() {
+	s.mux.HandleFunc("GET /index.json", s.handleIndex)
+	s.mux.HandleFunc("GET /{tileset}/"+tileJSONFilename, s.handleTileJSON)
+	s.mux.HandleFunc("GET /{tileset}/{z}/{x}/{y}", s.handleTile)
+
+	// Single tileset is also available from the root
+	if len(s.Tilesets) == 1 {
+		s.mux.HandleFunc("GET /"+tileJSONFilename, s.handleTileJSON)
+		s.mux.HandleFunc("GET /{z}/{x}/{y}", s.handleTile)
+	}
+
+	s.previewRoutes()
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	index := make([]*TileJSON, 0, len(s.Tilesets))
+
+	for _, name := range s.Names() {
+		tj, err := s.tileJSON(r, name, s.Tilesets[name])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		index = append(index, tj)
+	}
+
+	s.writeJSON(w, index)
+}
+
+func (s *Server) handleTileJSON(w http.ResponseWriter, r *http.Request) {
+	name, tileset, ok := s.tileset(r)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	tj, err := s.tileJSON(r, name, tileset)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeJSON(w, tj)
+}
+
+func (s *Server) handleTile(w http.ResponseWriter, r *http.Request) {
+	_, tileset, ok := s.tileset(r)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	z, x, y, err := tileCoordinates(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := tileset.Tile(z, x, y)
+	if errors.Is(err, mbt.ErrTileNotFound) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	etag := tileETag(data)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", s.MaxAge))
+
+	if noneMatch(r.Header.Values("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentTypeTile)
+	if compression := mbt.DetectCompression(data); compression != mbt.CompressionNone {
+		w.Header().Set("Content-Encoding", compression)
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	_, _ = w.Write(data)
+}
+
+// tileset return tileset from path or the single served tileset
+func (s *Server) tileset(r *http.Request) (string, *mbt.Tileset, bool) {
+	name := r.PathValue("tileset")
+	if name == "" {
+		for single, tileset := range s.Tilesets {
+			return single, tileset, true
+		}
+	}
+
+	tileset, ok := s.Tilesets[name]
+	return name, tileset, ok
+}
+
+func (s *Server) tileJSON(r *http.Request, name string, tileset *mbt.Tileset) (*TileJSON, error) {
+	metadata, err := tileset.Metadata()
+	if err != nil {
+		return nil, err
+	}
+
+	tj, err := NewTileJSON(metadata, baseURL(r)+"/"+name+"/{z}/{x}/{y}.pbf")
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata of %s: %w", name, err)
+	}
+
+	if tj.Name == "" {
+		tj.Name = name
+	}
+
+	return tj, nil
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func tileCoordinates(r *http.Request) (int, int, int, error) {
+	z, errZ := strconv.Atoi(r.PathValue("z"))
+	x, errX := strconv.Atoi(r.PathValue("x"))
+	y, errY := strconv.Atoi(strings.TrimSuffix(r.PathValue("y"), filepath.Ext(r.PathValue("y"))))
+	if err := errors.Join(errZ, errX, errY); err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid tile coordinates: %w", err)
+	}
+
+	if z < 0 || z > 30 || x < 0 || y < 0 || x >= 1<<z || y >= 1<<z {
+		return 0, 0, 0, fmt.Errorf("tile %d/%d/%d out of range", z, x, y)
+	}
+
+	return z, x, y, nil
+}
+
+func tileETag(data []byte) string {
+	hash := fnv.New64a()
+	_, _ = hash.Write(data)
+
+	return fmt.Sprintf(`"%x"`, hash.Sum64())
+}
+
+// noneMatch If-None-Match fields match the etag by weak comparison of RFC 9110,
+// fields are "*" or lists of strong or weak etags
+func noneMatch(fields []string, etag string) bool {
+	opaque := strings.TrimPrefix(etag, "W/")
+	for _, field := range fields {
+		for field = strings.TrimLeft(field, " \t,"); field != ""; field = strings.TrimLeft(field, " \t,") {
+			if field[0] == '*' {
+				return true
+			}
+
+			tag := strings.TrimPrefix(field, "W/")
+			if tag == "" || tag[0] != '"' {
+				return false
+			}
+			end := strings.IndexByte(tag[1:], '"')
+			if end < 0 {
+				return false
+			}
+			if tag[:end+2] == opaque {
+				return true
+			}
+			field = tag[end+2:]
+		}
+	}
+
+	return false
+}
+
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	return scheme + "://" + r.Host
+}
+
+func loadTilesets(path string) (map[string]*mbt.Tileset, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files, err = filepath.Glob(filepath.Join(path, "*"+mbtilesExt))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w in %s", errNoTilesets, path)
+	}
+
+	tilesets := make(map[string]*mbt.Tileset, len(files))
+	for _, file := range files {
+		tileset, err := mbt.Open(file)
+		if err != nil {
+			for _, opened := range tilesets {
+				_ = opened.Close()
+			}
+			return nil, err
+		}
+
+		tilesets[strings.TrimSuffix(filepath.Base(file), mbtilesExt)] = tileset
+	}
+
+	return tilesets, nil
+}
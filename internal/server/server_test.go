@@ -0,0 +1,121 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var gzipTile = []byte{0x1f, 0x8b, 0x08, 0x00}
+
+func createTileset(t *testing.T, file string) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`
+		CREATE TABLE metadata (name text, value text);
+		CREATE TABLE tiles (zoom_level integer, tile_column integer, tile_row integer, tile_data blob);
+		INSERT INTO metadata VALUES ('name', 'Andorra'), ('minzoom', '0'), ('maxzoom', '14'),
+			('bounds', '1.4,42.4,1.8,42.7'), ('center', '1.6,42.5,10'),
+			('json', '{"vector_layers":[{"id":"points","minzoom":0,"maxzoom":14,"fields":{"name":"String"}}]}');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Row 2 in tms is row 1 in xyz on zoom 2
+	if _, err = db.Exec("INSERT INTO tiles VALUES (2, 1, 2, ?)", gzipTile); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServer_ServeHTTP(t *testing.T) {
+	dir := t.TempDir()
+	createTileset(t, filepath.Join(dir, "andorra.mbtiles"))
+
+	s, err := NewServer(dir, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	tests := []struct {
+		name        string
+		path        string
+		etag        string
+		wantStatus  int
+		wantContent string
+	}{
+		{name: "tile", path: "/andorra/2/1/1.pbf", wantStatus: http.StatusOK, wantContent: "gzip"},
+		{name: "root tile", path: "/2/1/1.pbf", wantStatus: http.StatusOK, wantContent: "gzip"},
+		{name: "not modified", path: "/andorra/2/1/1.pbf", etag: tileETag(gzipTile), wantStatus: http.StatusNotModified},
+		{name: "not modified by any", path: "/andorra/2/1/1.pbf", etag: "*", wantStatus: http.StatusNotModified},
+		{name: "not modified by list", path: "/andorra/2/1/1.pbf", etag: `"a,b", W/` + tileETag(gzipTile), wantStatus: http.StatusNotModified},
+		{name: "modified", path: "/andorra/2/1/1.pbf", etag: `"a", W/"b"`, wantStatus: http.StatusOK, wantContent: "gzip"},
+		{name: "empty tile", path: "/andorra/2/1/2.pbf", wantStatus: http.StatusNoContent},
+		{name: "out of range", path: "/andorra/2/4/1.pbf", wantStatus: http.StatusBadRequest},
+		{name: "unknown tileset", path: "/unknown/2/1/1.pbf", wantStatus: http.StatusNotFound},
+		{name: "tilejson", path: "/andorra/tiles.json", wantStatus: http.StatusOK},
+		{name: "index", path: "/index.json", wantStatus: http.StatusOK},
+		{name: "preview", path: "/andorra/", wantStatus: http.StatusOK},
+		{name: "root preview", path: "/", wantStatus: http.StatusOK},
+		{name: "preview asset", path: "/_preview/viewer.js", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.etag != "" {
+				r.Header.Set("If-None-Match", tt.etag)
+			}
+			w := httptest.NewRecorder()
+
+			s.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("ServeHTTP() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Content-Encoding"); got != tt.wantContent {
+				t.Errorf("ServeHTTP() Content-Encoding = %v, want %v", got, tt.wantContent)
+			}
+		})
+	}
+}
+
+func TestNewTileJSON(t *testing.T) {
+	dir := t.TempDir()
+	createTileset(t, filepath.Join(dir, "andorra.mbtiles"))
+
+	s, err := NewServer(filepath.Join(dir, "andorra.mbtiles"), 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://localhost:8080/tiles.json", nil))
+
+	tj := &TileJSON{}
+	if err = json.Unmarshal(w.Body.Bytes(), tj); err != nil {
+		t.Fatal(err)
+	}
+
+	if tj.Tiles[0] != "http://localhost:8080/andorra/{z}/{x}/{y}.pbf" {
+		t.Errorf("NewTileJSON() tiles = %v", tj.Tiles)
+	}
+	if len(tj.Bounds) != 4 || len(tj.Center) != 3 {
+		t.Errorf("NewTileJSON() bounds = %v, center = %v", tj.Bounds, tj.Center)
+	}
+	if len(tj.VectorLayers) != 1 || tj.VectorLayers[0].ID != "points" {
+		t.Errorf("NewTileJSON() vector_layers = %v", tj.VectorLayers)
+	}
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/your-map/mbtiles-tool/internal/mbt"
+)
+
+const tileJSONVersion = "3.0.0"
+
+// TileJSON Description of a tileset by the tilejson spec
+// https://github.com/mapbox/tilejson-spec/tree/master/3.0.0
+type TileJSON struct {
+	TileJSON     string            `json:"tilejson"`
+	Name         string            `json:"name,omitempty"`
+	Description  string            `json:"description,omitempty"`
+	Version      string            `json:"version,omitempty"`
+	Attribution  string            `json:"attribution,omitempty"`
+	Scheme       string            `json:"scheme"`
+	Format       string            `json:"format,omitempty"`
+	Tiles        []string          `json:"tiles"`
+	MinZoom      int               `json:"minzoom"`
+	MaxZoom      int               `json:"maxzoom"`
+	Bounds       []float64         `json:"bounds,omitempty"`
+	Center       []float64         `json:"center,omitempty"`
+	VectorLayers []mbt.VectorLayer `json:"vector_layers"`
+}
+
+// NewTileJSON build tilejson from the metadata table of tileset
+func NewTileJSON(metadata map[string]string, tileURL string) (*TileJSON, error) {
+	tj := &TileJSON{
+		TileJSON:     tileJSONVersion,
+		Name:         metadata["name"],
+		Description:  metadata["description"],
+		Version:      metadata["version"],
+		Attribution:  metadata["attribution"],
+		Scheme:       "xyz",
+		Format:       metadata["format"],
+		Tiles:        []string{tileURL},
+		MinZoom:      0,
+		MaxZoom:      14,
+		Bounds:       parseFloats(metadata["bounds"], 4),
+		Center:       parseFloats(metadata["center"], 3),
+		VectorLayers: make([]mbt.VectorLayer, 0),
+	}
+
+	if zoom, err := strconv.Atoi(metadata["minzoom"]); err == nil {
+		tj.MinZoom = zoom
+	}
+
+	if zoom, err := strconv.Atoi(metadata["maxzoom"]); err == nil {
+		tj.MaxZoom = zoom
+	}
+
+	vectorLayers, err := mbt.ParseVectorLayers(metadata)
+	if err != nil {
+		return nil, err
+	}
+	tj.VectorLayers = vectorLayers
+
+	return tj, nil
+}
+
+func parseFloats(value string, count int) []float64 {
+	parts := strings.Split(value, ",")
+	if len(parts) != count {
+		return nil
+	}
+
+	result := make([]float64, 0, count)
+	for _, part := range parts {
+		number, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil
+		}
+		result = append(result, number)
+	}
+
+	return result
+}
@@ -0,0 +1,151 @@
+// Package shapefile reads polygons of esri shapefiles (.shp) like water
+// polygons of osmdata.openstreetmap.de, attributes of .dbf files are not read
+package shapefile
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/planar"
+)
+
+var ErrInvalidShapefile = errors.New("invalid shapefile")
+
+const (
+	fileCode   = 9994
+	headerSize = 100
+
+	shapeNull     = 0
+	shapePolygon  = 5
+	shapePolygonZ = 15
+	shapePolygonM = 25
+)
+
+// ReadPolygons read polygons of .shp file, shapes with bounding box outside
+// of bound are skipped if bound is not nil. Outer rings of shapefiles are
+// clockwise, counterclockwise rings are holes of the outer ring containing them
+func ReadPolygons(r io.Reader, bound *orb.Bound) (orb.MultiPolygon, error) {
+	reader := bufio.NewReader(r)
+
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, fmt.Errorf("%w: header: %w", ErrInvalidShapefile, err)
+	}
+	if code := binary.BigEndian.Uint32(header[0:4]); code != fileCode {
+		return nil, fmt.Errorf("%w: file code %d", ErrInvalidShapefile, code)
+	}
+	if shapeType := binary.LittleEndian.Uint32(header[32:36]); !isPolygonType(shapeType) {
+		return nil, fmt.Errorf("%w: shape type %d is not polygon", ErrInvalidShapefile, shapeType)
+	}
+
+	var result orb.MultiPolygon
+	recordHeader := make([]byte, 8)
+	for {
+		_, err := io.ReadFull(reader, recordHeader)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: record header: %w", ErrInvalidShapefile, err)
+		}
+
+		// Length of content is in 16-bit words
+		content := make([]byte, int(binary.BigEndian.Uint32(recordHeader[4:8]))*2)
+		if _, err = io.ReadFull(reader, content); err != nil {
+			return nil, fmt.Errorf("%w: record %d: %w", ErrInvalidShapefile, binary.BigEndian.Uint32(recordHeader[0:4]), err)
+		}
+
+		polygons, err := parsePolygon(content, bound)
+		if err != nil {
+			return nil, fmt.Errorf("%w: record %d: %w", ErrInvalidShapefile, binary.BigEndian.Uint32(recordHeader[0:4]), err)
+		}
+		result = append(result, polygons...)
+	}
+
+	return result, nil
+}
+
+func isPolygonType(shapeType uint32) bool {
+	return shapeType == shapePolygon || shapeType == shapePolygonZ || shapeType == shapePolygonM
+}
+
+// parsePolygon polygons of record content, z and m values are ignored
+func parsePolygon(content []byte, bound *orb.Bound) (orb.MultiPolygon, error) {
+	if len(content) < 4 {
+		return nil, errors.New("record is too short")
+	}
+
+	shapeType := binary.LittleEndian.Uint32(content[0:4])
+	if shapeType == shapeNull {
+		return nil, nil
+	}
+	if !isPolygonType(shapeType) {
+		return nil, fmt.Errorf("shape type %d is not polygon", shapeType)
+	}
+	if len(content) < 44 {
+		return nil, errors.New("record is too short")
+	}
+
+	box := orb.Bound{
+		Min: orb.Point{float64At(content, 4), float64At(content, 12)},
+		Max: orb.Point{float64At(content, 20), float64At(content, 28)},
+	}
+	if bound != nil && !bound.Intersects(box) {
+		return nil, nil
+	}
+
+	numParts := int(binary.LittleEndian.Uint32(content[36:40]))
+	numPoints := int(binary.LittleEndian.Uint32(content[40:44]))
+	pointsAt := 44 + 4*numParts
+	if numParts < 1 || numPoints < 0 || len(content) < pointsAt+16*numPoints {
+		return nil, fmt.Errorf("%d parts and %d points do not fit record", numParts, numPoints)
+	}
+
+	var polygons orb.MultiPolygon
+	var holes []orb.Ring
+	for part := 0; part < numParts; part++ {
+		start := int(binary.LittleEndian.Uint32(content[44+4*part:]))
+		end := numPoints
+		if part+1 < numParts {
+			end = int(binary.LittleEndian.Uint32(content[44+4*(part+1):]))
+		}
+		if start < 0 || start > end || end > numPoints {
+			return nil, fmt.Errorf("part %d is out of points", part)
+		}
+
+		ring := make(orb.Ring, 0, end-start)
+		for i := start; i < end; i++ {
+			at := pointsAt + 16*i
+			ring = append(ring, orb.Point{float64At(content, at), float64At(content, at+8)})
+		}
+		if len(ring) < 4 {
+			continue
+		}
+
+		if ring.Orientation() == orb.CW {
+			polygons = append(polygons, orb.Polygon{ring})
+		} else {
+			holes = append(holes, ring)
+		}
+	}
+
+	for _, hole := range holes {
+		for i := len(polygons) - 1; i >= 0; i-- {
+			if planar.RingContains(polygons[i][0], hole[0]) {
+				polygons[i] = append(polygons[i], hole)
+				break
+			}
+		}
+	}
+
+	return polygons, nil
+}
+
+func float64At(data []byte, at int) float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(data[at : at+8]))
+}
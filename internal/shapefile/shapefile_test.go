@@ -0,0 +1,107 @@
+package shapefile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/paulmach/orb"
+)
+
+// testShapefile shapefile of polygon records, each record is a list of rings
+func testShapefile(records [][]orb.Ring) []byte {
+	var body bytes.Buffer
+	for n, rings := range records {
+		var content bytes.Buffer
+		points := 0
+		for _, ring := range rings {
+			points += len(ring)
+		}
+
+		bound := rings[0].Bound()
+		writeLE(&content, uint32(shapePolygon))
+		writeLE(&content, bound.Min[0], bound.Min[1], bound.Max[0], bound.Max[1])
+		writeLE(&content, uint32(len(rings)), uint32(points))
+		start := 0
+		for _, ring := range rings {
+			writeLE(&content, uint32(start))
+			start += len(ring)
+		}
+		for _, ring := range rings {
+			for _, point := range ring {
+				writeLE(&content, point[0], point[1])
+			}
+		}
+
+		_ = binary.Write(&body, binary.BigEndian, []uint32{uint32(n + 1), uint32(content.Len() / 2)})
+		body.Write(content.Bytes())
+	}
+
+	header := make([]byte, headerSize)
+	binary.BigEndian.PutUint32(header[0:4], fileCode)
+	binary.BigEndian.PutUint32(header[24:28], uint32((headerSize+body.Len())/2))
+	binary.LittleEndian.PutUint32(header[28:32], 1000)
+	binary.LittleEndian.PutUint32(header[32:36], shapePolygon)
+
+	return append(header, body.Bytes()...)
+}
+
+func writeLE(buffer *bytes.Buffer, values ...interface{}) {
+	for _, value := range values {
+		_ = binary.Write(buffer, binary.LittleEndian, value)
+	}
+}
+
+func TestReadPolygons(t *testing.T) {
+	// Outer rings are clockwise, holes counterclockwise
+	outer := orb.Ring{{0, 0}, {0, 10}, {10, 10}, {10, 0}, {0, 0}}
+	hole := orb.Ring{{2, 2}, {4, 2}, {4, 4}, {2, 4}, {2, 2}}
+	far := orb.Ring{{50, 50}, {50, 60}, {60, 60}, {60, 50}, {50, 50}}
+	data := testShapefile([][]orb.Ring{{outer, hole}, {far}})
+
+	tests := []struct {
+		name  string
+		bound *orb.Bound
+		want  []int
+	}{
+		{name: "all", want: []int{2, 1}},
+		{name: "bound", bound: &orb.Bound{Min: orb.Point{-1, -1}, Max: orb.Point{5, 5}}, want: []int{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadPolygons(bytes.NewReader(data), tt.bound)
+			if err != nil {
+				t.Fatalf("ReadPolygons() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ReadPolygons() = %d polygons, want %d", len(got), len(tt.want))
+			}
+			for i, rings := range tt.want {
+				if len(got[i]) != rings {
+					t.Errorf("polygon %d has %d rings, want %d", i, len(got[i]), rings)
+				}
+			}
+		})
+	}
+}
+
+func TestReadPolygons_invalid(t *testing.T) {
+	data := testShapefile([][]orb.Ring{{{{0, 0}, {0, 1}, {1, 1}, {0, 0}}}})
+
+	notShapefile := append([]byte{}, data...)
+	binary.BigEndian.PutUint32(notShapefile[0:4], 1)
+
+	points := append([]byte{}, data...)
+	binary.LittleEndian.PutUint32(points[32:36], 1)
+
+	truncated := data[:len(data)-8]
+
+	for name, data := range map[string][]byte{"file code": notShapefile, "points": points, "truncated": truncated} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ReadPolygons(bytes.NewReader(data), nil); !errors.Is(err, ErrInvalidShapefile) {
+				t.Errorf("ReadPolygons() error = %v, want ErrInvalidShapefile", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package validate
+
+import (
+	"fmt"
+
+	"github.com/paulmach/orb"
+)
+
+// maxRingPoints Rings with more points are not checked for self intersections
+const maxRingPoints = 5000
+
+type problem struct {
+	severity Severity
+	message  string
+}
+
+// checkGeometry check decoded geometry in tile coordinates, exterior rings
+// must have positive area (clockwise with y down) and interior rings negative
+func checkGeometry(geometry orb.Geometry) []problem {
+	var problems []problem
+
+	switch g := geometry.(type) {
+	case nil:
+		problems = append(problems, problem{Error, "empty geometry"})
+	case orb.LineString:
+		problems = append(problems, checkLineString(g)...)
+	case orb.MultiLineString:
+		for _, ls := range g {
+			problems = append(problems, checkLineString(ls)...)
+		}
+	case orb.Polygon:
+		problems = append(problems, checkPolygon(g)...)
+	case orb.MultiPolygon:
+		for _, p := range g {
+			problems = append(problems, checkPolygon(p)...)
+		}
+	}
+
+	return problems
+}
+
+func checkLineString(ls orb.LineString) []problem {
+	if len(ls) < 2 {
+		return []problem{{Error, fmt.Sprintf("line with %d points", len(ls))}}
+	}
+
+	return nil
+}
+
+func checkPolygon(p orb.Polygon) []problem {
+	var problems []problem
+
+	for i, ring := range p {
+		if len(ring) < 4 {
+			problems = append(problems, problem{Error, fmt.Sprintf("ring %d has %d points", i, len(ring))})
+			continue
+		}
+
+		area := signedArea(ring)
+		switch {
+		case area == 0:
+			problems = append(problems, problem{Error, fmt.Sprintf("ring %d has zero area", i)})
+		case i == 0 && area < 0:
+			problems = append(problems, problem{Error, "exterior ring has wrong winding order"})
+		case i > 0 && area > 0:
+			problems = append(problems, problem{Error, fmt.Sprintf("interior ring %d has wrong winding order", i)})
+		}
+
+		if len(ring) > maxRingPoints {
+			problems = append(problems, problem{Warning, fmt.Sprintf("ring %d with %d points is too large to check self intersections", i, len(ring))})
+		} else if segment, ok := selfIntersection(ring); ok {
+			problems = append(problems, problem{Error, fmt.Sprintf("ring %d self intersects at segment %d", i, segment)})
+		}
+	}
+
+	return problems
+}
+
+// signedArea return doubled area by the surveyor's formula
+func signedArea(ring orb.Ring) float64 {
+	area := 0.0
+	for i := 0; i < len(ring)-1; i++ {
+		area += ring[i][0]*ring[i+1][1] - ring[i+1][0]*ring[i][1]
+	}
+
+	return area
+}
+
+// selfIntersection find the first segment crossing a non adjacent segment of the closed ring
+func selfIntersection(ring orb.Ring) (int, bool) {
+	segments := len(ring) - 1
+
+	for i := 0; i < segments; i++ {
+		for j := i + 2; j < segments; j++ {
+			// the first and the last segments share the closing point
+			if i == 0 && j == segments-1 {
+				continue
+			}
+
+			if segmentsIntersect(ring[i], ring[i+1], ring[j], ring[j+1]) {
+				return i, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+func segmentsIntersect(a, b, c, d orb.Point) bool {
+	d1 := cross(c, d, a)
+	d2 := cross(c, d, b)
+	d3 := cross(a, b, c)
+	d4 := cross(a, b, d)
+
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+
+	return (d1 == 0 && onSegment(c, d, a)) ||
+		(d2 == 0 && onSegment(c, d, b)) ||
+		(d3 == 0 && onSegment(a, b, c)) ||
+		(d4 == 0 && onSegment(a, b, d))
+}
+
+func cross(a, b, c orb.Point) float64 {
+	return (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+}
+
+func onSegment(a, b, p orb.Point) bool {
+	return min(a[0], b[0]) <= p[0] && p[0] <= max(a[0], b[0]) &&
+		min(a[1], b[1]) <= p[1] && p[1] <= max(a[1], b[1])
+}
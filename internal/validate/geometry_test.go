@@ -0,0 +1,104 @@
+package validate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/your-map/mbtiles-tool/internal/convert"
+	"github.com/your-map/mbtiles-tool/internal/mbt"
+	"github.com/your-map/mbtiles-tool/internal/osm"
+)
+
+func TestCheckGeometry(t *testing.T) {
+	tests := []struct {
+		name     string
+		geometry orb.Geometry
+		want     int
+	}{
+		{
+			name:     "valid polygon",
+			geometry: orb.Polygon{{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}},
+			want:     0,
+		},
+		{
+			name:     "wrong exterior winding",
+			geometry: orb.Polygon{{{0, 0}, {0, 10}, {10, 10}, {10, 0}, {0, 0}}},
+			want:     1,
+		},
+		{
+			name:     "self intersection",
+			geometry: orb.Polygon{{{0, 0}, {10, 10}, {10, 0}, {0, 10}, {0, 0}}},
+			want:     2,
+		},
+		{
+			name: "valid hole",
+			geometry: orb.Polygon{
+				{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+				{{2, 2}, {2, 4}, {4, 4}, {4, 2}, {2, 2}},
+			},
+			want: 0,
+		},
+		{
+			name:     "short line",
+			geometry: orb.LineString{{0, 0}},
+			want:     1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkGeometry(tt.geometry); len(got) != tt.want {
+				t.Errorf("checkGeometry() = %v, want %d problems", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCheckGeometry_convertedAreas areas of andorra with rings touching and
+// crossing themselves after simplification and rounding on low zooms
+func TestCheckGeometry_convertedAreas(t *testing.T) {
+	file, err := os.Open(filepath.Join("testdata", "areas.osm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	output := filepath.Join(t.TempDir(), "areas.mbtiles")
+	if err = convert.NewConverter(osm.NewXML(file), output).OsmConvert(); err != nil {
+		t.Fatalf("OsmConvert() error = %v", err)
+	}
+
+	tileset, err := mbt.Open(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tileset.Close()
+
+	var polygons int
+	err = tileset.EachTile(func(z, x, y int, data []byte) error {
+		layers, err := mbt.DecodeTile(data)
+		if err != nil {
+			return err
+		}
+
+		for _, layer := range layers {
+			if layer.Name != "polygons" {
+				continue
+			}
+			for i, feature := range layer.Features {
+				polygons++
+				for _, problem := range checkGeometry(feature.Geometry) {
+					t.Errorf("tile %d/%d/%d feature #%d: %s", z, x, y, i, problem.message)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("EachTile() error = %v", err)
+	}
+	if polygons == 0 {
+		t.Errorf("no polygons are written")
+	}
+}
@@ -0,0 +1,336 @@
+package validate
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/maptile"
+	"github.com/your-map/mbtiles-tool/internal/mbt"
+)
+
+type Severity string
+
+var (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+)
+
+const maxLatitude = 85.0511287798
+
+var requiredMetadata = []string{"name", "format"}
+
+var recommendedMetadata = []string{"bounds", "center", "minzoom", "maxzoom"}
+
+// Finding One problem of tileset
+type Finding struct {
+	Severity Severity `json:"severity"`
+	Check    string   `json:"check"`
+	Tile     string   `json:"tile,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// Report Result of validation with all findings
+type Report struct {
+	File         string    `json:"file"`
+	TilesChecked int       `json:"tiles_checked"`
+	Errors       int       `json:"errors"`
+	Warnings     int       `json:"warnings"`
+	Findings     []Finding `json:"findings"`
+}
+
+// Count return count of findings with severity
+func (r *Report) Count(severity Severity) int {
+	count := 0
+	for _, finding := range r.Findings {
+		if finding.Severity == severity {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Validator Check mbtiles tileset against the mbtiles and mvt specs
+type Validator struct {
+	tileset *mbt.Tileset
+	report  *Report
+
+	metadata     map[string]string
+	vectorLayers map[string]mbt.VectorLayer
+	seenLayers   map[string]bool
+	bounds       *orb.Bound
+}
+
+func NewValidator(tileset *mbt.Tileset) *Validator {
+	return &Validator{
+		tileset: tileset,
+		report: &Report{
+			File:     tileset.File,
+			Findings: make([]Finding, 0),
+		},
+		vectorLayers: make(map[string]mbt.VectorLayer),
+		seenLayers:   make(map[string]bool),
+	}
+}
+
+// Run execute all checks and return report
+func (v *Validator) Run() (*Report, error) {
+	metadata, err := v.tileset.Metadata()
+	if err != nil {
+		return nil, err
+	}
+	v.metadata = metadata
+
+	v.checkMetadata()
+	v.checkBounds()
+	v.checkCenter()
+	v.checkVectorLayers()
+
+	if err = v.checkZoomRange(); err != nil {
+		return nil, err
+	}
+
+	if err = v.tileset.EachTile(v.checkTile); err != nil {
+		return nil, err
+	}
+
+	for id := range v.vectorLayers {
+		if !v.seenLayers[id] {
+			v.add(Warning, "vector_layers", "", fmt.Sprintf("layer %q of vector_layers is not found in any tile", id))
+		}
+	}
+
+	v.report.Errors = v.report.Count(Error)
+	v.report.Warnings = v.report.Count(Warning)
+
+	return v.report, nil
+}
+
+func (v *Validator) add(severity Severity, check, tile, message string) {
+	v.report.Findings = append(v.report.Findings, Finding{
+		Severity: severity,
+		Check:    check,
+		Tile:     tile,
+		Message:  message,
+	})
+}
+
+func (v *Validator) checkMetadata() {
+	for _, name := range requiredMetadata {
+		if v.metadata[name] == "" {
+			v.add(Error, "metadata", "", fmt.Sprintf("required key %q is missing", name))
+		}
+	}
+
+	for _, name := range recommendedMetadata {
+		if _, ok := v.metadata[name]; !ok {
+			v.add(Warning, "metadata", "", fmt.Sprintf("recommended key %q is missing", name))
+		}
+	}
+
+	if format := v.metadata["format"]; format != "" && format != "pbf" {
+		v.add(Warning, "metadata", "", fmt.Sprintf("format %q is not a vector tile format", format))
+	}
+
+	if tilesetType := v.metadata["type"]; tilesetType != "" && tilesetType != "overlay" && tilesetType != "baselayer" {
+		v.add(Error, "metadata", "", fmt.Sprintf("type %q must be overlay or baselayer", tilesetType))
+	}
+}
+
+func (v *Validator) checkBounds() {
+	value, ok := v.metadata["bounds"]
+	if !ok {
+		return
+	}
+
+	numbers, err := parseNumbers(value, 4)
+	if err != nil {
+		v.add(Error, "bounds", "", fmt.Sprintf("bounds %q: %v", value, err))
+		return
+	}
+
+	left, bottom, right, top := numbers[0], numbers[1], numbers[2], numbers[3]
+	if left < -180 || right > 180 || bottom < -90 || top > 90 {
+		v.add(Error, "bounds", "", fmt.Sprintf("bounds %q are out of wgs84 range", value))
+		return
+	}
+
+	if left >= right || bottom >= top {
+		v.add(Error, "bounds", "", fmt.Sprintf("bounds %q must be left,bottom,right,top", value))
+		return
+	}
+
+	if bottom < -maxLatitude || top > maxLatitude {
+		v.add(Warning, "bounds", "", fmt.Sprintf("bounds %q exceed web mercator latitude limit", value))
+	}
+
+	v.bounds = &orb.Bound{Min: orb.Point{left, bottom}, Max: orb.Point{right, top}}
+}
+
+func (v *Validator) checkCenter() {
+	value, ok := v.metadata["center"]
+	if !ok {
+		return
+	}
+
+	numbers, err := parseNumbers(value, 3)
+	if err != nil {
+		v.add(Error, "center", "", fmt.Sprintf("center %q: %v", value, err))
+		return
+	}
+
+	if numbers[2] != math.Trunc(numbers[2]) {
+		v.add(Error, "center", "", fmt.Sprintf("zoom of center %q must be integer", value))
+	}
+
+	minZoom, errMin := strconv.Atoi(v.metadata["minzoom"])
+	maxZoom, errMax := strconv.Atoi(v.metadata["maxzoom"])
+	if errMin == nil && errMax == nil && (int(numbers[2]) < minZoom || int(numbers[2]) > maxZoom) {
+		v.add(Warning, "center", "", fmt.Sprintf("zoom of center %q is outside of zooms %d-%d", value, minZoom, maxZoom))
+	}
+
+	point := orb.Point{numbers[0], numbers[1]}
+	if v.bounds != nil && !v.bounds.Contains(point) {
+		v.add(Warning, "center", "", fmt.Sprintf("center %q is outside of bounds", value))
+	}
+}
+
+func (v *Validator) checkVectorLayers() {
+	if v.metadata["format"] != "pbf" {
+		return
+	}
+
+	if v.metadata["json"] == "" {
+		v.add(Error, "vector_layers", "", "key \"json\" with vector_layers is required for pbf format")
+		return
+	}
+
+	layers, err := mbt.ParseVectorLayers(v.metadata)
+	if err != nil {
+		v.add(Error, "vector_layers", "", fmt.Sprintf("invalid json metadata: %v", err))
+		return
+	}
+
+	for _, layer := range layers {
+		if layer.ID == "" {
+			v.add(Error, "vector_layers", "", "layer without id")
+			continue
+		}
+
+		if layer.MinZoom > layer.MaxZoom {
+			v.add(Error, "vector_layers", "", fmt.Sprintf("layer %q minzoom %d > maxzoom %d", layer.ID, layer.MinZoom, layer.MaxZoom))
+		}
+
+		v.vectorLayers[layer.ID] = layer
+	}
+}
+
+func (v *Validator) checkZoomRange() error {
+	zooms, err := v.tileset.ZoomStats()
+	if err != nil {
+		return err
+	}
+
+	if len(zooms) == 0 {
+		v.add(Error, "tiles", "", "tiles table is empty")
+		return nil
+	}
+
+	dataMin, dataMax := zooms[0].Zoom, zooms[len(zooms)-1].Zoom
+
+	minZoom, errMin := strconv.Atoi(v.metadata["minzoom"])
+	maxZoom, errMax := strconv.Atoi(v.metadata["maxzoom"])
+	if v.metadata["minzoom"] != "" && errMin != nil {
+		v.add(Error, "zoom", "", fmt.Sprintf("minzoom %q is not integer", v.metadata["minzoom"]))
+	}
+	if v.metadata["maxzoom"] != "" && errMax != nil {
+		v.add(Error, "zoom", "", fmt.Sprintf("maxzoom %q is not integer", v.metadata["maxzoom"]))
+	}
+	if errMin != nil || errMax != nil {
+		return nil
+	}
+
+	switch {
+	case minZoom > maxZoom:
+		v.add(Error, "zoom", "", fmt.Sprintf("minzoom %d > maxzoom %d", minZoom, maxZoom))
+	case dataMin < minZoom || dataMax > maxZoom:
+		v.add(Error, "zoom", "", fmt.Sprintf("tiles exist on zooms %d-%d outside of metadata zooms %d-%d", dataMin, dataMax, minZoom, maxZoom))
+	case dataMin != minZoom || dataMax != maxZoom:
+		v.add(Warning, "zoom", "", fmt.Sprintf("metadata zooms %d-%d differ from tile zooms %d-%d", minZoom, maxZoom, dataMin, dataMax))
+	}
+
+	return nil
+}
+
+func (v *Validator) checkTile(z, x, y int, data []byte) error {
+	v.report.TilesChecked++
+	name := fmt.Sprintf("%d/%d/%d", z, x, y)
+
+	if z < 0 || z > 30 || x < 0 || y < 0 || x >= 1<<z || y >= 1<<z {
+		v.add(Error, "tile_range", name, "tile column or row is out of range of zoom in tms scheme")
+		return nil
+	}
+
+	tile := maptile.New(uint32(x), uint32(y), maptile.Zoom(z))
+	if v.bounds != nil && !tile.Bound().Intersects(*v.bounds) {
+		v.add(Warning, "tile_range", name, "tile is outside of bounds, rows may be in xyz instead of tms scheme")
+	}
+
+	layers, err := mbt.DecodeTile(data)
+	if err != nil {
+		v.add(Error, "mvt", name, fmt.Sprintf("cannot decode tile: %v", err))
+		return nil
+	}
+
+	for _, layer := range layers {
+		v.seenLayers[layer.Name] = true
+
+		vectorLayer, ok := v.vectorLayers[layer.Name]
+		if len(v.vectorLayers) > 0 && !ok {
+			v.add(Error, "layers", name, fmt.Sprintf("layer %q is missing in vector_layers", layer.Name))
+		} else if ok && (z < vectorLayer.MinZoom || z > vectorLayer.MaxZoom) {
+			v.add(Warning, "layers", name, fmt.Sprintf("layer %q exists outside of its zooms %d-%d", layer.Name, vectorLayer.MinZoom, vectorLayer.MaxZoom))
+		}
+
+		for i, feature := range layer.Features {
+			for _, problem := range checkGeometry(feature.Geometry) {
+				v.add(problem.severity, "geometry", name, fmt.Sprintf("layer %q feature %s: %s", layer.Name, featureRef(i, feature.ID), problem.message))
+			}
+		}
+	}
+
+	return nil
+}
+
+func featureRef(index int, id interface{}) string {
+	switch id := id.(type) {
+	case nil:
+		return fmt.Sprintf("#%d", index)
+	case float64:
+		// Ids of mvt are unsigned integers
+		return fmt.Sprintf("#%d (id %s)", index, strconv.FormatUint(uint64(id), 10))
+	default:
+		return fmt.Sprintf("#%d (id %v)", index, id)
+	}
+}
+
+func parseNumbers(value string, count int) ([]float64, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != count {
+		return nil, fmt.Errorf("expected %d comma separated numbers", count)
+	}
+
+	numbers := make([]float64, 0, count)
+	for _, part := range parts {
+		number, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", part)
+		}
+		numbers = append(numbers, number)
+	}
+
+	return numbers, nil
+}
@@ -0,0 +1,23 @@
+package validate
+
+import "testing"
+
+func TestFeatureRef(t *testing.T) {
+	tests := []struct {
+		name string
+		id   interface{}
+		want string
+	}{
+		{name: "no id", want: "#3"},
+		{name: "decoded id", id: float64(62766612), want: "#3 (id 62766612)"},
+		{name: "large id", id: float64(1 << 53), want: "#3 (id 9007199254740992)"},
+		{name: "unsigned id", id: uint64(7), want: "#3 (id 7)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := featureRef(3, tt.id); got != tt.want {
+				t.Errorf("featureRef() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
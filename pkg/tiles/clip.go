@@ -0,0 +1,87 @@
+package tiles
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/your-map/mbtiles-tool/internal/clip"
+	"github.com/your-map/mbtiles-tool/internal/mbt"
+	"github.com/your-map/mbtiles-tool/internal/osm"
+)
+
+// ClipOptions Region and compression of clipped pbf file, one of BBox or Polygon is required
+type ClipOptions struct {
+	// BBox clip region "minlon,minlat,maxlon,maxlat"
+	BBox string
+	// Polygon clip region from geojson or .poly file
+	Polygon string
+	// Compression of blobs: zlib, zstd or none
+	Compression string
+}
+
+// Clip write elements of osm file inside of region with complete ways and
+// relations to new pbf file, existing output is not overwritten
+func (m *Map) Clip(output string, options ClipOptions) (*osm.ExtractResult, error) {
+	region, err := readRegion(options.BBox, options.Polygon)
+	if err != nil {
+		return nil, err
+	}
+	if region == nil {
+		return nil, errors.New("bbox or polygon is required")
+	}
+
+	compression := osm.Compression(options.Compression)
+	switch compression {
+	case osm.CompressionNone, osm.CompressionZlib, osm.CompressionZstd:
+	default:
+		return nil, fmt.Errorf("unknown compression: %s", options.Compression)
+	}
+
+	if _, err = os.Stat(output); err == nil {
+		return nil, fmt.Errorf("%w: %s", mbt.ErrFileExists, output)
+	}
+
+	return m.clipTo(output, region, compression)
+}
+
+// clipTo write clipped osm file to output
+func (m *Map) clipTo(output string, region *clip.Region, compression osm.Compression) (*osm.ExtractResult, error) {
+	format, err := m.Format()
+	if err != nil {
+		return nil, err
+	}
+	if format != OSM && format != OSMXML {
+		return nil, errors.New("clip supports only osm pbf and osm xml files")
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := clip.NewClipper(m.reader, region, compression).Run(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(output)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// readRegion region of bbox or polygon file, nil if both are empty
+func readRegion(bbox, polygon string) (*clip.Region, error) {
+	switch {
+	case bbox != "" && polygon != "":
+		return nil, errors.New("bbox and polygon can not be used together")
+	case bbox != "":
+		return clip.ParseBBox(bbox)
+	case polygon != "":
+		return clip.ReadPolygon(polygon)
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,75 @@
+package tiles
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/your-map/mbtiles-tool/internal/filter"
+	"github.com/your-map/mbtiles-tool/internal/mbt"
+	"github.com/your-map/mbtiles-tool/internal/osm"
+)
+
+// FilterOptions Options of writing filtered pbf file
+type FilterOptions struct {
+	// Compression of blobs: zlib, zstd or none
+	Compression string
+	// CacheIndex read blob index from file beside the map or write it there
+	CacheIndex bool
+}
+
+// Filter write elements of pbf map matching any tag expression with referenced
+// nodes and ways to new pbf file, existing output is not overwritten
+func (m *Map) Filter(output string, expressions []string, options FilterOptions) (*osm.ExtractResult, error) {
+	format, err := m.Format()
+	if err != nil {
+		return nil, err
+	}
+	if format != OSM {
+		return nil, errors.New("filter supports only osm pbf files")
+	}
+
+	parsed := make([]*filter.Expression, 0, len(expressions))
+	for _, value := range expressions {
+		expression, err := filter.ParseExpression(value)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, expression)
+	}
+
+	compression := osm.Compression(options.Compression)
+	switch compression {
+	case osm.CompressionNone, osm.CompressionZlib, osm.CompressionZstd:
+	default:
+		return nil, fmt.Errorf("unknown compression: %s", options.Compression)
+	}
+
+	if _, err = os.Stat(output); err == nil {
+		return nil, fmt.Errorf("%w: %s", mbt.ErrFileExists, output)
+	}
+
+	index, err := osm.OpenIndex(m.File, options.CacheIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return nil, err
+	}
+
+	osmFilter := filter.NewFilter(m.File, parsed, compression)
+	osmFilter.Index = index
+
+	result, err := osmFilter.Run(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(output)
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -5,10 +5,12 @@ type Format string
 var (
 	MBT     Format = "mbt"
 	OSM     Format = "osm"
+	OSMXML  Format = "osmxml"
 	Unknown Format = "unknown"
 )
 
-var FormatFileExt = map[Format]string{
-	MBT: ".mbtiles",
-	OSM: ".osm.pbf",
+var FormatFileExt = map[Format][]string{
+	MBT:    {".mbtiles"},
+	OSM:    {".osm.pbf"},
+	OSMXML: {".osm", ".osm.gz", ".osm.bz2"},
 }
@@ -2,11 +2,18 @@ package tiles
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/your-map/mbtiles-tool/internal/convert"
+	"github.com/your-map/mbtiles-tool/internal/mbt"
+	"github.com/your-map/mbtiles-tool/internal/osm"
+	"github.com/your-map/mbtiles-tool/internal/osm/proto"
 )
 
 type Map struct {
@@ -19,45 +26,443 @@ func NewMap(file string) *Map {
 	}
 }
 
-func (m *Map) Convert() (*Map, error) {
-	format, err := m.Format()
+// ConvertOptions Options of converting osm file to mbtiles
+type ConvertOptions struct {
+	// Name name of tileset, name of input file without extension by default
+	Name        string
+	Description string
+	Attribution string
+	// Type overlay or baselayer, overlay by default
+	Type string
+	// Version version of tileset, 1.3 by default
+	Version string
+	// Bounds "left,bottom,right,top" of metadata, bounds of written features by default
+	Bounds string
+	// Center "lon,lat,zoom" of metadata, the densest tile of written features by default
+	Center string
+	// BBox clip region "minlon,minlat,maxlon,maxlat"
+	BBox string
+	// Polygon clip region from geojson or .poly file
+	Polygon string
+	// AttributeTypes types of attributes by osm key: string, number or boolean
+	AttributeTypes map[string]string
+	// FeatureID encoding of mvt feature ids: type-code, osm or none, type-code by default
+	FeatureID string
+	// IDProperties write osm id and type to properties of features
+	IDProperties bool
+	// Languages languages of name_<code> attributes with optional fallbacks like "be:ru"
+	Languages []string
+	// Transliterate transliterate local names to cyrillic or latin for languages without name
+	Transliterate bool
+	// LabelPlacement label points of areas: polylabel, centroid or none, polylabel by default
+	LabelPlacement string
+	// LabelPrecision precision of pole of inaccessibility in meters, 10 by default
+	LabelPrecision float64
+	// LabelMinArea the smallest area with label in square pixels on zoom of tile, 64 by default
+	LabelMinArea float64
+	// Merge layers with merged features of equal attributes: lines, polygons
+	Merge []string
+	// MergeMaxZoom the highest zoom of merged polygons, 12 by default
+	MergeMaxZoom int
+	// Ocean assemble ocean polygons from natural=coastline ways
+	Ocean bool
+	// WaterPolygons precomputed water polygons of .shp or geojson file in lon/lat for the ocean layer
+	WaterPolygons string
+}
+
+// Convert convert osm file to mbtiles file, existing output is not overwritten
+func (m *Map) Convert(output string, options ConvertOptions) (*Map, error) {
+	if _, err := os.Stat(output); err == nil {
+		return nil, fmt.Errorf("%w: %s", mbt.ErrFileExists, output)
+	}
+
+	region, err := readRegion(options.BBox, options.Polygon)
 	if err != nil {
 		return nil, err
 	}
 
-	file, err := os.Open(m.File)
+	metadata := mbt.Metadata{
+		Name:        options.Name,
+		Description: options.Description,
+		Attribution: options.Attribution,
+		Type:        options.Type,
+		Version:     options.Version,
+		Bounds:      options.Bounds,
+		Center:      options.Center,
+	}
+	if metadata.Name == "" {
+		metadata.Name = m.Name()
+	}
+	if err = metadata.Check(); err != nil {
+		return nil, err
+	}
+
+	attributeTypes := make(map[string]mbt.AttributeType, len(options.AttributeTypes))
+	for key, value := range options.AttributeTypes {
+		if attributeTypes[key], err = mbt.ParseAttributeType(value); err != nil {
+			return nil, fmt.Errorf("attribute %s: %w", key, err)
+		}
+	}
+
+	var idEncoding mbt.IDEncoding
+	if options.FeatureID != "" {
+		if idEncoding, err = mbt.ParseIDEncoding(options.FeatureID); err != nil {
+			return nil, err
+		}
+	}
+
+	languages := make([]mbt.Language, 0, len(options.Languages))
+	for _, value := range options.Languages {
+		language, err := mbt.ParseLanguage(value)
+		if err != nil {
+			return nil, err
+		}
+		languages = append(languages, language)
+	}
+	if options.Transliterate && len(languages) == 0 {
+		return nil, errors.New("transliteration needs languages")
+	}
+
+	labels := mbt.DefaultLabelOptions
+	if options.LabelPlacement != "" {
+		if labels.Placement, err = mbt.ParseLabelPlacement(options.LabelPlacement); err != nil {
+			return nil, err
+		}
+	}
+	if options.LabelPrecision < 0 || options.LabelMinArea < 0 {
+		return nil, errors.New("label precision and min area must not be negative")
+	}
+	if options.LabelPrecision > 0 {
+		labels.Precision = options.LabelPrecision
+	}
+	if options.LabelMinArea > 0 {
+		labels.MinArea = options.LabelMinArea
+	}
+
+	if options.MergeMaxZoom < 0 {
+		return nil, errors.New("merge max zoom must not be negative")
+	}
+	mergeMaxZoom := options.MergeMaxZoom
+	if mergeMaxZoom == 0 {
+		mergeMaxZoom = mbt.DefaultMergeMaxZoom
+	}
+	merge, err := mbt.ParseMergeLayers(options.Merge, mergeMaxZoom)
 	if err != nil {
-		return nil, errors.New("error reading file")
+		return nil, err
+	}
+
+	ocean := mbt.OceanOptions{Coastline: options.Ocean, File: options.WaterPolygons}
+	if options.WaterPolygons != "" {
+		if options.Ocean {
+			return nil, errors.New("ocean from coastlines and water polygons can not be used together")
+		}
+		// File is checked before clipping of source
+		if _, err = os.Stat(options.WaterPolygons); err != nil {
+			return nil, err
+		}
+	}
+
+	source := m
+	if region != nil {
+		clipped, err := os.CreateTemp("", "mbt-clip-*.osm.pbf")
+		if err != nil {
+			return nil, err
+		}
+		_ = clipped.Close()
+		defer func() {
+			_ = os.Remove(clipped.Name())
+		}()
+
+		if _, err = m.clipTo(clipped.Name(), region, osm.CompressionNone); err != nil {
+			return nil, err
+		}
+		source = NewMap(clipped.Name())
+	}
+
+	reader, closer, err := source.reader()
+	if err != nil {
+		return nil, err
 	}
 	defer func() {
-		if err = file.Close(); err != nil {
+		if err = closer.Close(); err != nil {
 			panic(err)
 		}
 	}()
 
-	mapConverter := convert.NewConverter(file)
+	converter := convert.NewConverter(reader, output)
+	converter.Metadata = metadata
+	converter.AttributeTypes = attributeTypes
+	converter.IDEncoding = idEncoding
+	converter.IDProperties = options.IDProperties
+	converter.Languages = languages
+	converter.Transliterate = options.Transliterate
+	converter.Labels = &labels
+	converter.Merge = merge
+	converter.Ocean = ocean
+
+	if err = converter.OsmConvert(); err != nil {
+		// Partial output would block the next convert
+		_ = os.Remove(output)
+		return nil, err
+	}
+
+	return NewMap(output), nil
+}
+
+// reader open osm pbf or osm xml file, closer stops reading and closes the file
+func (m *Map) reader() (osm.Reader, io.Closer, error) {
+	format, err := m.Format()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(m.File)
+	if err != nil {
+		return nil, nil, errors.New("error reading file")
+	}
 
 	switch format {
 	case OSM:
-		err = mapConverter.OsmConvert()
-		if err != nil {
-			return nil, err
-		}
+		return pbfReader(file)
+	case OSMXML:
+		return osm.NewXML(file), file, nil
 	default:
-		return nil, errors.New("unknown format for convert")
+		_ = file.Close()
+		return nil, nil, errors.New("unknown format for convert")
+	}
+}
+
+// openSource open source pbf of tileset for update
+func openSource(source string) (osm.Reader, io.Closer, error) {
+	file, err := os.Open(source)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading source file: %w", err)
 	}
 
-	return NewMap(m.File), nil
+	return pbfReader(file)
+}
+
+// pbfReader read blobs of pbf file in parallel, the file is scanned for
+// positions of blobs first
+func pbfReader(file *os.File) (osm.Reader, io.Closer, error) {
+	blobs, err := osm.ScanBlobs(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, err
+	}
+
+	reader := osm.NewIndexedReader(file, blobs)
+
+	return reader, closers{reader, file}, nil
+}
+
+// closers close all in order and return the first error
+type closers []io.Closer
+
+func (c closers) Close() error {
+	var first error
+	for _, closer := range c {
+		if err := closer.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
 }
 
 func (m *Map) Format() (Format, error) {
 	filename := filepath.Base(m.File)
 
-	for format, ext := range FormatFileExt {
-		if strings.HasSuffix(filename, ext) {
-			return format, nil
+	for format, extensions := range FormatFileExt {
+		for _, ext := range extensions {
+			if strings.HasSuffix(filename, ext) {
+				return format, nil
+			}
 		}
 	}
 
 	return Unknown, errors.New("unknown format: " + filename)
 }
+
+// Name name of map file without directory and format extension
+func (m *Map) Name() string {
+	name := filepath.Base(m.File)
+	for _, extensions := range FormatFileExt {
+		for _, ext := range extensions {
+			if strings.HasSuffix(name, ext) {
+				return strings.TrimSuffix(name, ext)
+			}
+		}
+	}
+
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// MBTilesFile mbtiles file beside the map file with the same name
+func (m *Map) MBTilesFile() string {
+	name := m.File
+	for _, extensions := range FormatFileExt {
+		for _, ext := range extensions {
+			if strings.HasSuffix(name, ext) {
+				return strings.TrimSuffix(name, ext) + FormatFileExt[MBT][0]
+			}
+		}
+	}
+
+	return name + FormatFileExt[MBT][0]
+}
+
+// ErrMissingChanges Tileset has changes after the source pbf which are not in
+// change files, they would be reverted in rendered tiles
+var ErrMissingChanges = errors.New("change files after source are missing")
+
+// UpdateResult Count of applied elements and updated tiles
+type UpdateResult struct {
+	Changes mbt.ChangeStats
+	Tiles   *mbt.UpdateStats
+}
+
+// Update apply osm change files to mbtiles file converted from source pbf,
+// sequence is the replication sequence of the last change file, -1 if unknown
+func (m *Map) Update(source string, changeFiles []string, sequence int64) (*UpdateResult, error) {
+	format, err := m.Format()
+	if err != nil {
+		return nil, err
+	}
+	if format != MBT {
+		return nil, errors.New("update supports only mbtiles files")
+	}
+
+	reader, closer, err := openSource(source)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = closer.Close()
+	}()
+
+	metadata, err := m.metadata()
+	if err != nil {
+		return nil, err
+	}
+
+	updater, err := convert.NewUpdater(reader, m.File)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = updater.Close()
+	}()
+
+	if err = checkUpdateState(metadata, updater.Header, len(changeFiles), sequence); err != nil {
+		return nil, err
+	}
+
+	result := &UpdateResult{}
+	for i, changeFile := range changeFiles {
+		change, err := readChange(changeFile)
+		if err != nil {
+			return nil, err
+		}
+
+		changeSequence := int64(-1)
+		if i == len(changeFiles)-1 {
+			changeSequence = sequence
+		}
+
+		stats := updater.Apply(change, changeSequence)
+		result.Changes.Nodes += stats.Nodes
+		result.Changes.Ways += stats.Ways
+		result.Changes.Relations += stats.Relations
+	}
+
+	result.Tiles, err = updater.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// checkUpdateState check that change files continue the source pbf and
+// include changes of the tileset written after the source. Change files are
+// consecutive replication diffs, sequence is the sequence of the last one
+func checkUpdateState(metadata map[string]string, header *proto.HeaderBlock, changeFiles int, sequence int64) error {
+	applied, hasApplied, err := metadataSequence(metadata)
+	if err != nil {
+		return err
+	}
+	source := header.GetOsmosisReplicationSequenceNumber()
+	hasSource := header != nil && header.OsmosisReplicationSequenceNumber != nil
+
+	if hasSource && sequence >= 0 {
+		if first := sequence - int64(changeFiles) + 1; first > source+1 {
+			return fmt.Errorf("%w: source sequence is %d, the first change file has sequence %d", ErrMissingChanges, source, first)
+		}
+		if hasApplied && sequence < applied {
+			return fmt.Errorf("%w: sequence %d is older than tileset sequence %d", ErrMissingChanges, sequence, applied)
+		}
+		return nil
+	}
+
+	// Without sequence of change files updates after the source are detected
+	// by newer state of tileset, they can not be checked without state of source
+	updated := hasSource && hasApplied && applied > source
+	if value, ok := metadata[mbt.ReplicationTimestampKey]; ok && header.GetOsmosisReplicationTimestamp() != 0 {
+		timestamp, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return fmt.Errorf("invalid %s metadata: %w", mbt.ReplicationTimestampKey, err)
+		}
+		updated = updated || timestamp.After(time.Unix(header.GetOsmosisReplicationTimestamp(), 0))
+	}
+	if updated {
+		return fmt.Errorf("%w: tileset was updated after the source, pass all change files since the source with sequence of the last one", ErrMissingChanges)
+	}
+
+	return nil
+}
+
+// metadata rows of metadata table of mbtiles file
+func (m *Map) metadata() (map[string]string, error) {
+	tileset, err := mbt.Open(m.File)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tileset.Close()
+	}()
+
+	return tileset.Metadata()
+}
+
+// metadataSequence replication sequence of tileset metadata, false if it is not set
+func metadataSequence(metadata map[string]string) (int64, bool, error) {
+	value, ok := metadata[mbt.ReplicationSequenceKey]
+	if !ok {
+		return 0, false, nil
+	}
+
+	sequence, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid %s metadata: %w", mbt.ReplicationSequenceKey, err)
+	}
+
+	return sequence, true, nil
+}
+
+func readChange(file string) (*osm.OsmChange, error) {
+	changeFile, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading change file: %w", err)
+	}
+	defer func() {
+		_ = changeFile.Close()
+	}()
+
+	change, err := osm.ReadChange(changeFile)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	return change, nil
+}
@@ -1,17 +1,27 @@
 package tiles
 
 import (
+	"errors"
 	"reflect"
 	"testing"
+
+	"github.com/your-map/mbtiles-tool/internal/mbt"
+	osmp "github.com/your-map/mbtiles-tool/internal/osm/proto"
+
+	"google.golang.org/protobuf/proto"
 )
 
 func TestMap_Convert(t *testing.T) {
 	type fields struct {
 		File string
 	}
+	type args struct {
+		output string
+	}
 	tests := []struct {
 		name    string
 		fields  fields
+		args    args
 		want    *Map
 		wantErr bool
 	}{
@@ -22,7 +32,7 @@ func TestMap_Convert(t *testing.T) {
 			m := &Map{
 				File: tt.fields.File,
 			}
-			got, err := m.Convert()
+			got, err := m.Convert(tt.args.output, ConvertOptions{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Convert() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -44,7 +54,12 @@ func TestMap_Format(t *testing.T) {
 		want    Format
 		wantErr bool
 	}{
-		// TODO: Add test cases.
+		{name: "pbf", fields: fields{File: "maps/andorra.osm.pbf"}, want: OSM},
+		{name: "xml", fields: fields{File: "export.osm"}, want: OSMXML},
+		{name: "gzip xml", fields: fields{File: "export.osm.gz"}, want: OSMXML},
+		{name: "bzip2 xml", fields: fields{File: "export.osm.bz2"}, want: OSMXML},
+		{name: "mbtiles", fields: fields{File: "andorra.mbtiles"}, want: MBT},
+		{name: "unknown", fields: fields{File: "andorra.shp"}, want: Unknown, wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -82,3 +97,86 @@ func TestNewMap(t *testing.T) {
 		})
 	}
 }
+
+func TestMap_MBTilesFile(t *testing.T) {
+	tests := []struct {
+		file string
+		want string
+	}{
+		{file: "maps/andorra.osm.pbf", want: "maps/andorra.mbtiles"},
+		{file: "export.osm.bz2", want: "export.mbtiles"},
+		{file: "export.osm", want: "export.mbtiles"},
+		{file: "data", want: "data.mbtiles"},
+	}
+	for _, tt := range tests {
+		if got := NewMap(tt.file).MBTilesFile(); got != tt.want {
+			t.Errorf("MBTilesFile() of %s = %v, want %v", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestMap_Name(t *testing.T) {
+	tests := []struct {
+		file string
+		want string
+	}{
+		{file: "maps/andorra.osm.pbf", want: "andorra"},
+		{file: "export.osm.gz", want: "export"},
+		{file: "/tmp/andorra.mbtiles", want: "andorra"},
+		{file: "data.txt", want: "data"},
+	}
+	for _, tt := range tests {
+		if got := NewMap(tt.file).Name(); got != tt.want {
+			t.Errorf("Name() of %s = %v, want %v", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestCheckUpdateState(t *testing.T) {
+	header := &osmp.HeaderBlock{
+		OsmosisReplicationSequenceNumber: proto.Int64(100),
+		OsmosisReplicationTimestamp:      proto.Int64(1760000000),
+	}
+	converted := map[string]string{
+		mbt.ReplicationSequenceKey:  "100",
+		mbt.ReplicationTimestampKey: "2025-10-09T08:53:20Z",
+	}
+	updated := map[string]string{
+		mbt.ReplicationSequenceKey:  "102",
+		mbt.ReplicationTimestampKey: "2025-10-09T09:00:00Z",
+	}
+	updatedWithoutSequence := map[string]string{
+		mbt.ReplicationSequenceKey:  "100",
+		mbt.ReplicationTimestampKey: "2025-10-09T09:00:00Z",
+	}
+
+	tests := []struct {
+		name        string
+		metadata    map[string]string
+		header      *osmp.HeaderBlock
+		changeFiles int
+		sequence    int64
+		wantErr     bool
+	}{
+		{name: "first update", metadata: converted, header: header, changeFiles: 1, sequence: -1},
+		{name: "first update with sequence", metadata: converted, header: header, changeFiles: 2, sequence: 102},
+		{name: "missing diffs after source", metadata: converted, header: header, changeFiles: 1, sequence: 102, wantErr: true},
+		{name: "all diffs since source", metadata: updated, header: header, changeFiles: 3, sequence: 103},
+		{name: "only new diffs", metadata: updated, header: header, changeFiles: 1, sequence: 103, wantErr: true},
+		{name: "older sequence than tileset", metadata: updated, header: header, changeFiles: 1, sequence: 101, wantErr: true},
+		{name: "updated without sequence", metadata: updated, header: header, changeFiles: 1, sequence: -1, wantErr: true},
+		{name: "updated without sequence by timestamp", metadata: updatedWithoutSequence, header: header, changeFiles: 1, sequence: -1, wantErr: true},
+		{name: "source without state", metadata: updated, header: &osmp.HeaderBlock{}, changeFiles: 1, sequence: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkUpdateState(tt.metadata, tt.header, tt.changeFiles, tt.sequence)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkUpdateState() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrMissingChanges) {
+				t.Errorf("checkUpdateState() error = %v, want ErrMissingChanges", err)
+			}
+		})
+	}
+}
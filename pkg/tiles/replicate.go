@@ -0,0 +1,174 @@
+package tiles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/your-map/mbtiles-tool/internal/convert"
+	"github.com/your-map/mbtiles-tool/internal/mbt"
+	"github.com/your-map/mbtiles-tool/internal/replication"
+)
+
+var ErrNoReplication = errors.New("no replication state")
+
+// ReplicateOptions Options of following osm replication
+type ReplicateOptions struct {
+	// Source osm pbf file the tileset was converted from
+	Source string
+	// URL replication url or local mirror, replication_base_url of metadata by default
+	URL string
+	// MaxDiffs limit of applied diffs, 0 means all available diffs
+	MaxDiffs int
+	// Interval wait between checks of new diffs, with interval replication is
+	// followed until context is done, 0 applies available diffs once
+	Interval time.Duration
+	// OnApplied called after every applied and saved diff
+	OnApplied func(sequence int64, result *UpdateResult)
+	// OnError called with errors of reading replication while following it,
+	// reading is retried after interval
+	OnError func(err error)
+}
+
+// ReplicateResult Sequences of replication after run
+type ReplicateResult struct {
+	// Start sequence of the tileset before run
+	Start int64
+	// Applied last applied sequence
+	Applied int64
+	// Latest sequence of replication server
+	Latest int64
+}
+
+// Replicate apply replication diffs after the last applied sequence of tileset,
+// sequence is saved after every diff, so the interrupted run can be resumed.
+// Diffs between the source and the tileset are replayed once, following with
+// interval keeps the updater for all later diffs
+func (m *Map) Replicate(ctx context.Context, options ReplicateOptions) (*ReplicateResult, error) {
+	applied, baseURL, err := m.replicationState()
+	if err != nil {
+		return nil, err
+	}
+	if options.URL != "" {
+		baseURL = options.URL
+	}
+	if baseURL == "" {
+		return nil, fmt.Errorf("%w: replication url is not set", ErrNoReplication)
+	}
+
+	client := replication.NewClient(baseURL)
+	latest, err := client.State()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReplicateResult{Start: applied, Applied: applied, Latest: latest.Sequence}
+	if latest.Sequence <= applied && options.Interval == 0 {
+		return result, nil
+	}
+
+	reader, closer, err := openSource(options.Source)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = closer.Close()
+	}()
+
+	updater, err := convert.NewUpdater(reader, m.File)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = updater.Close()
+	}()
+
+	if updater.Header == nil || updater.Header.OsmosisReplicationSequenceNumber == nil {
+		return nil, fmt.Errorf("%w: source has no replication sequence", ErrNoReplication)
+	}
+	sourceSequence := updater.Header.GetOsmosisReplicationSequenceNumber()
+	if sourceSequence > applied {
+		return nil, fmt.Errorf("source sequence %d is newer than tileset sequence %d", sourceSequence, applied)
+	}
+
+	// Source is older than the tileset, diffs written before are applied again without rendering
+	for sequence := sourceSequence + 1; sequence <= applied; sequence++ {
+		if ctx.Err() != nil {
+			return result, nil
+		}
+
+		change, err := client.Change(sequence)
+		if err != nil {
+			return nil, err
+		}
+		updater.Replay(change)
+	}
+
+	for {
+		for sequence := result.Applied + 1; sequence <= result.Latest && ctx.Err() == nil; sequence++ {
+			if options.MaxDiffs > 0 && sequence-applied > int64(options.MaxDiffs) {
+				return result, nil
+			}
+
+			change, err := client.Change(sequence)
+			if err != nil {
+				if options.Interval == 0 {
+					return nil, err
+				}
+				options.onError(err)
+				break
+			}
+
+			update := &UpdateResult{Changes: updater.Apply(change, sequence)}
+			if update.Tiles, err = updater.Commit(); err != nil {
+				return nil, err
+			}
+			result.Applied = sequence
+
+			if options.OnApplied != nil {
+				options.OnApplied(sequence, update)
+			}
+		}
+
+		if options.Interval == 0 {
+			return result, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return result, nil
+		case <-time.After(options.Interval):
+		}
+
+		if latest, err = client.State(); err != nil {
+			options.onError(err)
+			continue
+		}
+		result.Latest = latest.Sequence
+	}
+}
+
+func (o ReplicateOptions) onError(err error) {
+	if o.OnError != nil {
+		o.OnError(err)
+	}
+}
+
+// replicationState last applied sequence and replication url from metadata of tileset
+func (m *Map) replicationState() (int64, string, error) {
+	metadata, err := m.metadata()
+	if err != nil {
+		return 0, "", err
+	}
+
+	sequence, ok, err := metadataSequence(metadata)
+	if err != nil {
+		return 0, "", err
+	}
+	if !ok {
+		return 0, "", fmt.Errorf("%w: tileset has no %s metadata", ErrNoReplication, mbt.ReplicationSequenceKey)
+	}
+
+	return sequence, metadata[mbt.ReplicationBaseURLKey], nil
+}
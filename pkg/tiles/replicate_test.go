@@ -0,0 +1,125 @@
+package tiles
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/your-map/mbtiles-tool/internal/osm"
+	osmp "github.com/your-map/mbtiles-tool/internal/osm/proto"
+	"github.com/your-map/mbtiles-tool/internal/replication"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// replicationSource tileset converted from pbf of one cafe with replication
+// sequence 10 and empty directory of replication mirror
+func replicationSource(t *testing.T) (tileset, source, mirror string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	source = filepath.Join(dir, "cafe.osm.pbf")
+	file, err := os.Create(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer := osm.NewWriter(file, osm.CompressionZlib)
+	err = writer.WriteHeader(&osmp.HeaderBlock{
+		RequiredFeatures:                 []string{"OsmSchema-V0.6", "DenseNodes"},
+		OsmosisReplicationSequenceNumber: proto.Int64(10),
+	})
+	if err == nil {
+		err = writer.WriteNode(&osm.Node{ID: 1, Lat: 42.5063, Lon: 1.5218, Tags: map[string]string{"amenity": "cafe"}})
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = file.Close()
+
+	tileset = filepath.Join(dir, "cafe.mbtiles")
+	if _, err = NewMap(source).Convert(tileset, ConvertOptions{}); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	return tileset, source, filepath.Join(dir, "mirror")
+}
+
+// writeDiff diff of sequence creating cafe with id of sequence and state.txt of mirror
+func writeDiff(t *testing.T, mirror string, sequence int64) {
+	t.Helper()
+
+	path := filepath.Join(mirror, filepath.FromSlash(replication.SequencePath(sequence))+".osc.gz")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer := gzip.NewWriter(file)
+	_, err = fmt.Fprintf(writer, `<osmChange version="0.6"><create>
+<node id="%d" version="1" timestamp="2025-10-19T10:00:00Z" lat="42.5" lon="1.5"><tag k="amenity" v="cafe"/></node>
+</create></osmChange>`, sequence)
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = file.Close()
+
+	state := fmt.Sprintf("sequenceNumber=%d\ntimestamp=2025-10-19T10\\:00\\:00Z\n", sequence)
+	if err = os.WriteFile(filepath.Join(mirror, "state.txt"), []byte(state), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMap_Replicate_follow(t *testing.T) {
+	tileset, source, mirror := replicationSource(t)
+	writeDiff(t, mirror, 11)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var applied []int64
+	result, err := NewMap(tileset).Replicate(ctx, ReplicateOptions{
+		Source:   source,
+		URL:      mirror,
+		Interval: time.Millisecond,
+		OnApplied: func(sequence int64, _ *UpdateResult) {
+			applied = append(applied, sequence)
+			if sequence == 12 {
+				cancel()
+				return
+			}
+
+			// Applied diffs are kept in memory while following and are not read again
+			if err := os.Remove(filepath.Join(mirror, filepath.FromSlash(replication.SequencePath(sequence))+".osc.gz")); err != nil {
+				t.Fatal(err)
+			}
+			writeDiff(t, mirror, sequence+1)
+		},
+		OnError: func(err error) {
+			t.Errorf("OnError() error = %v", err)
+			cancel()
+		},
+	})
+	if err != nil {
+		t.Fatalf("Replicate() error = %v", err)
+	}
+
+	if want := []int64{11, 12}; !reflect.DeepEqual(applied, want) {
+		t.Errorf("applied sequences %v, want %v", applied, want)
+	}
+	if result.Start != 10 || result.Applied != 12 || result.Latest != 12 {
+		t.Errorf("Replicate() = %+v, want sequence 10 -> 12, latest 12", result)
+	}
+}